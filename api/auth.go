@@ -0,0 +1,74 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// JWT lifetime settings
+const (
+	jwtLifetime   = 15 * time.Minute
+	jwtClockSkew  = 30 * time.Second // iat is backdated to tolerate clock differences
+	jwtRenewAhead = time.Minute      // Tokens are renewed this long before they expire
+)
+
+// JWTSigner signs the short-lived EdDSA JWTs QWeather accepts in place of an API key
+type JWTSigner struct {
+	keyID      string
+	projectID  string
+	privateKey ed25519.PrivateKey
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewJWTSigner creates a signer from a credential ID, a project ID and a PEM encoded PKCS#8
+// Ed25519 private key
+func NewJWTSigner(keyID, projectID string, privateKeyPEM []byte) (*JWTSigner, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to parse JWT private key: no PEM data found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT private key: %w", err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse JWT private key: not an Ed25519 key")
+	}
+	return &JWTSigner{keyID: keyID, projectID: projectID, privateKey: privateKey}, nil
+}
+
+// Token returns a valid token, signing a new one when the current one is about to expire
+func (s *JWTSigner) Token(now time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && now.Add(jwtRenewAhead).Before(s.expires) {
+		return s.token, nil
+	}
+
+	issued := now.Add(-jwtClockSkew)
+	expires := issued.Add(jwtLifetime)
+	header, err := json.Marshal(map[string]string{"alg": "EdDSA", "kid": s.keyID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT header: %w", err)
+	}
+	claims, err := json.Marshal(map[string]any{"sub": s.projectID, "iat": issued.Unix(), "exp": expires.Unix()})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %w", err)
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	signature := ed25519.Sign(s.privateKey, []byte(signingInput))
+
+	s.token = signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+	s.expires = expires
+	return s.token, nil
+}
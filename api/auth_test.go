@@ -0,0 +1,85 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJWTSigner(t *testing.T) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(privateKey)
+	signer, err := NewJWTSigner("kid-1", "project-1", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("NewJWTSigner failed: %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	token, err := signer.Token(now)
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token %q is not a JWT", token)
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	if !ed25519.Verify(publicKey, []byte(parts[0]+"."+parts[1]), signature) {
+		t.Fatal("token signature does not verify")
+	}
+	var header, claims map[string]any
+	headerJSON, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	claimsJSON, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	json.Unmarshal(headerJSON, &header)
+	json.Unmarshal(claimsJSON, &claims)
+	if header["alg"] != "EdDSA" || header["kid"] != "kid-1" || claims["sub"] != "project-1" {
+		t.Fatalf("header = %v, claims = %v", header, claims)
+	}
+	if exp := int64(claims["exp"].(float64)); exp <= now.Unix() {
+		t.Fatalf("exp %d is not after now", exp)
+	}
+
+	// Tokens are reused until shortly before they expire
+	if again, _ := signer.Token(now.Add(5 * time.Minute)); again != token {
+		t.Fatal("token was not reused")
+	}
+	if renewed, _ := signer.Token(now.Add(14 * time.Minute)); renewed == token {
+		t.Fatal("token was not renewed before expiry")
+	}
+
+	if _, err := NewJWTSigner("kid-1", "project-1", []byte("not a key")); err == nil {
+		t.Fatal("expected error for invalid private key")
+	}
+}
+
+func TestMakeRequest_JWTAndLang(t *testing.T) {
+	_, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(privateKey)
+	signer, _ := NewJWTSigner("kid-1", "project-1", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") || r.Header.Get("X-QW-Api-Key") != "" {
+			t.Errorf("Authorization = %q, X-QW-Api-Key = %q", r.Header.Get("Authorization"), r.Header.Get("X-QW-Api-Key"))
+		}
+		if r.URL.Query().Get("lang") != "en" {
+			t.Errorf("lang = %q, want en", r.URL.Query().Get("lang"))
+		}
+		w.Write([]byte(`{"code":"200"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "")
+	client.JWT = signer
+	client.Lang = "en"
+	if _, err := client.MakeRequest("/v7/weather/now", map[string]string{"location": "101010100"}); err != nil {
+		t.Fatalf("MakeRequest failed: %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
@@ -30,6 +31,10 @@ type Client struct {
 	APIKey     string
 	HTTPClient *http.Client
 	LogLevel   LogLevel
+	JWT        *JWTSigner // Authenticates with signed JWTs instead of the API key when set
+	Lang       string     // Language of text in responses; the API default when empty
+
+	quota quotaTracker
 }
 
 // NewClient Create a new API client
@@ -67,10 +72,10 @@ func (c *Client) MakeRequestWithContext(ctx context.Context, endpoint string, pa
 
 	// Handle path parameters
 	if len(pathParams) > 0 {
-		// Replace placeholders in the path
+		// Replace placeholders in the path, escaping the values so they stay within their segment
 		endpointWithParams := endpoint
 		for _, param := range pathParams {
-			endpointWithParams = strings.Replace(endpointWithParams, "{}", param, 1)
+			endpointWithParams = strings.Replace(endpointWithParams, "{}", url.PathEscape(param), 1)
 		}
 		urlStr = fmt.Sprintf("%s%s", c.BaseURL, endpointWithParams)
 	} else {
@@ -87,6 +92,9 @@ func (c *Client) MakeRequestWithContext(ctx context.Context, endpoint string, pa
 	for key, value := range params {
 		q.Add(key, value)
 	}
+	if c.Lang != "" && q.Get("lang") == "" {
+		q.Set("lang", c.Lang)
+	}
 	u.RawQuery = q.Encode()
 
 	// Create request with context
@@ -95,8 +103,16 @@ func (c *Client) MakeRequestWithContext(ctx context.Context, endpoint string, pa
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Add API key to request header
-	req.Header.Add("X-QW-Api-Key", c.APIKey)
+	// Authenticate with a JWT if configured, otherwise with the API key
+	if c.JWT != nil {
+		token, err := c.JWT.Token(time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else {
+		req.Header.Add("X-QW-Api-Key", c.APIKey)
+	}
 
 	// Send request
 	resp, err := c.HTTPClient.Do(req)
@@ -104,6 +120,7 @@ func (c *Client) MakeRequestWithContext(ctx context.Context, endpoint string, pa
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
+	c.quota.record(resp.StatusCode, time.Now())
 
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
@@ -119,28 +136,28 @@ func (c *Client) MakeRequestWithContext(ctx context.Context, endpoint string, pa
 	}
 
 	// Output response information based on log level
-	if c.LogLevel >= LogLevelDebug {
+	if c.logEnabled(ctx, LogLevelDebug) {
 		// Output full response at debug level
 		bodyPreview := string(body)
 		if len(bodyPreview) > MaxLogBodyLength {
 			bodyPreview = bodyPreview[:MaxLogBodyLength] + "... (truncated)"
 		}
-		fmt.Printf("API Response [%s]: Status=%d, Body=%s\n", endpoint, resp.StatusCode, bodyPreview)
-	} else if c.LogLevel >= LogLevelInfo {
+		slog.DebugContext(ctx, "API response", "endpoint", endpoint, "status", resp.StatusCode, "body", bodyPreview)
+	} else if c.logEnabled(ctx, LogLevelInfo) {
 		// Output only status code and endpoint at info level
-		fmt.Printf("API Response [%s]: Status=%d\n", endpoint, resp.StatusCode)
+		slog.InfoContext(ctx, "API response", "endpoint", endpoint, "status", resp.StatusCode)
 	}
 
 	return body, nil
 }
 
 // GetLocationByName Get location information by city name
-func (c *Client) GetLocationByName(cityName string) (*LocationResponse, error) {
+func (c *Client) GetLocationByName(ctx context.Context, cityName string) (*LocationResponse, error) {
 	params := map[string]string{
 		"location": cityName,
 	}
 
-	data, err := c.MakeRequest("/geo/v2/city/lookup", params)
+	data, err := c.MakeRequestWithContext(ctx, "/geo/v2/city/lookup", params)
 	if err != nil {
 		return nil, err
 	}
@@ -159,8 +176,8 @@ func (c *Client) GetLocationByName(cityName string) (*LocationResponse, error) {
 
 // GetCityCoordinates Helper function to get city coordinates and info by name
 // This eliminates duplicate city lookup code across tools
-func (c *Client) GetCityCoordinates(cityName string) (lat, lon string, cityInfo *Location, err error) {
-	locationData, err := c.GetLocationByName(cityName)
+func (c *Client) GetCityCoordinates(ctx context.Context, cityName string) (lat, lon string, cityInfo *Location, err error) {
+	locationData, err := c.GetLocationByName(ctx, cityName)
 	if err != nil {
 		return "", "", nil, fmt.Errorf("failed to query city: %w", err)
 	}
@@ -174,24 +191,27 @@ func (c *Client) GetCityCoordinates(cityName string) (lat, lon string, cityInfo
 	}
 
 	info := &locationData.Location[0]
-	// Format coordinates, keep up to 2 decimal places
-	latF := 0.0
-	lonF := 0.0
-	fmt.Sscanf(info.Lat, "%f", &latF)
-	fmt.Sscanf(info.Lon, "%f", &lonF)
-	lat = fmt.Sprintf("%.2f", latF)
-	lon = fmt.Sprintf("%.2f", lonF)
+	lat, lon = info.Coordinates()
 
 	return lat, lon, info, nil
 }
 
+// Coordinates Format the location's coordinates for the air quality endpoints, keeping up to 2 decimal places
+func (l *Location) Coordinates() (lat, lon string) {
+	latF := 0.0
+	lonF := 0.0
+	fmt.Sscanf(l.Lat, "%f", &latF)
+	fmt.Sscanf(l.Lon, "%f", &lonF)
+	return fmt.Sprintf("%.2f", latF), fmt.Sprintf("%.2f", lonF)
+}
+
 // GetWeatherNow Get real-time weather
-func (c *Client) GetWeatherNow(locationID string) (*WeatherNowResponse, error) {
+func (c *Client) GetWeatherNow(ctx context.Context, locationID string) (*WeatherNowResponse, error) {
 	params := map[string]string{
 		"location": locationID,
 	}
 
-	data, err := c.MakeRequest("/v7/weather/now", params)
+	data, err := c.MakeRequestWithContext(ctx, "/v7/weather/now", params)
 	if err != nil {
 		return nil, err
 	}
@@ -205,12 +225,12 @@ func (c *Client) GetWeatherNow(locationID string) (*WeatherNowResponse, error) {
 }
 
 // GetWeatherForecast Get weather forecast
-func (c *Client) GetWeatherForecast(locationID, days string) (*WeatherDailyResponse, error) {
+func (c *Client) GetWeatherForecast(ctx context.Context, locationID, days string) (*WeatherDailyResponse, error) {
 	params := map[string]string{
 		"location": locationID,
 	}
 
-	data, err := c.MakeRequest(fmt.Sprintf("/v7/weather/%s", days), params)
+	data, err := c.MakeRequestWithContext(ctx, fmt.Sprintf("/v7/weather/%s", days), params)
 	if err != nil {
 		return nil, err
 	}
@@ -224,12 +244,12 @@ func (c *Client) GetWeatherForecast(locationID, days string) (*WeatherDailyRespo
 }
 
 // GetMinutelyPrecipitation Get minutely precipitation forecast
-func (c *Client) GetMinutelyPrecipitation(location string) (*MinutelyResponse, error) {
+func (c *Client) GetMinutelyPrecipitation(ctx context.Context, location string) (*MinutelyResponse, error) {
 	params := map[string]string{
 		"location": location,
 	}
 
-	data, err := c.MakeRequest("/v7/minutely/5m", params)
+	data, err := c.MakeRequestWithContext(ctx, "/v7/minutely/5m", params)
 	if err != nil {
 		return nil, err
 	}
@@ -243,12 +263,12 @@ func (c *Client) GetMinutelyPrecipitation(location string) (*MinutelyResponse, e
 }
 
 // GetHourlyForecast Get hourly weather forecast
-func (c *Client) GetHourlyForecast(locationID, hours string) (*HourlyResponse, error) {
+func (c *Client) GetHourlyForecast(ctx context.Context, locationID, hours string) (*HourlyResponse, error) {
 	params := map[string]string{
 		"location": locationID,
 	}
 
-	data, err := c.MakeRequest(fmt.Sprintf("/v7/weather/%s", hours), params)
+	data, err := c.MakeRequestWithContext(ctx, fmt.Sprintf("/v7/weather/%s", hours), params)
 	if err != nil {
 		return nil, err
 	}
@@ -262,12 +282,12 @@ func (c *Client) GetHourlyForecast(locationID, hours string) (*HourlyResponse, e
 }
 
 // GetWeatherWarning Get weather warnings
-func (c *Client) GetWeatherWarning(locationID string) (*WarningResponse, error) {
+func (c *Client) GetWeatherWarning(ctx context.Context, locationID string) (*WarningResponse, error) {
 	params := map[string]string{
 		"location": locationID,
 	}
 
-	data, err := c.MakeRequest("/v7/warning/now", params)
+	data, err := c.MakeRequestWithContext(ctx, "/v7/warning/now", params)
 	if err != nil {
 		return nil, err
 	}
@@ -281,13 +301,13 @@ func (c *Client) GetWeatherWarning(locationID string) (*WarningResponse, error)
 }
 
 // GetWeatherIndices Get weather life indices
-func (c *Client) GetWeatherIndices(locationID, days, indexType string) (*IndicesResponse, error) {
+func (c *Client) GetWeatherIndices(ctx context.Context, locationID, days, indexType string) (*IndicesResponse, error) {
 	params := map[string]string{
 		"location": locationID,
 		"type":     indexType,
 	}
 
-	data, err := c.MakeRequest(fmt.Sprintf("/v7/indices/%s", days), params)
+	data, err := c.MakeRequestWithContext(ctx, fmt.Sprintf("/v7/indices/%s", days), params)
 	if err != nil {
 		return nil, err
 	}
@@ -301,27 +321,27 @@ func (c *Client) GetWeatherIndices(locationID, days, indexType string) (*Indices
 }
 
 // GetAirQuality Get real-time air quality
-func (c *Client) GetAirQuality(lat, lon string) (*AirQualityResponse, error) {
+func (c *Client) GetAirQuality(ctx context.Context, lat, lon string) (*AirQualityResponse, error) {
 	endpoint := fmt.Sprintf("/airquality/v1/current/%s/%s", lat, lon)
 
-	data, err := c.MakeRequest(endpoint, map[string]string{}, lat, lon)
+	data, err := c.MakeRequestWithContext(ctx, endpoint, map[string]string{}, lat, lon)
 	if err != nil {
 		return nil, err
 	}
 
 	var response AirQualityResponse
 	if err := json.Unmarshal(data, &response); err != nil {
-		if c.LogLevel >= LogLevelError {
+		if c.logEnabled(ctx, LogLevelError) {
 			rawData := string(data)
-			fmt.Printf("Error parsing air quality data: %v, raw data: %s\n", err, rawData[:min(len(rawData), maxErrorLogLength)])
+			slog.ErrorContext(ctx, "Failed to parse air quality data", "error", err, "raw", rawData[:min(len(rawData), maxErrorLogLength)])
 		}
 		return nil, fmt.Errorf("failed to parse air quality data: %w", err)
 	}
 
 	// Check if Code field is empty and handle it consistently
 	if response.Code == "" {
-		if c.LogLevel >= LogLevelInfo {
-			fmt.Printf("WARNING: Empty Code field in AirQualityResponse\n")
+		if c.logEnabled(ctx, LogLevelInfo) {
+			slog.WarnContext(ctx, "Empty Code field in response", "response", "AirQualityResponse")
 		}
 		// Empty code is treated as unknown/invalid response
 		if len(response.Indexes) == 0 {
@@ -335,27 +355,27 @@ func (c *Client) GetAirQuality(lat, lon string) (*AirQualityResponse, error) {
 }
 
 // GetAirQualityHourly Get hourly air quality forecast
-func (c *Client) GetAirQualityHourly(lat, lon string) (*AirQualityHourlyResponse, error) {
+func (c *Client) GetAirQualityHourly(ctx context.Context, lat, lon string) (*AirQualityHourlyResponse, error) {
 	endpoint := fmt.Sprintf("/airquality/v1/hourly/%s/%s", lat, lon)
 
-	data, err := c.MakeRequest(endpoint, map[string]string{}, lat, lon)
+	data, err := c.MakeRequestWithContext(ctx, endpoint, map[string]string{}, lat, lon)
 	if err != nil {
 		return nil, err
 	}
 
 	var response AirQualityHourlyResponse
 	if err := json.Unmarshal(data, &response); err != nil {
-		if c.LogLevel >= LogLevelError {
+		if c.logEnabled(ctx, LogLevelError) {
 			rawData := string(data)
-			fmt.Printf("Error parsing hourly air quality data: %v, raw data: %s\n", err, rawData[:min(len(rawData), maxErrorLogLength)])
+			slog.ErrorContext(ctx, "Failed to parse hourly air quality data", "error", err, "raw", rawData[:min(len(rawData), maxErrorLogLength)])
 		}
 		return nil, fmt.Errorf("failed to parse hourly air quality data: %w", err)
 	}
 
 	// Check if Code field is empty and handle it consistently
 	if response.Code == "" {
-		if c.LogLevel >= LogLevelInfo {
-			fmt.Printf("WARNING: Empty Code field in AirQualityHourlyResponse\n")
+		if c.logEnabled(ctx, LogLevelInfo) {
+			slog.WarnContext(ctx, "Empty Code field in response", "response", "AirQualityHourlyResponse")
 		}
 		// Empty code is treated as unknown/invalid response
 		if len(response.Hours) == 0 {
@@ -372,27 +392,27 @@ func (c *Client) GetAirQualityHourly(lat, lon string) (*AirQualityHourlyResponse
 const maxErrorLogLength = 500
 
 // GetAirQualityDaily Get daily air quality forecast
-func (c *Client) GetAirQualityDaily(lat, lon string) (*AirQualityDailyResponse, error) {
+func (c *Client) GetAirQualityDaily(ctx context.Context, lat, lon string) (*AirQualityDailyResponse, error) {
 	endpoint := fmt.Sprintf("/airquality/v1/daily/%s/%s", lat, lon)
 
-	data, err := c.MakeRequest(endpoint, map[string]string{}, lat, lon)
+	data, err := c.MakeRequestWithContext(ctx, endpoint, map[string]string{}, lat, lon)
 	if err != nil {
 		return nil, err
 	}
 
 	var response AirQualityDailyResponse
 	if err := json.Unmarshal(data, &response); err != nil {
-		if c.LogLevel >= LogLevelError {
+		if c.logEnabled(ctx, LogLevelError) {
 			rawData := string(data)
-			fmt.Printf("Error parsing daily air quality data: %v, raw data: %s\n", err, rawData[:min(len(rawData), maxErrorLogLength)])
+			slog.ErrorContext(ctx, "Failed to parse daily air quality data", "error", err, "raw", rawData[:min(len(rawData), maxErrorLogLength)])
 		}
 		return nil, fmt.Errorf("failed to parse daily air quality data: %w", err)
 	}
 
 	// Check if Code field is empty and handle it consistently
 	if response.Code == "" {
-		if c.LogLevel >= LogLevelInfo {
-			fmt.Printf("WARNING: Empty Code field in AirQualityDailyResponse\n")
+		if c.logEnabled(ctx, LogLevelInfo) {
+			slog.WarnContext(ctx, "Empty Code field in response", "response", "AirQualityDailyResponse")
 		}
 		// Empty code is treated as unknown/invalid response
 		if len(response.Days) == 0 {
@@ -404,3 +424,35 @@ func (c *Client) GetAirQualityDaily(lat, lon string) (*AirQualityDailyResponse,
 
 	return &response, nil
 }
+
+// GetAirQualityStation Get pollutant concentrations observed by a monitoring station
+func (c *Client) GetAirQualityStation(ctx context.Context, stationID string) (*AirQualityStationResponse, error) {
+	data, err := c.MakeRequestWithContext(ctx, "/airquality/v1/station/{}", map[string]string{}, stationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response AirQualityStationResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		if c.logEnabled(ctx, LogLevelError) {
+			rawData := string(data)
+			slog.ErrorContext(ctx, "Failed to parse station air quality data", "error", err, "raw", rawData[:min(len(rawData), maxErrorLogLength)])
+		}
+		return nil, fmt.Errorf("failed to parse station air quality data: %w", err)
+	}
+
+	// Check if Code field is empty and handle it consistently
+	if response.Code == "" {
+		if c.logEnabled(ctx, LogLevelInfo) {
+			slog.WarnContext(ctx, "Empty Code field in response", "response", "AirQualityStationResponse")
+		}
+		// Empty code is treated as unknown/invalid response
+		if len(response.Pollutants) == 0 {
+			return nil, fmt.Errorf("API returned invalid response: empty code and no data")
+		}
+		// If we have data, set a default code
+		response.Code = APICodeUnknown
+	}
+
+	return &response, nil
+}
@@ -142,7 +142,7 @@ func TestGetLocationByName(t *testing.T) {
 
 	client := NewClient(server.URL, "test-key")
 
-	locationData, err := client.GetLocationByName("Beijing")
+	locationData, err := client.GetLocationByName(context.Background(), "Beijing")
 	if err != nil {
 		t.Fatalf("GetLocationByName failed: %v", err)
 	}
@@ -182,7 +182,7 @@ func TestGetCityCoordinates(t *testing.T) {
 
 	client := NewClient(server.URL, "test-key")
 
-	lat, lon, cityInfo, err := client.GetCityCoordinates("Shanghai")
+	lat, lon, cityInfo, err := client.GetCityCoordinates(context.Background(), "Shanghai")
 	if err != nil {
 		t.Fatalf("GetCityCoordinates failed: %v", err)
 	}
@@ -218,7 +218,7 @@ func TestGetWeatherNow(t *testing.T) {
 
 	client := NewClient(server.URL, "test-key")
 
-	weatherData, err := client.GetWeatherNow("101010100")
+	weatherData, err := client.GetWeatherNow(context.Background(), "101010100")
 	if err != nil {
 		t.Fatalf("GetWeatherNow failed: %v", err)
 	}
@@ -245,7 +245,7 @@ func TestGetWeatherForecast(t *testing.T) {
 
 	client := NewClient(server.URL, "test-key")
 
-	forecastData, err := client.GetWeatherForecast("101010100", "3d")
+	forecastData, err := client.GetWeatherForecast(context.Background(), "101010100", "3d")
 	if err != nil {
 		t.Fatalf("GetWeatherForecast failed: %v", err)
 	}
@@ -302,7 +302,7 @@ func TestGetAirQuality(t *testing.T) {
 
 	client := NewClient(server.URL, "test-key")
 
-	airQualityData, err := client.GetAirQuality("39.90", "116.41")
+	airQualityData, err := client.GetAirQuality(context.Background(), "39.90", "116.41")
 	if err != nil {
 		t.Fatalf("GetAirQuality failed: %v", err)
 	}
@@ -327,7 +327,7 @@ func TestGetAirQualityEmptyCode(t *testing.T) {
 
 	client := NewClient(server.URL, "test-key")
 
-	airQualityData, err := client.GetAirQuality("39.90", "116.41")
+	airQualityData, err := client.GetAirQuality(context.Background(), "39.90", "116.41")
 	if err != nil {
 		t.Fatalf("GetAirQuality failed: %v", err)
 	}
@@ -417,7 +417,7 @@ func TestGetLocationByName_CodeError(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(server.URL, "test-key")
-	_, err := client.GetLocationByName("Nowhere")
+	_, err := client.GetLocationByName(context.Background(), "Nowhere")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -431,7 +431,7 @@ func TestGetCityCoordinates_NoLocationFound(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(server.URL, "test-key")
-	_, _, _, err := client.GetCityCoordinates("Nowhere")
+	_, _, _, err := client.GetCityCoordinates(context.Background(), "Nowhere")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -448,7 +448,7 @@ func TestGetCityCoordinates_CodeErrorWrapped(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(server.URL, "test-key")
-	_, _, _, err := client.GetCityCoordinates("Nowhere")
+	_, _, _, err := client.GetCityCoordinates(context.Background(), "Nowhere")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -465,7 +465,7 @@ func TestGetAirQualityEmptyCodeNoData(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(server.URL, "test-key")
-	_, err := client.GetAirQuality("39.90", "116.41")
+	_, err := client.GetAirQuality(context.Background(), "39.90", "116.41")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -479,7 +479,7 @@ func TestGetAirQualityHourlyEmptyCode_WithData(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(server.URL, "test-key")
-	got, err := client.GetAirQualityHourly("39.90", "116.41")
+	got, err := client.GetAirQualityHourly(context.Background(), "39.90", "116.41")
 	if err != nil {
 		t.Fatalf("GetAirQualityHourly failed: %v", err)
 	}
@@ -499,7 +499,7 @@ func TestGetAirQualityHourlyEmptyCode_NoData(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(server.URL, "test-key")
-	_, err := client.GetAirQualityHourly("39.90", "116.41")
+	_, err := client.GetAirQualityHourly(context.Background(), "39.90", "116.41")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -513,7 +513,7 @@ func TestGetAirQualityDailyEmptyCode_WithData(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(server.URL, "test-key")
-	got, err := client.GetAirQualityDaily("39.90", "116.41")
+	got, err := client.GetAirQualityDaily(context.Background(), "39.90", "116.41")
 	if err != nil {
 		t.Fatalf("GetAirQualityDaily failed: %v", err)
 	}
@@ -533,8 +533,58 @@ func TestGetAirQualityDailyEmptyCode_NoData(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(server.URL, "test-key")
-	_, err := client.GetAirQualityDaily("39.90", "116.41")
+	_, err := client.GetAirQualityDaily(context.Background(), "39.90", "116.41")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestGetAirQualityStation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/airquality/v1/station/P51762" {
+			t.Errorf("path = %q, want %q", r.URL.Path, "/airquality/v1/station/P51762")
+		}
+		w.Write([]byte(`{"pollutants":[{"code":"pm2p5","name":"PM 2.5","concentration":{"value":12.5,"unit":"μg/m3"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	data, err := client.GetAirQualityStation(context.Background(), "P51762")
+	if err != nil {
+		t.Fatalf("GetAirQualityStation failed: %v", err)
+	}
+	if data.Code != APICodeUnknown {
+		t.Fatalf("Code = %q, want %q", data.Code, APICodeUnknown)
+	}
+	if len(data.Pollutants) != 1 || data.Pollutants[0].Concentration.Value != 12.5 {
+		t.Fatalf("Pollutants = %+v, want one PM2.5 reading of 12.5", data.Pollutants)
+	}
+}
+
+func TestGetAirQualityStation_EscapesStationID(t *testing.T) {
+	stationID := "P1/../../v7/weather/now?location=101010100"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if want := "/airquality/v1/station/" + stationID; r.URL.Path != want || r.URL.Query().Has("location") {
+			t.Errorf("request = %q, want the station ID as a single path segment", r.RequestURI)
+		}
+		w.Write([]byte(`{"code":"200","pollutants":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	if _, err := client.GetAirQualityStation(context.Background(), stationID); err != nil {
+		t.Fatalf("GetAirQualityStation failed: %v", err)
+	}
+}
+
+func TestGetAirQualityStationEmptyCode_NoData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"pollutants":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	if _, err := client.GetAirQualityStation(context.Background(), "P51762"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -1,5 +1,10 @@
 package api
 
+import (
+	"context"
+	"log/slog"
+)
+
 // LogLevel defines the logging level
 type LogLevel int
 
@@ -29,3 +34,21 @@ func (l LogLevel) String() string {
 		return "UNKNOWN"
 	}
 }
+
+// slogLevel returns the slog level records of a log level are written at
+func (l LogLevel) slogLevel() slog.Level {
+	switch l {
+	case LogLevelError:
+		return slog.LevelError
+	case LogLevelInfo:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// logEnabled reports whether records of a level are logged: the client's log level caps the
+// verbosity and the default slog handler filters by the configured and client-requested levels
+func (c *Client) logEnabled(ctx context.Context, level LogLevel) bool {
+	return c.LogLevel >= level && slog.Default().Enabled(ctx, level.slogLevel())
+}
@@ -8,15 +8,17 @@ type LocationResponse struct {
 
 // Location City information
 type Location struct {
-	Name    string `json:"name"`
-	ID      string `json:"id"`
-	Lat     string `json:"lat"`
-	Lon     string `json:"lon"`
-	Adm2    string `json:"adm2"`
-	Adm1    string `json:"adm1"`
-	Country string `json:"country"`
-	Type    string `json:"type"`
-	Rank    string `json:"rank"`
+	Name      string `json:"name"`
+	ID        string `json:"id"`
+	Lat       string `json:"lat"`
+	Lon       string `json:"lon"`
+	Adm2      string `json:"adm2"`
+	Adm1      string `json:"adm1"`
+	Country   string `json:"country"`
+	Type      string `json:"type"`
+	Rank      string `json:"rank"`
+	TZ        string `json:"tz"`        // IANA time zone, e.g. Asia/Shanghai
+	UtcOffset string `json:"utcOffset"` // e.g. +08:00
 }
 
 // WeatherNowResponse Real-time weather response
@@ -28,12 +30,15 @@ type WeatherNowResponse struct {
 		Temp      string `json:"temp"`
 		FeelsLike string `json:"feelsLike"`
 		Text      string `json:"text"`
+		Wind360   string `json:"wind360"`
 		WindDir   string `json:"windDir"`
 		WindScale string `json:"windScale"`
+		WindSpeed string `json:"windSpeed"`
 		Humidity  string `json:"humidity"`
 		Precip    string `json:"precip"`
 		Pressure  string `json:"pressure"`
 		Vis       string `json:"vis"`
+		Dew       string `json:"dew"`
 	} `json:"now"`
 }
 
@@ -108,28 +113,32 @@ type HourlyResponse struct {
 	} `json:"hourly"`
 }
 
+// Warning a weather warning issued for a location.
+// Declared as an alias so responses can still be built from anonymous struct literals.
+type Warning = struct {
+	ID            string `json:"id"`
+	Sender        string `json:"sender"`
+	PubTime       string `json:"pubTime"`
+	Title         string `json:"title"`
+	StartTime     string `json:"startTime"`
+	EndTime       string `json:"endTime"`
+	Status        string `json:"status"`
+	Severity      string `json:"severity"`
+	SeverityColor string `json:"severityColor"`
+	Type          string `json:"type"`
+	TypeName      string `json:"typeName"`
+	Urgency       string `json:"urgency"`
+	Certainty     string `json:"certainty"`
+	Text          string `json:"text"`
+	Related       string `json:"related"`
+}
+
 // WarningResponse Weather warning response
 type WarningResponse struct {
-	Code       string `json:"code"`
-	UpdateTime string `json:"updateTime"`
-	FxLink     string `json:"fxLink"`
-	Warning    []struct {
-		ID            string `json:"id"`
-		Sender        string `json:"sender"`
-		PubTime       string `json:"pubTime"`
-		Title         string `json:"title"`
-		StartTime     string `json:"startTime"`
-		EndTime       string `json:"endTime"`
-		Status        string `json:"status"`
-		Severity      string `json:"severity"`
-		SeverityColor string `json:"severityColor"`
-		Type          string `json:"type"`
-		TypeName      string `json:"typeName"`
-		Urgency       string `json:"urgency"`
-		Certainty     string `json:"certainty"`
-		Text          string `json:"text"`
-		Related       string `json:"related"`
-	} `json:"warning"`
+	Code       string    `json:"code"`
+	UpdateTime string    `json:"updateTime"`
+	FxLink     string    `json:"fxLink"`
+	Warning    []Warning `json:"warning"`
 }
 
 // IndicesResponse Weather life indices response
@@ -147,53 +156,60 @@ type IndicesResponse struct {
 	} `json:"daily"`
 }
 
+// AirQualityIndex an air quality index under a national/regional standard or the QWeather universal AQI.
+// Declared as an alias so responses can still be built from anonymous struct literals.
+type AirQualityIndex = struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Aqi        int    `json:"aqi"`
+	AqiDisplay string `json:"aqiDisplay"`
+	Level      string `json:"level,omitempty"`
+	Category   string `json:"category,omitempty"`
+	Color      struct {
+		Red   int `json:"red"`
+		Green int `json:"green"`
+		Blue  int `json:"blue"`
+		Alpha int `json:"alpha"`
+	} `json:"color"`
+	PrimaryPollutant *struct {
+		Code     string `json:"code"`
+		Name     string `json:"name"`
+		FullName string `json:"fullName"`
+	} `json:"primaryPollutant,omitempty"`
+	Health *struct {
+		Effect string `json:"effect"`
+		Advice struct {
+			GeneralPopulation   string `json:"generalPopulation"`
+			SensitivePopulation string `json:"sensitivePopulation"`
+		} `json:"advice"`
+	} `json:"health,omitempty"`
+}
+
+// AirQualityPollutant a pollutant concentration with its sub-indexes
+type AirQualityPollutant = struct {
+	Code          string `json:"code"`
+	Name          string `json:"name"`
+	FullName      string `json:"fullName"`
+	Concentration struct {
+		Value float64 `json:"value"`
+		Unit  string  `json:"unit"`
+	} `json:"concentration"`
+	SubIndexes []struct {
+		Code       string `json:"code"`
+		Aqi        int    `json:"aqi"`
+		AqiDisplay string `json:"aqiDisplay"`
+	} `json:"subIndexes,omitempty"`
+}
+
 // AirQualityResponse Real-time air quality response
 type AirQualityResponse struct {
 	Code     string `json:"code"`
 	Metadata struct {
 		Tag string `json:"tag"`
 	} `json:"metadata"`
-	Indexes []struct {
-		Code       string `json:"code"`
-		Name       string `json:"name"`
-		Aqi        int    `json:"aqi"`
-		AqiDisplay string `json:"aqiDisplay"`
-		Level      string `json:"level,omitempty"`
-		Category   string `json:"category,omitempty"`
-		Color      struct {
-			Red   int `json:"red"`
-			Green int `json:"green"`
-			Blue  int `json:"blue"`
-			Alpha int `json:"alpha"`
-		} `json:"color"`
-		PrimaryPollutant *struct {
-			Code     string `json:"code"`
-			Name     string `json:"name"`
-			FullName string `json:"fullName"`
-		} `json:"primaryPollutant,omitempty"`
-		Health *struct {
-			Effect string `json:"effect"`
-			Advice struct {
-				GeneralPopulation   string `json:"generalPopulation"`
-				SensitivePopulation string `json:"sensitivePopulation"`
-			} `json:"advice"`
-		} `json:"health,omitempty"`
-	} `json:"indexes"`
-	Pollutants []struct {
-		Code          string `json:"code"`
-		Name          string `json:"name"`
-		FullName      string `json:"fullName"`
-		Concentration struct {
-			Value float64 `json:"value"`
-			Unit  string  `json:"unit"`
-		} `json:"concentration"`
-		SubIndexes []struct {
-			Code       string `json:"code"`
-			Aqi        int    `json:"aqi"`
-			AqiDisplay string `json:"aqiDisplay"`
-		} `json:"subIndexes,omitempty"`
-	} `json:"pollutants"`
-	Stations []struct {
+	Indexes    []AirQualityIndex     `json:"indexes"`
+	Pollutants []AirQualityPollutant `json:"pollutants"`
+	Stations   []struct {
 		ID   string `json:"id"`
 		Name string `json:"name"`
 	} `json:"stations,omitempty"`
@@ -206,47 +222,9 @@ type AirQualityHourlyResponse struct {
 		Tag string `json:"tag"`
 	} `json:"metadata"`
 	Hours []struct {
-		ForecastTime string `json:"forecastTime"`
-		Indexes      []struct {
-			Code       string `json:"code"`
-			Name       string `json:"name"`
-			Aqi        int    `json:"aqi"`
-			AqiDisplay string `json:"aqiDisplay"`
-			Level      string `json:"level,omitempty"`
-			Category   string `json:"category,omitempty"`
-			Color      struct {
-				Red   int `json:"red"`
-				Green int `json:"green"`
-				Blue  int `json:"blue"`
-				Alpha int `json:"alpha"`
-			} `json:"color"`
-			PrimaryPollutant *struct {
-				Code     string `json:"code"`
-				Name     string `json:"name"`
-				FullName string `json:"fullName"`
-			} `json:"primaryPollutant,omitempty"`
-			Health *struct {
-				Effect string `json:"effect"`
-				Advice struct {
-					GeneralPopulation   string `json:"generalPopulation"`
-					SensitivePopulation string `json:"sensitivePopulation"`
-				} `json:"advice"`
-			} `json:"health,omitempty"`
-		} `json:"indexes"`
-		Pollutants []struct {
-			Code          string `json:"code"`
-			Name          string `json:"name"`
-			FullName      string `json:"fullName"`
-			Concentration struct {
-				Value float64 `json:"value"`
-				Unit  string  `json:"unit"`
-			} `json:"concentration"`
-			SubIndexes []struct {
-				Code       string `json:"code"`
-				Aqi        int    `json:"aqi"`
-				AqiDisplay string `json:"aqiDisplay"`
-			} `json:"subIndexes,omitempty"`
-		} `json:"pollutants"`
+		ForecastTime string                `json:"forecastTime"`
+		Indexes      []AirQualityIndex     `json:"indexes"`
+		Pollutants   []AirQualityPollutant `json:"pollutants"`
 	} `json:"hours"`
 }
 
@@ -257,47 +235,26 @@ type AirQualityDailyResponse struct {
 		Tag string `json:"tag"`
 	} `json:"metadata"`
 	Days []struct {
-		ForecastStartTime string `json:"forecastStartTime"`
-		ForecastEndTime   string `json:"forecastEndTime"`
-		Indexes           []struct {
-			Code       string `json:"code"`
-			Name       string `json:"name"`
-			Aqi        int    `json:"aqi"`
-			AqiDisplay string `json:"aqiDisplay"`
-			Level      string `json:"level,omitempty"`
-			Category   string `json:"category,omitempty"`
-			Color      struct {
-				Red   int `json:"red"`
-				Green int `json:"green"`
-				Blue  int `json:"blue"`
-				Alpha int `json:"alpha"`
-			} `json:"color"`
-			PrimaryPollutant *struct {
-				Code     string `json:"code"`
-				Name     string `json:"name"`
-				FullName string `json:"fullName"`
-			} `json:"primaryPollutant,omitempty"`
-			Health *struct {
-				Effect string `json:"effect"`
-				Advice struct {
-					GeneralPopulation   string `json:"generalPopulation"`
-					SensitivePopulation string `json:"sensitivePopulation"`
-				} `json:"advice"`
-			} `json:"health,omitempty"`
-		} `json:"indexes"`
-		Pollutants []struct {
-			Code          string `json:"code"`
-			Name          string `json:"name"`
-			FullName      string `json:"fullName"`
-			Concentration struct {
-				Value float64 `json:"value"`
-				Unit  string  `json:"unit"`
-			} `json:"concentration"`
-			SubIndexes []struct {
-				Code       string `json:"code"`
-				Aqi        int    `json:"aqi"`
-				AqiDisplay string `json:"aqiDisplay"`
-			} `json:"subIndexes,omitempty"`
-		} `json:"pollutants"`
+		ForecastStartTime string                `json:"forecastStartTime"`
+		ForecastEndTime   string                `json:"forecastEndTime"`
+		Indexes           []AirQualityIndex     `json:"indexes"`
+		Pollutants        []AirQualityPollutant `json:"pollutants"`
 	} `json:"days"`
 }
+
+// AirQualityStationResponse Monitoring station air quality response
+type AirQualityStationResponse struct {
+	Code     string `json:"code"`
+	Metadata struct {
+		Tag string `json:"tag"`
+	} `json:"metadata"`
+	Pollutants []struct {
+		Code          string `json:"code"`
+		Name          string `json:"name"`
+		FullName      string `json:"fullName"`
+		Concentration struct {
+			Value float64 `json:"value"`
+			Unit  string  `json:"unit"`
+		} `json:"concentration"`
+	} `json:"pollutants"`
+}
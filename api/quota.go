@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// QuotaState upstream quota usage as observed in API responses
+type QuotaState struct {
+	Requests     int64     `json:"requests"`              // Requests sent by the client
+	Exceeded     bool      `json:"exceeded"`              // The latest response reported an exhausted quota or rate limit
+	LastExceeded time.Time `json:"lastExceeded,omitzero"` // When a response last reported it
+	LastStatus   int       `json:"lastStatus,omitempty"`  // HTTP status of the latest response
+}
+
+// quotaTracker records the quota state of a client
+type quotaTracker struct {
+	mu    sync.Mutex
+	state QuotaState
+}
+
+// record updates the state with the status of a response. QWeather answers 402 when the quota
+// or balance is exhausted and 429 when requests exceed the allowed rate.
+func (t *quotaTracker) record(status int, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state.Requests++
+	t.state.LastStatus = status
+	t.state.Exceeded = status == http.StatusPaymentRequired || status == http.StatusTooManyRequests
+	if t.state.Exceeded {
+		t.state.LastExceeded = now
+	}
+}
+
+// QuotaState returns the upstream quota state observed by the client
+func (c *Client) QuotaState() QuotaState {
+	c.quota.mu.Lock()
+	defer c.quota.mu.Unlock()
+	return c.quota.state
+}
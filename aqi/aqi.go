@@ -0,0 +1,288 @@
+// Package aqi computes air quality indexes locally from pollutant concentrations,
+// for use when the QWeather API does not report the requested standard.
+//
+// Concentrations use the units QWeather usually reports: μg/m³ for all pollutants
+// except CO, which is in mg/m³. Normalize converts other units to these.
+package aqi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Supported standards, using the QWeather index codes where one exists
+const (
+	StandardUSEPA  = "us-epa"  // United States EPA AQI
+	StandardCNMEE  = "cn-mee"  // China Ministry of Ecology and Environment AQI (HJ 633-2012)
+	StandardEUCAQI = "eu-caqi" // European Common Air Quality Index (hourly grid)
+)
+
+// Pollutant codes as reported by QWeather
+const (
+	PM25 = "pm2p5"
+	PM10 = "pm10"
+	O3   = "o3"
+	NO2  = "no2"
+	SO2  = "so2"
+	CO   = "co"
+)
+
+// molarVolume volume of one mole of gas in litres at 25°C and 1 atm, used to convert μg/m³ to ppb
+const molarVolume = 24.45
+
+// molecularWeight molecular weights in g/mol of the gaseous pollutants
+var molecularWeight = map[string]float64{
+	O3:  48.00,
+	NO2: 46.01,
+	SO2: 64.07,
+	CO:  28.01,
+}
+
+// Concentrations pollutant concentrations keyed by pollutant code
+type Concentrations map[string]float64
+
+// SubIndex the index computed for a single pollutant
+type SubIndex struct {
+	Pollutant string
+	Value     int
+}
+
+// Result an air quality index computed under one standard
+type Result struct {
+	Standard   string
+	Name       string
+	AQI        int
+	Category   string
+	Primary    string // Pollutant with the highest sub-index
+	SubIndexes []SubIndex
+}
+
+// breakpoint one linear segment of a piecewise index table
+type breakpoint struct {
+	cLow, cHigh float64
+	iLow, iHigh float64
+}
+
+// standard breakpoint tables and categories of an index
+type standard struct {
+	name       string
+	tables     map[string][]breakpoint
+	convert    func(pollutant string, value float64) float64
+	round      func(float64) int
+	extend     bool // Extrapolate the last segment above the top of the table instead of capping
+	categories []category
+}
+
+// category the upper index bound of a named category
+type category struct {
+	upper int
+	name  string
+}
+
+// segments builds breakpoints from matching concentration and index bounds
+func segments(c []float64, i []float64) []breakpoint {
+	bps := make([]breakpoint, 0, len(c)-1)
+	for k := 1; k < len(c); k++ {
+		bps = append(bps, breakpoint{cLow: c[k-1], cHigh: c[k], iLow: i[k-1], iHigh: i[k]})
+	}
+	return bps
+}
+
+// epaSegments builds EPA breakpoints, whose ranges are closed and separated by the reporting precision
+func epaSegments(c ...[2]float64) []breakpoint {
+	index := [][2]float64{{0, 50}, {51, 100}, {101, 150}, {151, 200}, {201, 300}, {301, 500}}
+	bps := make([]breakpoint, len(c))
+	for k, r := range c {
+		bps[k] = breakpoint{cLow: r[0], cHigh: r[1], iLow: index[k][0], iHigh: index[k][1]}
+	}
+	return bps
+}
+
+var standards = map[string]standard{
+	StandardUSEPA: {
+		name: "US EPA AQI",
+		tables: map[string][]breakpoint{
+			PM25: epaSegments([2]float64{0, 9.0}, [2]float64{9.1, 35.4}, [2]float64{35.5, 55.4}, [2]float64{55.5, 125.4}, [2]float64{125.5, 225.4}, [2]float64{225.5, 325.4}),
+			PM10: epaSegments([2]float64{0, 54}, [2]float64{55, 154}, [2]float64{155, 254}, [2]float64{255, 354}, [2]float64{355, 424}, [2]float64{425, 604}),
+			// 8-hour ozone, defined up to 200 ppb; the Hazardous segment uses the 1-hour breakpoints
+			// (405 to 604 ppb), so 8-hour levels between them count from its start
+			O3:  epaSegments([2]float64{0, 54}, [2]float64{55, 70}, [2]float64{71, 85}, [2]float64{86, 105}, [2]float64{106, 200}, [2]float64{405, 604}),
+			NO2: epaSegments([2]float64{0, 53}, [2]float64{54, 100}, [2]float64{101, 360}, [2]float64{361, 649}, [2]float64{650, 1249}, [2]float64{1250, 2049}),
+			SO2: epaSegments([2]float64{0, 35}, [2]float64{36, 75}, [2]float64{76, 185}, [2]float64{186, 304}, [2]float64{305, 604}, [2]float64{605, 1004}),
+			CO:  epaSegments([2]float64{0, 4.4}, [2]float64{4.5, 9.4}, [2]float64{9.5, 12.4}, [2]float64{12.5, 15.4}, [2]float64{15.5, 30.4}, [2]float64{30.5, 50.4}),
+		},
+		// EPA tables use ppb for O3, NO2 and SO2, and ppm for CO; the same factor
+		// converts μg/m³ to ppb and mg/m³ to ppm
+		convert: func(pollutant string, value float64) float64 {
+			if weight, ok := molecularWeight[pollutant]; ok {
+				return value * molarVolume / weight
+			}
+			return value
+		},
+		round: func(v float64) int { return int(math.Round(v)) },
+		categories: []category{
+			{50, "Good"},
+			{100, "Moderate"},
+			{150, "Unhealthy for Sensitive Groups"},
+			{200, "Unhealthy"},
+			{300, "Very Unhealthy"},
+			{math.MaxInt, "Hazardous"},
+		},
+	},
+	StandardCNMEE: {
+		name: "China AQI (MEE)",
+		tables: map[string][]breakpoint{
+			// 24-hour averages for particulate matter, 1-hour averages for gases
+			PM25: segments([]float64{0, 35, 75, 115, 150, 250, 350, 500}, mee),
+			PM10: segments([]float64{0, 50, 150, 250, 350, 420, 500, 600}, mee),
+			O3:   segments([]float64{0, 160, 200, 300, 400, 800, 1000, 1200}, mee),
+			NO2:  segments([]float64{0, 100, 200, 700, 1200, 2340, 3090, 3840}, mee),
+			// 1-hour SO2 is only defined up to 800 μg/m³; higher levels use the 24-hour table
+			SO2: segments([]float64{0, 150, 500, 650, 800, 1600, 2100, 2620}, mee),
+			CO:  segments([]float64{0, 5, 10, 35, 60, 90, 120, 150}, mee),
+		},
+		round: func(v float64) int { return int(math.Ceil(v)) },
+		categories: []category{
+			{50, "Excellent"},
+			{100, "Good"},
+			{150, "Lightly Polluted"},
+			{200, "Moderately Polluted"},
+			{300, "Heavily Polluted"},
+			{math.MaxInt, "Severely Polluted"},
+		},
+	},
+	StandardEUCAQI: {
+		name: "European CAQI",
+		tables: map[string][]breakpoint{
+			PM25: segments([]float64{0, 15, 30, 55, 110}, caqi),
+			PM10: segments([]float64{0, 25, 50, 90, 180}, caqi),
+			O3:   segments([]float64{0, 60, 120, 180, 240}, caqi),
+			NO2:  segments([]float64{0, 50, 100, 200, 400}, caqi),
+			SO2:  segments([]float64{0, 50, 100, 350, 500}, caqi),
+			CO:   segments([]float64{0, 5, 7.5, 10, 20}, caqi),
+		},
+		round:  func(v float64) int { return int(math.Round(v)) },
+		extend: true,
+		categories: []category{
+			{25, "Very Low"},
+			{50, "Low"},
+			{75, "Medium"},
+			{100, "High"},
+			{math.MaxInt, "Very High"},
+		},
+	},
+}
+
+// Index bounds shared by the tables of a standard
+var (
+	mee  = []float64{0, 50, 100, 150, 200, 300, 400, 500}
+	caqi = []float64{0, 25, 50, 75, 100}
+)
+
+// Standards returns the codes of the standards that can be computed locally
+func Standards() []string {
+	codes := make([]string, 0, len(standards))
+	for code := range standards {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Supported reports whether the standard can be computed locally
+func Supported(code string) bool {
+	_, ok := standards[code]
+	return ok
+}
+
+// Normalize converts a concentration reported in a unit to the unit Compute expects for the
+// pollutant. Mass concentrations are given in μg/m³ or mg/m³, and gases may also be given as
+// mixing ratios in ppb or ppm. An empty unit is taken to be the expected one.
+func Normalize(pollutant string, value float64, unit string) (float64, error) {
+	unit = strings.NewReplacer("μ", "u", "µ", "u", "³", "3").Replace(strings.ToLower(strings.TrimSpace(unit)))
+	if unit == "" {
+		return value, nil
+	}
+
+	// Convert to μg/m³ first
+	weight, gas := molecularWeight[pollutant]
+	switch {
+	case unit == "ug/m3":
+	case unit == "mg/m3":
+		value *= 1000
+	case unit == "ppb" && gas:
+		value *= weight / molarVolume
+	case unit == "ppm" && gas:
+		value *= 1000 * weight / molarVolume
+	default:
+		return 0, fmt.Errorf("unsupported unit %q for %s", unit, pollutant)
+	}
+	if pollutant == CO {
+		value /= 1000
+	}
+	return value, nil
+}
+
+// Compute returns the index of the given standard: the highest sub-index across
+// the pollutants it defines. Pollutants without a table in the standard are ignored.
+func Compute(code string, concentrations Concentrations) (Result, error) {
+	std, ok := standards[code]
+	if !ok {
+		return Result{}, fmt.Errorf("unsupported air quality standard: %s", code)
+	}
+
+	result := Result{Standard: code, Name: std.name}
+	for pollutant, value := range concentrations {
+		table, ok := std.tables[pollutant]
+		if !ok || value < 0 {
+			continue
+		}
+		if std.convert != nil {
+			value = std.convert(pollutant, value)
+		}
+		result.SubIndexes = append(result.SubIndexes, SubIndex{
+			Pollutant: pollutant,
+			Value:     std.round(interpolate(table, value, std.extend)),
+		})
+	}
+	if len(result.SubIndexes) == 0 {
+		return Result{}, fmt.Errorf("no pollutant concentrations usable for %s", std.name)
+	}
+
+	// Highest sub-index first; pollutant code breaks ties for a stable primary pollutant
+	sort.Slice(result.SubIndexes, func(i, j int) bool {
+		a, b := result.SubIndexes[i], result.SubIndexes[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		return a.Pollutant < b.Pollutant
+	})
+	result.AQI = result.SubIndexes[0].Value
+	result.Primary = result.SubIndexes[0].Pollutant
+	for _, c := range std.categories {
+		if result.AQI <= c.upper {
+			result.Category = c.name
+			break
+		}
+	}
+	return result, nil
+}
+
+// interpolate maps a concentration onto the index scale of a breakpoint table.
+// Values in the gap between two closed ranges use the upper range; values above
+// the table are capped at its top, or extrapolated along the last segment if extend is set.
+func interpolate(table []breakpoint, c float64, extend bool) float64 {
+	for _, bp := range table {
+		if c <= bp.cHigh {
+			c = max(c, bp.cLow)
+			return bp.iLow + (bp.iHigh-bp.iLow)/(bp.cHigh-bp.cLow)*(c-bp.cLow)
+		}
+	}
+	last := table[len(table)-1]
+	if !extend {
+		return last.iHigh
+	}
+	return last.iLow + (last.iHigh-last.iLow)/(last.cHigh-last.cLow)*(c-last.cLow)
+}
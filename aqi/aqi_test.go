@@ -0,0 +1,99 @@
+package aqi
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name           string
+		standard       string
+		concentrations Concentrations
+		wantAQI        int
+		wantPrimary    string
+		wantCategory   string
+	}{
+		// EPA worked examples: PM2.5 12.0 μg/m³ -> 56, 35.0 μg/m³ -> 99
+		{"epa pm2.5 moderate", StandardUSEPA, Concentrations{PM25: 12.0}, 56, PM25, "Moderate"},
+		{"epa pm2.5 upper moderate", StandardUSEPA, Concentrations{PM25: 35.0}, 99, PM25, "Moderate"},
+		{"epa value between ranges", StandardUSEPA, Concentrations{PM25: 9.05}, 51, PM25, "Moderate"},
+		// 100 μg/m³ of ozone is 51 ppb
+		{"epa ozone converted to ppb", StandardUSEPA, Concentrations{O3: 100}, 47, O3, "Good"},
+		// 294.48 μg/m³ of ozone is 150 ppb, in the 106-200 ppb Very Unhealthy segment
+		{"epa ozone very unhealthy", StandardUSEPA, Concentrations{O3: 294.48}, 247, O3, "Very Unhealthy"},
+		{"epa capped above table", StandardUSEPA, Concentrations{PM10: 900}, 500, PM10, "Hazardous"},
+
+		// HJ 633-2012: IAQI is rounded up; PM2.5 80 -> 107, PM10 100 -> 75
+		{"mee highest sub-index wins", StandardCNMEE, Concentrations{PM25: 80, PM10: 100}, 107, PM25, "Lightly Polluted"},
+		{"mee carbon monoxide in mg/m3", StandardCNMEE, Concentrations{CO: 1.2}, 12, CO, "Excellent"},
+
+		{"caqi within grid", StandardEUCAQI, Concentrations{NO2: 150}, 63, NO2, "Medium"},
+		{"caqi extrapolated above 100", StandardEUCAQI, Concentrations{PM10: 200, NO2: 20}, 106, PM10, "Very High"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Compute(tt.standard, tt.concentrations)
+			if err != nil {
+				t.Fatalf("Compute failed: %v", err)
+			}
+			if got.AQI != tt.wantAQI || got.Primary != tt.wantPrimary || got.Category != tt.wantCategory {
+				t.Errorf("Compute = AQI %d, primary %s, category %q; want %d, %s, %q",
+					got.AQI, got.Primary, got.Category, tt.wantAQI, tt.wantPrimary, tt.wantCategory)
+			}
+			if len(got.SubIndexes) != len(tt.concentrations) {
+				t.Errorf("got %d sub-indexes, want %d", len(got.SubIndexes), len(tt.concentrations))
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		pollutant string
+		value     float64
+		unit      string
+		want      float64
+		wantErr   bool
+	}{
+		{PM25, 35, "μg/m3", 35, false},
+		{PM25, 35, "", 35, false},
+		{PM10, 0.1, "mg/m³", 100, false},
+		{CO, 1.2, "mg/m3", 1.2, false},
+		{CO, 1200, "µg/m3", 1.2, false},
+		// 51 ppb of ozone is 100 μg/m³, 1 ppm of CO is 1.15 mg/m³
+		{O3, 50.94, "ppb", 100, false},
+		{CO, 1, "ppm", 1.1456, false},
+		{PM25, 10, "ppb", 0, true},
+		{NO2, 10, "ppt", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.pollutant, tt.value, tt.unit)
+		if (err != nil) != tt.wantErr || math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("Normalize(%s, %v, %q) = %v, %v; want %v, error %v", tt.pollutant, tt.value, tt.unit, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestComputeErrors(t *testing.T) {
+	if _, err := Compute("xx-unknown", Concentrations{PM25: 10}); err == nil {
+		t.Error("expected error for unsupported standard")
+	}
+	if _, err := Compute(StandardUSEPA, Concentrations{"nh3": 10}); err == nil {
+		t.Error("expected error when no pollutant has a table")
+	}
+}
+
+func TestStandards(t *testing.T) {
+	got := Standards()
+	want := []string{StandardCNMEE, StandardEUCAQI, StandardUSEPA}
+	if len(got) != len(want) {
+		t.Fatalf("Standards() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] || !Supported(got[i]) {
+			t.Errorf("Standards()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,582 @@
+// Package config loads the server configuration. Defaults are overridden by a JSON config file,
+// then by QWEATHER_* environment variables, then by command line flags.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/health"
+	"github.com/overstarry/qweather-mcp-go/logging"
+	"github.com/overstarry/qweather-mcp-go/tenant"
+	"github.com/overstarry/qweather-mcp-go/tools"
+	"github.com/overstarry/qweather-mcp-go/watcher"
+)
+
+// Upstream authentication modes
+const (
+	AuthModeKey = "key" // X-QW-Api-Key header
+	AuthModeJWT = "jwt" // Signed EdDSA JWT
+)
+
+// Transports
+const (
+	TransportStdio      = "stdio"
+	TransportSSE        = "sse"
+	TransportStreamable = "streamable"
+)
+
+// UnitsMetric the only supported units: tool output and weather conditions are expressed in metric units
+const UnitsMetric = "metric"
+
+// redacted replaces secrets in printed configurations
+const redacted = "REDACTED"
+
+// Duration a time.Duration written as a string such as "30s" in the config file
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\"")
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// MarshalJSON formats a duration string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Config server configuration
+type Config struct {
+	API        APIConfig        `json:"api"`
+	Server     ServerConfig     `json:"server"`
+	Log        LogConfig        `json:"log"`
+	Cache      CacheConfig      `json:"cache"`
+	Tools      ToolsConfig      `json:"tools"`
+	Middleware MiddlewareConfig `json:"middleware"`
+	Data       DataConfig       `json:"data"`
+	Tenants    TenantsConfig    `json:"tenants"`
+	Auth       AuthConfig       `json:"auth"`
+	Health     HealthConfig     `json:"health"`
+}
+
+// APIConfig upstream QWeather API access
+type APIConfig struct {
+	BaseURL  string    `json:"baseURL"`
+	AuthMode string    `json:"authMode"` // key or jwt
+	Key      string    `json:"key,omitempty"`
+	JWT      JWTConfig `json:"jwt"`
+	Timeout  Duration  `json:"timeout"`
+	Lang     string    `json:"lang,omitempty"` // Language of text in responses, e.g. en or zh; the API default when empty
+	Units    string    `json:"units"`
+}
+
+// JWTConfig credentials of the jwt authentication mode
+type JWTConfig struct {
+	KeyID          string `json:"keyID,omitempty"`
+	ProjectID      string `json:"projectID,omitempty"`
+	PrivateKeyFile string `json:"privateKeyFile,omitempty"` // PEM encoded Ed25519 private key
+}
+
+// ServerConfig MCP transport and HTTP listener
+type ServerConfig struct {
+	Transport         string   `json:"transport"`
+	Address           string   `json:"address"` // Listen address of the sse and streamable transports
+	ReadHeaderTimeout Duration `json:"readHeaderTimeout"`
+	IdleTimeout       Duration `json:"idleTimeout"`
+	ShutdownTimeout   Duration `json:"shutdownTimeout"`
+}
+
+// LogConfig local log output
+type LogConfig struct {
+	Level  string `json:"level"`
+	File   string `json:"file,omitempty"`
+	Format string `json:"format"`
+}
+
+// CacheConfig cache lifetimes
+type CacheConfig struct {
+	CompletionTTL Duration `json:"completionTTL"` // Geo lookups made for argument completion
+}
+
+// ToolsConfig tools offered to clients. Only the enabled tools are offered when Enabled is set;
+// disabled tools are never offered.
+type ToolsConfig struct {
+	Enabled  []string `json:"enabled,omitempty"`
+	Disabled []string `json:"disabled,omitempty"`
+}
+
+// MiddlewareConfig HTTP middlewares of the sse and streamable transports
+type MiddlewareConfig struct {
+	RequestLogging bool            `json:"requestLogging"`
+	Recovery       bool            `json:"recovery"`
+	RateLimit      RateLimitConfig `json:"rateLimit"`
+}
+
+// RateLimitConfig per-client rate limit of MCP requests; disabled when RequestsPerMinute is zero
+type RateLimitConfig struct {
+	RequestsPerMinute float64 `json:"requestsPerMinute"`
+	Burst             int     `json:"burst,omitempty"`      // Requests a client may send at once; RequestsPerMinute when zero
+	TrustProxy        bool    `json:"trustProxy,omitempty"` // Identify anonymous clients by the X-Forwarded-For address the proxy appended
+}
+
+// DataConfig local data kept by the server
+type DataConfig struct {
+	SnapshotDir         string         `json:"snapshotDir,omitempty"` // Forecast snapshots are kept in memory when empty
+	Recorder            RecorderConfig `json:"recorder"`
+	WarningPollInterval Duration       `json:"warningPollInterval"`
+	WebhookConfig       string         `json:"webhookConfig,omitempty"` // Path of the webhook config file
+}
+
+// RecorderConfig history recorder; disabled when Dir is empty
+type RecorderConfig struct {
+	Dir       string   `json:"dir,omitempty"`
+	Locations []string `json:"locations,omitempty"`
+	Interval  Duration `json:"interval,omitempty"`  // Recorder default when zero
+	Retention Duration `json:"retention,omitempty"` // Recorder default when zero
+}
+
+// TenantsConfig multi-tenant mode of the HTTP transports, in which every session uses the
+// QWeather credentials sent in its request headers instead of the configured ones
+type TenantsConfig struct {
+	Enabled             bool     `json:"enabled"`
+	IdleTimeout         Duration `json:"idleTimeout"` // Tenants without sessions are dropped after this long
+	MaxTenants          int      `json:"maxTenants"`
+	MaxTenantsPerClient int      `json:"maxTenantsPerClient"` // Tenants a single client, by authenticated subject or IP, may create
+}
+
+// AuthConfig inbound authentication of the HTTP transports, enabled when tokens or a JWKS file
+// are configured
+type AuthConfig struct {
+	Tokens               []AuthToken   `json:"tokens,omitempty"`
+	JWT                  AuthJWTConfig `json:"jwt"`
+	Resource             string        `json:"resource,omitempty"`             // Public URL of the MCP endpoint, e.g. https://mcp.example.com/mcp
+	AuthorizationServers []string      `json:"authorizationServers,omitempty"` // Issuers advertised in the protected resource metadata
+}
+
+// AuthToken static bearer token
+type AuthToken struct {
+	Subject string `json:"subject"` // Name identifying the client in logs and rate limits
+	Token   string `json:"token"`
+}
+
+// AuthJWTConfig validation of OAuth2 access tokens that are JWTs
+type AuthJWTConfig struct {
+	JWKSFile       string   `json:"jwksFile,omitempty"` // Local JWKS file with the issuer's signing keys
+	Issuer         string   `json:"issuer,omitempty"`
+	Audience       string   `json:"audience,omitempty"` // Defaults to the resource
+	RequiredScopes []string `json:"requiredScopes,omitempty"`
+}
+
+// HealthConfig readiness probe of the HTTP transports
+type HealthConfig struct {
+	UpstreamCheck bool     `json:"upstreamCheck"` // Readiness requires a QWeather request with the configured credentials to succeed
+	CheckTTL      Duration `json:"checkTTL"`      // How long the result of the upstream check is reused
+}
+
+// Enabled reports whether requests must be authenticated
+func (a AuthConfig) Enabled() bool {
+	return len(a.Tokens) > 0 || a.JWT.JWKSFile != ""
+}
+
+// Default returns the configuration used for unset values
+func Default() *Config {
+	return &Config{
+		API: APIConfig{
+			AuthMode: AuthModeKey,
+			Timeout:  Duration(10 * time.Second),
+			Units:    UnitsMetric,
+		},
+		Server: ServerConfig{
+			Transport:         TransportSSE,
+			Address:           ":8080",
+			ReadHeaderTimeout: Duration(10 * time.Second),
+			IdleTimeout:       Duration(2 * time.Minute),
+			ShutdownTimeout:   Duration(30 * time.Second),
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: logging.FormatText,
+		},
+		Cache: CacheConfig{
+			CompletionTTL: Duration(tools.DefaultCompletionCacheTTL),
+		},
+		Middleware: MiddlewareConfig{
+			RequestLogging: true,
+			Recovery:       true,
+		},
+		Data: DataConfig{
+			WarningPollInterval: Duration(watcher.DefaultInterval),
+		},
+		Tenants: TenantsConfig{
+			IdleTimeout:         Duration(tenant.DefaultIdleTimeout),
+			MaxTenants:          tenant.DefaultMaxTenants,
+			MaxTenantsPerClient: tenant.DefaultMaxTenantsPerClient,
+		},
+		Health: HealthConfig{
+			CheckTTL: Duration(health.DefaultCheckTTL),
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the config file named by the -config flag or
+// QWEATHER_CONFIG, the environment and the command line arguments, and validates it.
+// printConfig reports whether --print-config was given.
+func Load(args []string, getenv func(string) string, output io.Writer) (cfg *Config, printConfig bool, err error) {
+	cfg = Default()
+
+	fs := flag.NewFlagSet("qweather-mcp-go", flag.ContinueOnError)
+	fs.SetOutput(output)
+	var configFile, transport, port, address, logLevel string
+	fs.StringVar(&configFile, "config", "", "JSON config file")
+	fs.StringVar(&transport, "t", "", "Transport type (stdio, sse, or streamable)")
+	fs.StringVar(&transport, "transport", "", "Transport type (stdio, sse, or streamable)")
+	fs.StringVar(&port, "p", "", "Server listening port (for sse and streamable transports)")
+	fs.StringVar(&port, "port", "", "Server listening port (for sse and streamable transports)")
+	fs.StringVar(&address, "addr", "", "Server listening address, e.g. 127.0.0.1:8080 (overrides the port)")
+	fs.StringVar(&logLevel, "log-level", "", "Log level (debug, info, warn, error)")
+	fs.BoolVar(&printConfig, "print-config", false, "Print the effective configuration with secrets redacted and exit")
+	if err := fs.Parse(args); err != nil {
+		return nil, false, err
+	}
+
+	if configFile == "" {
+		configFile = getenv("QWEATHER_CONFIG")
+	}
+	if configFile != "" {
+		if err := cfg.loadFile(configFile); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := cfg.applyEnv(getenv); err != nil {
+		return nil, false, err
+	}
+
+	// Flags override only when given
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "t", "transport":
+			cfg.Server.Transport = transport
+		case "p", "port":
+			if address == "" {
+				cfg.Server.Address = ":" + port
+			}
+		case "addr":
+			cfg.Server.Address = address
+		case "log-level":
+			cfg.Log.Level = logLevel
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, false, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, printConfig, nil
+}
+
+// loadFile overrides the configuration with the values set in a JSON file
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overrides the configuration with the environment variables that are set
+func (c *Config) applyEnv(getenv func(string) string) error {
+	stringVars := map[string]*string{
+		"QWEATHER_API_BASE":             &c.API.BaseURL,
+		"QWEATHER_API_KEY":              &c.API.Key,
+		"QWEATHER_AUTH_MODE":            &c.API.AuthMode,
+		"QWEATHER_JWT_KEY_ID":           &c.API.JWT.KeyID,
+		"QWEATHER_JWT_PROJECT_ID":       &c.API.JWT.ProjectID,
+		"QWEATHER_JWT_PRIVATE_KEY_FILE": &c.API.JWT.PrivateKeyFile,
+		"QWEATHER_LANG":                 &c.API.Lang,
+		"QWEATHER_UNITS":                &c.API.Units,
+		"QWEATHER_TRANSPORT":            &c.Server.Transport,
+		"QWEATHER_LISTEN_ADDR":          &c.Server.Address,
+		"QWEATHER_LOG_LEVEL":            &c.Log.Level,
+		"QWEATHER_LOG_FILE":             &c.Log.File,
+		"QWEATHER_LOG_FORMAT":           &c.Log.Format,
+		"QWEATHER_SNAPSHOT_DIR":         &c.Data.SnapshotDir,
+		"QWEATHER_RECORDER_DIR":         &c.Data.Recorder.Dir,
+		"QWEATHER_WEBHOOK_CONFIG":       &c.Data.WebhookConfig,
+		"QWEATHER_AUTH_JWKS_FILE":       &c.Auth.JWT.JWKSFile,
+		"QWEATHER_AUTH_ISSUER":          &c.Auth.JWT.Issuer,
+		"QWEATHER_AUTH_AUDIENCE":        &c.Auth.JWT.Audience,
+		"QWEATHER_AUTH_RESOURCE":        &c.Auth.Resource,
+	}
+	for name, field := range stringVars {
+		if v := getenv(name); v != "" {
+			*field = v
+		}
+	}
+
+	durations := map[string]*Duration{
+		"QWEATHER_API_TIMEOUT":           &c.API.Timeout,
+		"QWEATHER_TENANT_IDLE_TIMEOUT":   &c.Tenants.IdleTimeout,
+		"QWEATHER_COMPLETION_CACHE_TTL":  &c.Cache.CompletionTTL,
+		"QWEATHER_RECORDER_INTERVAL":     &c.Data.Recorder.Interval,
+		"QWEATHER_RECORDER_RETENTION":    &c.Data.Recorder.Retention,
+		"QWEATHER_WARNING_POLL_INTERVAL": &c.Data.WarningPollInterval,
+		"QWEATHER_READY_CHECK_TTL":       &c.Health.CheckTTL,
+	}
+	for name, field := range durations {
+		if v := getenv(name); v != "" {
+			duration, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*field = Duration(duration)
+		}
+	}
+
+	lists := map[string]struct {
+		field     *[]string
+		separator string
+	}{
+		"QWEATHER_RECORDER_LOCATIONS": {&c.Data.Recorder.Locations, ";"}, // Coordinates contain commas
+		"QWEATHER_ENABLED_TOOLS":      {&c.Tools.Enabled, ","},
+		"QWEATHER_DISABLED_TOOLS":     {&c.Tools.Disabled, ","},
+	}
+	for name, list := range lists {
+		if v := getenv(name); v != "" {
+			*list.field = splitList(v, list.separator)
+		}
+	}
+
+	// Static tokens as subject:token pairs
+	if v := getenv("QWEATHER_AUTH_TOKENS"); v != "" {
+		c.Auth.Tokens = nil
+		for _, entry := range splitList(v, ",") {
+			subject, token, ok := strings.Cut(entry, ":")
+			if !ok {
+				return fmt.Errorf("invalid QWEATHER_AUTH_TOKENS: entries must be subject:token")
+			}
+			c.Auth.Tokens = append(c.Auth.Tokens, AuthToken{Subject: subject, Token: token})
+		}
+	}
+
+	if v := getenv("QWEATHER_RATE_LIMIT"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(rate) || math.IsInf(rate, 0) {
+			return fmt.Errorf("invalid QWEATHER_RATE_LIMIT: must be requests per minute")
+		}
+		c.Middleware.RateLimit.RequestsPerMinute = rate
+	}
+	if v := getenv("QWEATHER_RATE_LIMIT_BURST"); v != "" {
+		burst, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid QWEATHER_RATE_LIMIT_BURST: must be a number of requests")
+		}
+		c.Middleware.RateLimit.Burst = burst
+	}
+
+	for name, field := range map[string]*bool{
+		"QWEATHER_REQUEST_LOGGING": &c.Middleware.RequestLogging,
+		"QWEATHER_MULTI_TENANT":    &c.Tenants.Enabled,
+		"QWEATHER_TRUST_PROXY":     &c.Middleware.RateLimit.TrustProxy,
+		"QWEATHER_READY_UPSTREAM":  &c.Health.UpstreamCheck,
+	} {
+		if v := getenv(name); v != "" {
+			enabled, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: must be true or false", name)
+			}
+			*field = enabled
+		}
+	}
+	return nil
+}
+
+// splitList splits a list, dropping empty entries
+func splitList(s, separator string) []string {
+	var values []string
+	for _, value := range strings.Split(s, separator) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Validate checks the configuration
+func (c *Config) Validate() error {
+	var errs []error
+
+	u, err := url.Parse(c.API.BaseURL)
+	if c.API.BaseURL == "" {
+		errs = append(errs, fmt.Errorf("api.baseURL (QWEATHER_API_BASE) must be set"))
+	} else if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("api.baseURL must be an absolute http or https URL"))
+	}
+	switch c.API.AuthMode {
+	case AuthModeKey:
+		// Tenants bring their own credentials; the configured ones are only used by the recorder and webhooks
+		if c.API.Key == "" && !c.Tenants.Enabled {
+			errs = append(errs, fmt.Errorf("api.key (QWEATHER_API_KEY) must be set when api.authMode is key"))
+		}
+	case AuthModeJWT:
+		if c.API.JWT.KeyID == "" || c.API.JWT.ProjectID == "" || c.API.JWT.PrivateKeyFile == "" {
+			errs = append(errs, fmt.Errorf("api.jwt.keyID, projectID and privateKeyFile must be set when api.authMode is jwt"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("api.authMode must be %s or %s", AuthModeKey, AuthModeJWT))
+	}
+	if c.API.Units != UnitsMetric {
+		errs = append(errs, fmt.Errorf("api.units must be %s: tool output is expressed in metric units", UnitsMetric))
+	}
+
+	switch c.Server.Transport {
+	case TransportStdio, TransportSSE, TransportStreamable:
+	default:
+		errs = append(errs, fmt.Errorf("server.transport must be one of: stdio, sse, streamable"))
+	}
+	if _, port, err := net.SplitHostPort(c.Server.Address); err != nil || port == "" {
+		errs = append(errs, fmt.Errorf("server.address must be host:port or :port"))
+	}
+
+	if c.Tenants.Enabled {
+		if c.Server.Transport == TransportStdio {
+			errs = append(errs, fmt.Errorf("tenants.enabled requires the sse or streamable transport"))
+		}
+		if c.Tenants.MaxTenants <= 0 {
+			errs = append(errs, fmt.Errorf("tenants.maxTenants must be positive"))
+		}
+		if c.Tenants.MaxTenantsPerClient <= 0 {
+			errs = append(errs, fmt.Errorf("tenants.maxTenantsPerClient must be positive"))
+		}
+	}
+
+	if rateLimit := c.Middleware.RateLimit; rateLimit.RequestsPerMinute < 0 || rateLimit.Burst < 0 {
+		errs = append(errs, fmt.Errorf("middleware.rateLimit.requestsPerMinute and burst cannot be negative"))
+	}
+
+	errs = append(errs, c.Auth.validate()...)
+
+	if c.Health.UpstreamCheck && c.API.AuthMode == AuthModeKey && c.API.Key == "" {
+		errs = append(errs, fmt.Errorf("health.upstreamCheck requires api.key or JWT credentials"))
+	}
+
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+	if c.Log.Format != logging.FormatText && c.Log.Format != logging.FormatJSON {
+		errs = append(errs, fmt.Errorf("log.format must be %s or %s", logging.FormatText, logging.FormatJSON))
+	}
+
+	for _, d := range []struct {
+		name  string
+		value Duration
+	}{
+		{"api.timeout", c.API.Timeout},
+		{"server.readHeaderTimeout", c.Server.ReadHeaderTimeout},
+		{"server.idleTimeout", c.Server.IdleTimeout},
+		{"server.shutdownTimeout", c.Server.ShutdownTimeout},
+		{"cache.completionTTL", c.Cache.CompletionTTL},
+		{"data.warningPollInterval", c.Data.WarningPollInterval},
+		{"tenants.idleTimeout", c.Tenants.IdleTimeout},
+		{"health.checkTTL", c.Health.CheckTTL},
+	} {
+		if d.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be a positive duration", d.name))
+		}
+	}
+	if c.Data.Recorder.Interval < 0 || c.Data.Recorder.Retention < 0 {
+		errs = append(errs, fmt.Errorf("data.recorder.interval and retention cannot be negative"))
+	}
+
+	known := tools.ToolNames()
+	for _, name := range append(slices.Clone(c.Tools.Enabled), c.Tools.Disabled...) {
+		if !slices.Contains(known, name) {
+			errs = append(errs, fmt.Errorf("tools: unknown tool %q", name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the inbound authentication settings
+func (a AuthConfig) validate() []error {
+	var errs []error
+	subjects := make(map[string]bool)
+	for i, token := range a.Tokens {
+		if token.Subject == "" || token.Token == "" {
+			errs = append(errs, fmt.Errorf("auth.tokens[%d]: subject and token must be set", i))
+		} else if subjects[token.Subject] {
+			errs = append(errs, fmt.Errorf("auth.tokens[%d]: duplicate subject %q", i, token.Subject))
+		}
+		subjects[token.Subject] = true
+	}
+	if a.Resource != "" {
+		if u, err := url.Parse(a.Resource); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Fragment != "" {
+			errs = append(errs, fmt.Errorf("auth.resource must be an absolute http or https URL without fragment"))
+		}
+	}
+	jwt := a.JWT
+	if jwt.JWKSFile == "" && (jwt.Issuer != "" || jwt.Audience != "" || len(jwt.RequiredScopes) > 0) {
+		errs = append(errs, fmt.Errorf("auth.jwt.jwksFile must be set to validate JWTs"))
+	}
+	// Tokens must be checked to be issued for this server
+	if jwt.JWKSFile != "" && a.Resource == "" {
+		errs = append(errs, fmt.Errorf("auth.resource must be set when auth.jwt is configured"))
+	}
+	return errs
+}
+
+// ToolEnabled reports whether a tool is offered to clients
+func (c *Config) ToolEnabled(name string) bool {
+	if slices.Contains(c.Tools.Disabled, name) {
+		return false
+	}
+	return len(c.Tools.Enabled) == 0 || slices.Contains(c.Tools.Enabled, name)
+}
+
+// Redacted returns a copy of the configuration with secrets replaced, for printing
+func (c *Config) Redacted() *Config {
+	redactedConfig := *c
+	if redactedConfig.API.Key != "" {
+		redactedConfig.API.Key = redacted
+	}
+	redactedConfig.Auth.Tokens = nil
+	for _, token := range c.Auth.Tokens {
+		redactedConfig.Auth.Tokens = append(redactedConfig.Auth.Tokens, AuthToken{Subject: token.Subject, Token: redacted})
+	}
+	return &redactedConfig
+}
+
+// Print writes the configuration as indented JSON with secrets redacted
+func (c *Config) Print(w io.Writer) error {
+	data, err := json.MarshalIndent(c.Redacted(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
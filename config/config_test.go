@@ -0,0 +1,132 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env returns a getenv function reading from a map
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestLoad_Layering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{
+		"api": {"baseURL": "https://file.example.com", "key": "file-key", "timeout": "5s", "lang": "en"},
+		"server": {"transport": "streamable", "address": ":7000"},
+		"log": {"level": "warn"},
+		"tools": {"disabled": ["get-route-weather"]},
+		"middleware": {"requestLogging": false}
+	}`), 0o600)
+
+	cfg, printConfig, err := Load(
+		[]string{"-config", path, "-p", "9000"},
+		env(map[string]string{"QWEATHER_API_KEY": "env-key", "QWEATHER_LOG_LEVEL": "debug", "QWEATHER_RECORDER_LOCATIONS": "Beijing; 116.41,39.92"}),
+		&bytes.Buffer{},
+	)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if printConfig {
+		t.Fatal("printConfig = true without --print-config")
+	}
+
+	// File over defaults, environment over file, flags over environment
+	if cfg.API.BaseURL != "https://file.example.com" || cfg.API.Key != "env-key" || cfg.API.Lang != "en" {
+		t.Fatalf("API = %+v", cfg.API)
+	}
+	if time.Duration(cfg.API.Timeout) != 5*time.Second || time.Duration(cfg.Server.ShutdownTimeout) != 30*time.Second {
+		t.Fatalf("timeouts = %v, %v", cfg.API.Timeout, cfg.Server.ShutdownTimeout)
+	}
+	if cfg.Server.Transport != TransportStreamable || cfg.Server.Address != ":9000" {
+		t.Fatalf("Server = %+v", cfg.Server)
+	}
+	if cfg.Log.Level != "debug" {
+		t.Fatalf("Log.Level = %q, want debug", cfg.Log.Level)
+	}
+	if cfg.Middleware.RequestLogging || !cfg.Middleware.Recovery {
+		t.Fatalf("Middleware = %+v", cfg.Middleware)
+	}
+	if got := cfg.Data.Recorder.Locations; len(got) != 2 || got[1] != "116.41,39.92" {
+		t.Fatalf("Recorder.Locations = %q", got)
+	}
+	if cfg.ToolEnabled("get-route-weather") || !cfg.ToolEnabled("get-weather-now") {
+		t.Fatal("get-route-weather should be the only disabled tool")
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	valid := map[string]string{"QWEATHER_API_BASE": "https://api.example.com", "QWEATHER_API_KEY": "key"}
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want string
+	}{
+		{"missing key", nil, map[string]string{"QWEATHER_API_BASE": "https://api.example.com"}, "api.key"},
+		{"transport", []string{"-t", "websocket"}, valid, "server.transport"},
+		{"log level", []string{"-log-level", "loud"}, valid, "log.level"},
+		{"duration", nil, map[string]string{"QWEATHER_API_TIMEOUT": "soon"}, "QWEATHER_API_TIMEOUT"},
+		{"unknown tool", nil, map[string]string{"QWEATHER_API_BASE": "https://api.example.com", "QWEATHER_API_KEY": "key", "QWEATHER_ENABLED_TOOLS": "get-weather-now,get-horoscope"}, "get-horoscope"},
+		{"jwt", nil, map[string]string{"QWEATHER_API_BASE": "https://api.example.com", "QWEATHER_AUTH_MODE": "jwt"}, "api.jwt"},
+		{"units", nil, map[string]string{"QWEATHER_API_BASE": "https://api.example.com", "QWEATHER_API_KEY": "key", "QWEATHER_UNITS": "imperial"}, "api.units"},
+		{"tenants on stdio", []string{"-t", "stdio"}, map[string]string{"QWEATHER_API_BASE": "https://api.example.com", "QWEATHER_MULTI_TENANT": "true"}, "tenants.enabled"},
+		{"rate limit", nil, map[string]string{"QWEATHER_API_BASE": "https://api.example.com", "QWEATHER_API_KEY": "key", "QWEATHER_RATE_LIMIT": "-5"}, "middleware.rateLimit"},
+		{"upstream check without key", nil, map[string]string{"QWEATHER_API_BASE": "https://api.example.com", "QWEATHER_MULTI_TENANT": "true", "QWEATHER_READY_UPSTREAM": "true"}, "health.upstreamCheck"},
+		{"auth token", nil, map[string]string{"QWEATHER_API_BASE": "https://api.example.com", "QWEATHER_API_KEY": "key", "QWEATHER_AUTH_TOKENS": "ci"}, "QWEATHER_AUTH_TOKENS"},
+		{"auth jwt without resource", nil, map[string]string{"QWEATHER_API_BASE": "https://api.example.com", "QWEATHER_API_KEY": "key", "QWEATHER_AUTH_JWKS_FILE": "jwks.json"}, "auth.resource"},
+		{"auth resource", nil, map[string]string{"QWEATHER_API_BASE": "https://api.example.com", "QWEATHER_API_KEY": "key", "QWEATHER_AUTH_JWKS_FILE": "jwks.json", "QWEATHER_AUTH_RESOURCE": "mcp.example.com"}, "auth.resource"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Load(tt.args, env(tt.env), &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load error = %v, want mention of %q", err, tt.want)
+			}
+		})
+	}
+
+	// Tenants bring their own keys
+	if _, _, err := Load(nil, env(map[string]string{"QWEATHER_API_BASE": "https://api.example.com", "QWEATHER_MULTI_TENANT": "true"}), &bytes.Buffer{}); err != nil {
+		t.Fatalf("Load without API key in multi-tenant mode failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"api": {"basePath": "/v7"}}`), 0o600)
+	if _, _, err := Load([]string{"-config", path}, env(valid), &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "basePath") {
+		t.Fatalf("Load error = %v, want unknown field basePath", err)
+	}
+}
+
+func TestPrint_RedactsSecrets(t *testing.T) {
+	cfg, printConfig, err := Load([]string{"--print-config"}, env(map[string]string{
+		"QWEATHER_API_BASE":    "https://api.example.com",
+		"QWEATHER_API_KEY":     "super-secret-key",
+		"QWEATHER_AUTH_TOKENS": "ci:inbound-secret",
+	}), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !printConfig {
+		t.Fatal("printConfig = false with --print-config")
+	}
+
+	var out bytes.Buffer
+	if err := cfg.Print(&out); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+	if strings.Contains(out.String(), "super-secret-key") || !strings.Contains(out.String(), `"key": "REDACTED"`) {
+		t.Fatalf("printed configuration does not redact the API key:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "inbound-secret") || !strings.Contains(out.String(), `"subject": "ci"`) {
+		t.Fatalf("printed configuration does not redact the auth tokens:\n%s", out.String())
+	}
+	if cfg.API.Key != "super-secret-key" {
+		t.Fatal("Print modified the configuration")
+	}
+}
@@ -2,10 +2,12 @@ module github.com/overstarry/qweather-mcp-go
 
 go 1.25.0
 
-require github.com/modelcontextprotocol/go-sdk v1.0.0
+require (
+	github.com/google/jsonschema-go v0.3.0
+	github.com/modelcontextprotocol/go-sdk v1.0.0
+	github.com/yosida95/uritemplate/v3 v3.0.2
+)
 
 require (
-	github.com/google/jsonschema-go v0.3.0 // indirect
-	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
 	golang.org/x/tools v0.42.0 // indirect
 )
@@ -0,0 +1,161 @@
+// Package health serves the liveness and readiness probes of the HTTP transports.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// Probe paths
+const (
+	HealthzPath = "/healthz"
+	ReadyzPath  = "/readyz"
+)
+
+// DefaultCheckTTL how long the result of the upstream check is reused
+const DefaultCheckTTL = time.Minute
+
+// checkTimeout how long the upstream check may take
+const checkTimeout = 10 * time.Second
+
+// checkLocation location looked up by the upstream check; a geo lookup of an ID is the cheapest
+// request QWeather answers
+const checkLocation = "101010100"
+
+// UpstreamStatus result of the upstream check
+type UpstreamStatus struct {
+	OK        bool      `json:"ok"`
+	Error     string    `json:"error,omitempty"`
+	Latency   string    `json:"latency"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Checker reports the readiness of the server: the result of an optional upstream check and the
+// state of other components
+type Checker struct {
+	client    *api.Client // The upstream check is skipped when nil
+	ttl       time.Duration
+	now       func() time.Time
+	reporters []reporter
+
+	mu       sync.Mutex // Held during checks so that concurrent probes share one
+	upstream *UpstreamStatus
+}
+
+// reporter reports the state of a component
+type reporter struct {
+	name  string
+	state func() any
+}
+
+// NewChecker creates a checker. The upstream is checked with the client at most once per TTL;
+// with a nil client readiness does not depend on the upstream.
+func NewChecker(client *api.Client, ttl time.Duration) *Checker {
+	if ttl <= 0 {
+		ttl = DefaultCheckTTL
+	}
+	return &Checker{client: client, ttl: ttl, now: time.Now}
+}
+
+// Report adds the state of a component to readiness responses under a name. The state must be
+// JSON serializable and cheap to get.
+func (c *Checker) Report(name string, state func() any) {
+	c.reporters = append(c.reporters, reporter{name: name, state: state})
+}
+
+// Upstream returns the result of the upstream check, checking again when the last result is
+// older than the TTL, or nil when the check is disabled. The check does not use the context of
+// the probe, so that a probe giving up cannot fail readiness for the whole TTL.
+func (c *Checker) Upstream() *UpstreamStatus {
+	if c.client == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if c.upstream != nil && now.Sub(c.upstream.CheckedAt) < c.ttl {
+		return c.upstream
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	status := &UpstreamStatus{OK: true, CheckedAt: now}
+	err := c.checkUpstream(ctx)
+	if err != nil {
+		status.OK = false
+		status.Error = err.Error()
+	}
+	status.Latency = c.now().Sub(now).Round(time.Millisecond).String()
+	// A timed out check may only be slow, so it fails this probe and the next one checks again
+	// rather than failing readiness for the whole TTL
+	if !isTimeout(err) {
+		c.upstream = status
+	}
+	return status
+}
+
+// isTimeout reports whether an error is a timeout, of either the check or the client
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// checkUpstream looks a location up with the configured credentials
+func (c *Checker) checkUpstream(ctx context.Context) error {
+	data, err := c.client.MakeRequestWithContext(ctx, "/geo/v2/city/lookup", map[string]string{
+		"location": checkLocation,
+		"number":   "1",
+	})
+	if err != nil {
+		return err
+	}
+	var response api.LocationResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("failed to parse location data: %w", err)
+	}
+	if response.Code != api.APICodeSuccess {
+		return fmt.Errorf("API returned error code: %s", response.Code)
+	}
+	return nil
+}
+
+// HealthzHandler reports that the process is up
+func HealthzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write([]byte("ok\n"))
+	})
+}
+
+// ReadyzHandler reports readiness as JSON, with 503 Service Unavailable when the upstream check
+// fails
+func (c *Checker) ReadyzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]any{"status": "ready"}
+		code := http.StatusOK
+		if upstream := c.Upstream(); upstream != nil {
+			body["upstream"] = upstream
+			if !upstream.OK {
+				body["status"] = "unavailable"
+				code = http.StatusServiceUnavailable
+			}
+		}
+		for _, reporter := range c.reporters {
+			body[reporter.name] = reporter.state()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(body)
+	})
+}
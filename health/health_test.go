@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+func TestReadyzHandler(t *testing.T) {
+	var requests int
+	status := http.StatusOK
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(status)
+		w.Write([]byte(`{"code":"200","location":[{"id":"101010100"}]}`))
+	}))
+	defer upstream.Close()
+
+	client := api.NewClient(upstream.URL, "key")
+	now := time.Now()
+	checker := NewChecker(client, time.Minute)
+	checker.now = func() time.Time { return now }
+	checker.Report("quota", func() any { return client.QuotaState() })
+
+	probe := func() (int, map[string]any) {
+		rr := httptest.NewRecorder()
+		checker.ReadyzHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, ReadyzPath, nil))
+		var body map[string]any
+		if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+			t.Fatalf("readiness is not JSON: %v", err)
+		}
+		return rr.Code, body
+	}
+
+	// A probe that gave up does not fail the check
+	rr := httptest.NewRecorder()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	checker.ReadyzHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, ReadyzPath, nil).WithContext(cancelled))
+	if rr.Code != http.StatusOK || requests != 1 {
+		t.Fatalf("readyz with a cancelled probe = %d after %d upstream requests, want %d after 1", rr.Code, requests, http.StatusOK)
+	}
+
+	code, body := probe()
+	if code != http.StatusOK || body["status"] != "ready" || body["quota"] == nil {
+		t.Fatalf("readyz = %d %v", code, body)
+	}
+
+	// The result is reused within the TTL
+	status = http.StatusPaymentRequired
+	if code, _ := probe(); code != http.StatusOK || requests != 1 {
+		t.Fatalf("readyz = %d after %d upstream requests, want cached result", code, requests)
+	}
+
+	now = now.Add(2 * time.Minute)
+	code, body = probe()
+	if code != http.StatusServiceUnavailable || body["status"] != "unavailable" || requests != 2 {
+		t.Fatalf("readyz = %d %v after %d upstream requests", code, body, requests)
+	}
+	if quota := body["quota"].(map[string]any); quota["exceeded"] != true || quota["lastStatus"] != float64(http.StatusPaymentRequired) {
+		t.Fatalf("quota = %v, want exhausted quota reported", quota)
+	}
+}
+
+func TestReadyzHandler_TimeoutNotCached(t *testing.T) {
+	var requests atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Write([]byte(`{"code":"200","location":[{"id":"101010100"}]}`))
+	}))
+	defer upstream.Close()
+
+	client := api.NewClient(upstream.URL, "key")
+	client.HTTPClient.Timeout = 20 * time.Millisecond
+	checker := NewChecker(client, time.Minute)
+
+	if status := checker.Upstream(); status.OK {
+		t.Fatalf("upstream status = %+v, want the timed out check to fail", status)
+	}
+	if status := checker.Upstream(); !status.OK || requests.Load() != 2 {
+		t.Fatalf("upstream status = %+v after %d requests, want a new successful check", status, requests.Load())
+	}
+}
+
+func TestReadyzHandler_WithoutUpstreamCheck(t *testing.T) {
+	checker := NewChecker(nil, 0)
+	rr := httptest.NewRecorder()
+	checker.ReadyzHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, ReadyzPath, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("readyz = %d, want %d", rr.Code, http.StatusOK)
+	}
+
+	rr = httptest.NewRecorder()
+	HealthzHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, HealthzPath, nil))
+	if rr.Code != http.StatusOK || rr.Body.String() != "ok\n" {
+		t.Fatalf("healthz = %d %q", rr.Code, rr.Body.String())
+	}
+}
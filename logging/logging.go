@@ -0,0 +1,238 @@
+// Package logging sets up the server's slog logger. Records are written to stderr or a file,
+// never to stdout which carries the stdio transport. Records made while handling a client's
+// requests are forwarded to that client as logging notifications at the level it requested.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// LoggerName logger name of the notifications forwarded to MCP clients
+const LoggerName = "qweather"
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options output of the local log
+type Options struct {
+	Level  string // debug, info, warn or error; defaults to info
+	File   string // Log file, appended to; stderr if empty
+	Format string // text or json; defaults to text
+}
+
+// ParseLevel converts a level name to a slog level
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level %q: must be one of debug, info, warn, error", name)
+}
+
+// New creates a logger writing to the configured output and forwarding to the clients of the
+// forwarder. The returned closer closes the log file, if any.
+func New(opts Options, forwarder *Forwarder) (*slog.Logger, io.Closer, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var out io.WriteCloser = nopCloser{os.Stderr}
+	if opts.File != "" {
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		out = file
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var local slog.Handler
+	switch opts.Format {
+	case "", FormatText:
+		local = slog.NewTextHandler(out, handlerOpts)
+	case FormatJSON:
+		local = slog.NewJSONHandler(out, handlerOpts)
+	default:
+		out.Close()
+		return nil, nil, fmt.Errorf("invalid log format %q: must be text or json", opts.Format)
+	}
+
+	if forwarder == nil {
+		return slog.New(local), out, nil
+	}
+	return slog.New(teeHandler{local, forwarder.Handler()}), out, nil
+}
+
+// nopCloser keeps stderr open when the logger is closed
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// teeHandler sends records to every handler that is enabled for their level
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var firstErr error
+	for _, h := range t {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
+
+// Forwarder sends log records to the MCP session they were made for. Records are only forwarded
+// when their context carries the session whose request was being handled, so that no client
+// receives records about other clients' requests. Servers are attached after the logger is
+// created, since they are themselves built with logging in place.
+type Forwarder struct {
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession]*mcp.LoggingHandler
+}
+
+// NewForwarder creates a forwarder with no server attached
+func NewForwarder() *Forwarder {
+	return &Forwarder{sessions: make(map[*mcp.ServerSession]*mcp.LoggingHandler)}
+}
+
+// Attach starts forwarding to the sessions of a server, whose request contexts are made to
+// carry their session. Any number of servers may be attached, such as one per tenant.
+func (f *Forwarder) Attach(server *mcp.Server) {
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if session, ok := req.GetSession().(*mcp.ServerSession); ok {
+				f.track(session)
+				ctx = WithSession(ctx, session)
+			}
+			return next(ctx, method, req)
+		}
+	})
+}
+
+// Handler returns the slog handler forwarding records to the attached servers' sessions
+func (f *Forwarder) Handler() slog.Handler {
+	return &forwardHandler{forwarder: f}
+}
+
+type sessionKey struct{}
+
+// WithSession returns a context whose log records are forwarded to a session of an attached
+// server
+func WithSession(ctx context.Context, session *mcp.ServerSession) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// track creates the logging handler of a session on its first request and drops it once the
+// session is closed
+func (f *Forwarder) track(session *mcp.ServerSession) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.sessions[session]; ok {
+		return
+	}
+	f.sessions[session] = mcp.NewLoggingHandler(session, &mcp.LoggingHandlerOptions{LoggerName: LoggerName})
+	go func() {
+		session.Wait()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.sessions, session)
+	}()
+}
+
+// handler returns the logging handler of the session a context carries, or nil when it carries
+// none or the session is closed or belongs to no attached server
+func (f *Forwarder) handler(ctx context.Context) *mcp.LoggingHandler {
+	session, _ := ctx.Value(sessionKey{}).(*mcp.ServerSession)
+	if session == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sessions[session]
+}
+
+// forwardHandler slog handler of a Forwarder. Attributes and groups are replayed onto the
+// session's handler, which filters records by the level its client requested.
+type forwardHandler struct {
+	forwarder *Forwarder
+	derive    []func(slog.Handler) slog.Handler
+}
+
+func (h *forwardHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	session := h.forwarder.handler(ctx)
+	return session != nil && session.Enabled(ctx, level)
+}
+
+func (h *forwardHandler) Handle(ctx context.Context, r slog.Record) error {
+	session := h.forwarder.handler(ctx)
+	if session == nil {
+		return nil
+	}
+	var handler slog.Handler = session
+	for _, derive := range h.derive {
+		handler = derive(handler)
+	}
+	if handler.Enabled(ctx, r.Level) {
+		// Delivery failures do not affect the local log
+		handler.Handle(ctx, r)
+	}
+	return nil
+}
+
+func (h *forwardHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *forwardHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h *forwardHandler) with(derive func(slog.Handler) slog.Handler) slog.Handler {
+	return &forwardHandler{forwarder: h.forwarder, derive: append(h.derive[:len(h.derive):len(h.derive)], derive)}
+}
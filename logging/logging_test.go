@@ -0,0 +1,241 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+	"github.com/overstarry/qweather-mcp-go/tools"
+)
+
+func TestParseLevel(t *testing.T) {
+	for _, name := range []string{"", "debug", "INFO", "warning", "error"} {
+		if _, err := ParseLevel(name); err != nil {
+			t.Fatalf("ParseLevel(%q) failed: %v", name, err)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Fatal("expected error for unknown level")
+	}
+}
+
+func TestNew_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	logger, closer, err := New(Options{Level: "warn", File: path, Format: FormatJSON}, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	logger.Info("dropped")
+	logger.Warn("kept", "location", "101010100")
+	closer.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("log file has %d records, want 1: %s", len(lines), data)
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if record["msg"] != "kept" || record["location"] != "101010100" {
+		t.Fatalf("record = %v", record)
+	}
+
+	if _, _, err := New(Options{Format: "xml"}, nil); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
+
+// connectClient connects a client to a server, returning the client's session and a channel of
+// the log messages it receives
+func connectClient(t *testing.T, server *mcp.Server, level mcp.LoggingLevel) (*mcp.ClientSession, chan *mcp.LoggingMessageParams) {
+	t.Helper()
+	messages := make(chan *mcp.LoggingMessageParams, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			messages <- req.Params
+		},
+	})
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: level}); err != nil {
+		t.Fatalf("SetLoggingLevel failed: %v", err)
+	}
+	return session, messages
+}
+
+func TestForwarder_SessionScope(t *testing.T) {
+	forwarder := NewForwarder()
+	logger, _, err := New(Options{Level: "error", File: filepath.Join(t.TempDir(), "server.log")}, forwarder)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "lookup"}, func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+		logger.InfoContext(ctx, "not forwarded")
+		logger.With("location", "101010100").WarnContext(ctx, "forwarded")
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	forwarder.Attach(server)
+
+	caller, callerMessages := connectClient(t, server, "warning")
+	_, otherMessages := connectClient(t, server, "warning")
+
+	// Records without a session are not forwarded to anyone
+	logger.Warn("server-wide")
+
+	// The caller's level applies even though the local log only keeps errors
+	if _, err := caller.CallTool(context.Background(), &mcp.CallToolParams{Name: "lookup"}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	select {
+	case msg := <-callerMessages:
+		if msg.Level != "warning" || msg.Logger != LoggerName {
+			t.Fatalf("message = %+v", msg)
+		}
+		data, _ := json.Marshal(msg.Data)
+		if !strings.Contains(string(data), `"forwarded"`) || !strings.Contains(string(data), "101010100") {
+			t.Fatalf("message data = %s", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no log message forwarded")
+	}
+
+	select {
+	case msg := <-callerMessages:
+		t.Fatalf("unexpected message %+v", msg)
+	case msg := <-otherMessages:
+		t.Fatalf("message of another session forwarded: %+v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestForwarder_SeveralServers(t *testing.T) {
+	forwarder := NewForwarder()
+	logger, _, err := New(Options{Level: "error", File: filepath.Join(t.TempDir(), "server.log")}, forwarder)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	// Every tenant has its own server
+	var sessions []*mcp.ClientSession
+	var messages []chan *mcp.LoggingMessageParams
+	for _, name := range []string{"tenant-a", "tenant-b"} {
+		server := mcp.NewServer(&mcp.Implementation{Name: name, Version: "1.0.0"}, nil)
+		mcp.AddTool(server, &mcp.Tool{Name: "lookup"}, func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+			logger.WarnContext(ctx, "forwarded", "server", name)
+			return &mcp.CallToolResult{}, nil, nil
+		})
+		forwarder.Attach(server)
+		session, received := connectClient(t, server, "warning")
+		sessions = append(sessions, session)
+		messages = append(messages, received)
+	}
+
+	for i, session := range sessions {
+		if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "lookup"}); err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+		select {
+		case msg := <-messages[i]:
+			data, _ := json.Marshal(msg.Data)
+			if !strings.Contains(string(data), []string{"tenant-a", "tenant-b"}[i]) {
+				t.Fatalf("message data = %s", data)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no log message forwarded to session %d", i)
+		}
+	}
+
+	// Handlers of closed sessions are dropped
+	for _, session := range sessions {
+		session.Close()
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		forwarder.mu.Lock()
+		n := len(forwarder.sessions)
+		forwarder.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("forwarder keeps %d closed sessions", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestForwarder_ToolCall(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			w.Write([]byte(`{"code":"200","location":[{"name":"Beijing","id":"101010100","lat":"39.90","lon":"116.41"}]}`))
+		default:
+			w.Write([]byte(`{"code":"200","now":{"temp":"20","humidity":"50","text":"Sunny"}}`))
+		}
+	}))
+	defer upstream.Close()
+
+	forwarder := NewForwarder()
+	logger, _, err := New(Options{Level: "error", File: filepath.Join(t.TempDir(), "server.log")}, forwarder)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	// The API client logs with the default logger, as it does in the server
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	client := api.NewClient(upstream.URL, "key")
+	client.SetLogLevel(api.LogLevelInfo)
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	tools.RegisterWeatherTools(server, client)
+	forwarder.Attach(server)
+
+	caller, messages := connectClient(t, server, "info")
+	result, err := caller.CallTool(context.Background(), &mcp.CallToolParams{Name: "get-weather-now", Arguments: map[string]any{"cityName": "Beijing"}})
+	if err != nil || result.IsError {
+		t.Fatalf("CallTool failed: %v %+v", err, result)
+	}
+
+	var endpoints []string
+	for len(endpoints) < 2 {
+		select {
+		case msg := <-messages:
+			data, _ := json.Marshal(msg.Data)
+			var record map[string]any
+			json.Unmarshal(data, &record)
+			if record["msg"] == "API response" {
+				endpoints = append(endpoints, record["endpoint"].(string))
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("API responses forwarded for %v, want the city lookup and the current weather", endpoints)
+		}
+	}
+	if endpoints[0] != "/geo/v2/city/lookup" || endpoints[1] != "/v7/weather/now" {
+		t.Fatalf("forwarded endpoints = %v", endpoints)
+	}
+}
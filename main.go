@@ -2,150 +2,370 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/overstarry/qweather-mcp-go/api"
+	"github.com/overstarry/qweather-mcp-go/config"
+	"github.com/overstarry/qweather-mcp-go/health"
+	"github.com/overstarry/qweather-mcp-go/logging"
 	"github.com/overstarry/qweather-mcp-go/middlewares"
+	"github.com/overstarry/qweather-mcp-go/recorder"
+	"github.com/overstarry/qweather-mcp-go/snapshot"
+	"github.com/overstarry/qweather-mcp-go/tenant"
 	"github.com/overstarry/qweather-mcp-go/tools"
+	"github.com/overstarry/qweather-mcp-go/watcher"
+	"github.com/overstarry/qweather-mcp-go/webhook"
 )
 
 func main() {
-	// Command line arguments
-	var transport string
-	var port string
-	flag.StringVar(&transport, "t", "sse", "Transport type (stdio, sse, or streamable)")
-	flag.StringVar(&transport, "transport", "sse", "Transport type (stdio, sse, or streamable)")
-	flag.StringVar(&port, "p", "8080", "Server listening port (for sse and streamable transports)")
-	flag.StringVar(&port, "port", "8080", "Server listening port (for sse and streamable transports)")
-	flag.Parse()
+	// Configuration from the config file, environment variables and command line arguments
+	cfg, printConfig, err := config.Load(os.Args[1:], os.Getenv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Logs go to stderr or a file since stdout carries the stdio transport. Records made while
+	// handling a client's request are forwarded to that client at the level it requested.
+	forwarder := logging.NewForwarder()
+	logger, logFile, err := logging.New(logging.Options{
+		Level:  cfg.Log.Level,
+		File:   cfg.Log.File,
+		Format: cfg.Log.Format,
+	}, forwarder)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer logFile.Close()
+	slog.SetDefault(logger)
+
+	// Create API client; its records are filtered by the slog handlers
+	client, err := newClient(cfg.API)
+	if err != nil {
+		fatal("Invalid API configuration", "error", err)
+	}
+	client.SetLogLevel(api.LogLevelDebug)
+
+	// Forecast snapshots are kept in memory unless a snapshot directory is configured
+	var snapshots snapshot.Store = snapshot.NewMemoryStore(0)
+	if dir := cfg.Data.SnapshotDir; dir != "" {
+		fileStore, err := snapshot.NewFileStore(dir, 0)
+		if err != nil {
+			fatal("Invalid snapshot directory", "error", err)
+		}
+		snapshots = fileStore
+	}
+
+	// Optional recorder building a local history of current conditions for configured locations
+	var history recorder.Store
+	if dir := cfg.Data.Recorder.Dir; dir != "" {
+		fileStore, err := recorder.NewFileStore(dir)
+		if err != nil {
+			fatal("Invalid recorder directory", "error", err)
+		}
+		history = fileStore
+
+		recorderConfig := recorder.Config{
+			Locations: cfg.Data.Recorder.Locations,
+			Interval:  time.Duration(cfg.Data.Recorder.Interval),
+			Retention: time.Duration(cfg.Data.Recorder.Retention),
+		}
+		if len(recorderConfig.Locations) > 0 {
+			go recorder.New(client, fileStore, recorderConfig).Run(context.Background())
+		}
+	}
+
+	// Optional webhooks notifying configured endpoints of warnings, AQI and rain onset
+	if path := cfg.Data.WebhookConfig; path != "" {
+		webhookConfig, err := webhook.LoadConfig(path)
+		if err != nil {
+			fatal("Invalid webhook config", "error", err)
+		}
+		dispatcher := webhook.NewDispatcher(webhookConfig.Endpoints, webhook.Options{
+			MaxAttempts:    webhookConfig.MaxAttempts,
+			DeadLetterPath: webhookConfig.DeadLetterFile,
+		})
+		go webhook.NewMonitor(client, dispatcher, webhookConfig).Run(context.Background())
+	}
 
-	// Validate transport type
-	if transport != "stdio" && transport != "sse" && transport != "streamable" {
-		log.Fatalf("Invalid transport type: %s. Must be one of: stdio, sse, streamable", transport)
+	// Readiness reports the upstream quota and, when enabled, checks the configured credentials
+	var checkClient *api.Client
+	if cfg.Health.UpstreamCheck {
+		checkClient = client
+	}
+	checker := health.NewChecker(checkClient, time.Duration(cfg.Health.CheckTTL))
+	checker.Report("quota", func() any { return client.QuotaState() })
+
+	// Create MCP server; in multi-tenant mode every tenant gets its own server instead
+	ctx := context.Background()
+	var getServer func(*http.Request) *mcp.Server
+	if cfg.Tenants.Enabled {
+		pool := tenant.NewPool(func(ctx context.Context, client *api.Client) *mcp.Server {
+			// Tenants only receive the log records of their own sessions, and only see their own
+			// snapshots; the history of the configured locations is shared
+			s, _ := newServer(ctx, cfg, client, serverData{snapshots: snapshot.NewMemoryStore(0), history: history})
+			forwarder.Attach(s)
+			return s
+		}, tenant.Options{
+			BaseURL:             cfg.API.BaseURL,
+			Timeout:             time.Duration(cfg.API.Timeout),
+			Lang:                cfg.API.Lang,
+			IdleTimeout:         time.Duration(cfg.Tenants.IdleTimeout),
+			MaxTenants:          cfg.Tenants.MaxTenants,
+			MaxTenantsPerClient: cfg.Tenants.MaxTenantsPerClient,
+			ClientKey: func(r *http.Request) string {
+				if identity := middlewares.IdentityFromContext(r.Context()); identity != nil {
+					return "subject:" + identity.Subject
+				}
+				return "ip:" + tenant.RemoteIP(r)
+			},
+		})
+		go pool.Run(ctx)
+		getServer = pool.Server
+		checker.Report("tenants", func() any { return pool.Len() })
+	} else {
+		s, completer := newServer(ctx, cfg, client, serverData{snapshots: snapshots, history: history})
+		forwarder.Attach(s)
+		checker.Report("cache", func() any { return completer.CacheState() })
+		getServer = func(*http.Request) *mcp.Server { return s }
 	}
 
-	// Get configuration from environment variables
-	baseURL := os.Getenv("QWEATHER_API_BASE")
-	apiKey := os.Getenv("QWEATHER_API_KEY")
+	// Start server based on transport type
+	switch cfg.Server.Transport {
+	case config.TransportStdio:
+		slog.Info("QWeather MCP server running on stdio transport")
+		if err := getServer(nil).Run(ctx, &mcp.StdioTransport{}); err != nil {
+			fatal("Stdio server error", "error", err)
+		}
+
+	case config.TransportSSE:
+		// Create SSE HTTP handler
+		handler := mcp.NewSSEHandler(getServer, &mcp.SSEOptions{})
+		serveHTTP("SSE", handler, cfg, checker)
 
-	if baseURL == "" || apiKey == "" {
-		log.Fatal("Environment variables QWEATHER_API_BASE and QWEATHER_API_KEY must be set")
+	case config.TransportStreamable:
+		// Create Streamable HTTP server (official implementation)
+		handler := mcp.NewStreamableHTTPHandler(getServer, nil)
+		serveHTTP("Streamable HTTP", handler, cfg, checker)
 	}
+}
+
+// serverData local data of a server
+type serverData struct {
+	snapshots snapshot.Store
+	history   recorder.Store // nil when the recorder is disabled
+}
 
-	// Create API client
-	client := api.NewClient(baseURL, apiKey)
+// newServer creates an MCP server with the enabled tools, resources and prompts, using a client
+// for API requests, and returns it with its completer. Warning subscriptions are polled until the
+// context is cancelled.
+func newServer(ctx context.Context, cfg *config.Config, client *api.Client, data serverData) (*mcp.Server, *tools.Completer) {
+	// Sessions subscribing to a location's warnings resource are notified of warning changes
+	warnings := watcher.New(client, time.Duration(cfg.Data.WarningPollInterval))
+	completer := tools.NewCompleter(client)
+	completer.SetCacheTTL(time.Duration(cfg.Cache.CompletionTTL))
 
-	// Create MCP server
 	s := mcp.NewServer(&mcp.Implementation{
 		Name:    "qweather",
 		Version: "1.0.0",
-	}, nil)
+	}, &mcp.ServerOptions{
+		SubscribeHandler:   warnings.Subscribe,
+		UnsubscribeHandler: warnings.Unsubscribe,
+		CompletionHandler:  completer.Complete,
+	})
 
 	// Register tools
 	tools.RegisterWeatherTools(s, client)
 	tools.RegisterAirQualityTools(s, client)
 	tools.RegisterIndicesTools(s, client)
-
-	// Start server based on transport type
-	addr := ":" + port
-	ctx := context.Background()
-	switch transport {
-	case "stdio":
-		fmt.Println("QWeather MCP server running on stdio transport")
-		if err := s.Run(ctx, &mcp.StdioTransport{}); err != nil {
-			log.Fatal(err)
+	tools.RegisterCompareTools(s, client)
+	tools.RegisterBriefingTools(s, client)
+	tools.RegisterRouteTools(s, client)
+	tools.RegisterActivityTools(s, client)
+	tools.RegisterConditionTools(s, client)
+	tools.RegisterForecastChangeTools(s, client, data.snapshots)
+	if data.history != nil {
+		tools.RegisterHistoryTools(s, client, data.history)
+	}
+	for _, name := range tools.ToolNames() {
+		if !cfg.ToolEnabled(name) {
+			s.RemoveTools(name)
 		}
+	}
 
-	case "sse":
-		baseURL := "http://localhost:" + port
-
-		// Create SSE HTTP handler
-		handler := mcp.NewSSEHandler(func(req *http.Request) *mcp.Server {
-			return s
-		}, &mcp.SSEOptions{})
+	// Register resources
+	tools.RegisterResources(s, client)
+	go warnings.Run(ctx, s)
 
-		// Apply middlewares: recovery first, then logging
-		handlerWithMiddlewares := middlewares.LoggingHandler(middlewares.RecoveryHandler(handler))
-		fmt.Printf("QWeather MCP server running on SSE transport, listening at %s\n", addr)
-		log.Printf("MCP server listening on %s", baseURL)
-		fmt.Printf("SSE endpoint: %s\n", baseURL)
+	// Register prompts
+	tools.RegisterPrompts(s)
+	return s, completer
+}
 
-		// Create HTTP server with graceful shutdown support
-		srv := &http.Server{
-			Addr:    addr,
-			Handler: handlerWithMiddlewares,
+// newClient creates the API client authenticating as configured
+func newClient(apiConfig config.APIConfig) (*api.Client, error) {
+	client := api.NewClient(apiConfig.BaseURL, apiConfig.Key)
+	client.HTTPClient.Timeout = time.Duration(apiConfig.Timeout)
+	client.Lang = apiConfig.Lang
+	if apiConfig.AuthMode == config.AuthModeJWT {
+		privateKey, err := os.ReadFile(apiConfig.JWT.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT private key: %w", err)
 		}
+		if client.JWT, err = api.NewJWTSigner(apiConfig.JWT.KeyID, apiConfig.JWT.ProjectID, privateKey); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
 
-		// Start server in background
-		go func() {
-			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				log.Fatalf("SSE server error: %v", err)
-			}
-		}()
-
-		// Wait for interrupt signal
-		quit := make(chan os.Signal, 1)
-		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-		<-quit
-		log.Println("Shutting down SSE server...")
+// serveHTTP serves an MCP HTTP handler with the configured middlewares and the health probes until
+// interrupted, then shuts down gracefully
+func serveHTTP(name string, handler http.Handler, cfg *config.Config, checker *health.Checker) {
+	// Rate limits run after authentication to identify clients by subject
+	if rateLimit := cfg.Middleware.RateLimit; rateLimit.RequestsPerMinute > 0 {
+		handler = middlewares.RateLimitHandler(handler, middlewares.NewRateLimiter(middlewares.RateLimitOptions{
+			RequestsPerMinute: rateLimit.RequestsPerMinute,
+			Burst:             rateLimit.Burst,
+			TrustProxy:        rateLimit.TrustProxy,
+			TenantCredentials: cfg.Tenants.Enabled,
+		}))
+	}
 
-		// Graceful shutdown with 30 second timeout
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		if err := srv.Shutdown(shutdownCtx); err != nil {
-			log.Fatal("SSE server forced to shutdown:", err)
+	// Authentication applies to the MCP endpoint but not to the metadata telling clients how to authenticate
+	mux := http.NewServeMux()
+	if cfg.Auth.Enabled() {
+		authenticated, err := authenticate(mux, handler, cfg.Auth)
+		if err != nil {
+			fatal("Invalid auth configuration", "error", err)
 		}
-		log.Println("SSE server exited")
+		handler = authenticated
+	}
+	mux.Handle("/", handler)
 
-	case "streamable":
-		baseURL := "http://localhost:" + port
+	// Probes bypass authentication, rate limits and the MCP handler
+	mux.Handle(health.HealthzPath, health.HealthzHandler())
+	mux.Handle(health.ReadyzPath, checker.ReadyzHandler())
+	handler = mux
 
-		// Create Streamable HTTP server (official implementation)
-		handler := mcp.NewStreamableHTTPHandler(func(req *http.Request) *mcp.Server {
-			return s
-		}, nil)
+	// Apply middlewares: recovery first, then logging
+	if cfg.Middleware.Recovery {
+		handler = middlewares.RecoveryHandler(handler)
+	}
+	if cfg.Middleware.RequestLogging {
+		handler = middlewares.LoggingHandler(handler)
+	}
+
+	addr := cfg.Server.Address
+	_, port, _ := net.SplitHostPort(addr)
+	slog.Info("QWeather MCP server running on "+name+" transport", "addr", addr, "endpoint", "http://localhost:"+port)
 
-		// Apply middlewares: recovery first, then logging
-		handlerWithMiddlewares := middlewares.LoggingHandler(middlewares.RecoveryHandler(handler))
-		fmt.Printf("QWeather MCP server running on Streamable HTTP transport, listening at %s\n", addr)
-		log.Printf("MCP server listening on %s", baseURL)
+	// Create HTTP server with graceful shutdown support
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: time.Duration(cfg.Server.ReadHeaderTimeout),
+		IdleTimeout:       time.Duration(cfg.Server.IdleTimeout),
+	}
 
-		// Create HTTP server with graceful shutdown support
-		srv := &http.Server{
-			Addr:    addr,
-			Handler: handlerWithMiddlewares,
+	// Start server in background
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fatal(name+" server error", "error", err)
 		}
+	}()
+
+	// Wait for interrupt signal
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	slog.Info("Shutting down " + name + " server")
 
-		// Start server in background
-		go func() {
-			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				log.Fatalf("Streamable server error: %v", err)
-			}
-		}()
+	// Graceful shutdown within the configured timeout
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout))
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		fatal(name+" server forced to shutdown", "error", err)
+	}
+	slog.Info(name + " server exited")
+}
 
-		// Wait for interrupt signal
-		quit := make(chan os.Signal, 1)
-		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-		<-quit
-		log.Println("Shutting down Streamable server...")
+// authenticate wraps the MCP handler in the configured authentication and mounts the protected
+// resource metadata on the mux
+func authenticate(mux *http.ServeMux, handler http.Handler, authConfig config.AuthConfig) (http.Handler, error) {
+	var authenticators middlewares.Authenticators
+	if len(authConfig.Tokens) > 0 {
+		tokens := make(map[string]string, len(authConfig.Tokens))
+		for _, token := range authConfig.Tokens {
+			tokens[token.Subject] = token.Token
+		}
+		authenticators = append(authenticators, middlewares.NewStaticTokens(tokens))
+	}
+	if authConfig.JWT.JWKSFile != "" {
+		audience := authConfig.JWT.Audience
+		if audience == "" {
+			audience = authConfig.Resource
+		}
+		verifier, err := middlewares.NewJWTVerifier(authConfig.JWT.JWKSFile, middlewares.JWTOptions{
+			Issuer:         authConfig.JWT.Issuer,
+			Audience:       audience,
+			RequiredScopes: authConfig.JWT.RequiredScopes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, verifier)
+	}
 
-		// Graceful shutdown with 30 second timeout
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		if err := srv.Shutdown(shutdownCtx); err != nil {
-			log.Fatal("Streamable server forced to shutdown:", err)
+	var opts middlewares.AuthOptions
+	if authConfig.Resource != "" {
+		// The metadata of a resource with a path lives under the well-known path followed by that path (RFC 9728)
+		resource, err := url.Parse(authConfig.Resource)
+		if err != nil {
+			return nil, fmt.Errorf("invalid auth resource: %w", err)
 		}
-		log.Println("Streamable server exited")
+		metadataPath := middlewares.ProtectedResourceMetadataPath + strings.TrimSuffix(resource.Path, "/")
+		opts.ResourceMetadataURL = resource.Scheme + "://" + resource.Host + metadataPath
 
-	default:
-		log.Fatalf("Unsupported transport type: %s", transport)
+		metadata := middlewares.ProtectedResourceMetadataHandler(middlewares.ProtectedResourceMetadata{
+			Resource:             authConfig.Resource,
+			AuthorizationServers: authConfig.AuthorizationServers,
+			ScopesSupported:      authConfig.JWT.RequiredScopes,
+			ResourceName:         "QWeather MCP server",
+		})
+		mux.Handle(metadataPath, metadata)
+		if metadataPath != middlewares.ProtectedResourceMetadataPath {
+			mux.Handle(middlewares.ProtectedResourceMetadataPath, metadata)
+		}
 	}
+	return middlewares.AuthHandler(handler, authenticators, opts), nil
+}
+
+// fatal logs an error and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
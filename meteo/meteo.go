@@ -0,0 +1,116 @@
+// Package meteo provides pure functions for derived meteorological quantities
+// such as apparent temperature, comfort indices and humidity measures.
+//
+// Temperatures are in degrees Celsius, relative humidity in percent (0-100)
+// and wind speeds in the unit named by each parameter.
+package meteo
+
+import "math"
+
+// Magnus formula coefficients (Alduchov and Eskridge, 1996)
+const (
+	magnusA = 17.625
+	magnusB = 243.04 // °C
+)
+
+// CelsiusToFahrenheit converts a temperature from °C to °F
+func CelsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+// FahrenheitToCelsius converts a temperature from °F to °C
+func FahrenheitToCelsius(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
+
+// DewPoint returns the dew point in °C from air temperature and relative humidity,
+// using the Magnus formula.
+func DewPoint(tempC, rh float64) float64 {
+	if rh <= 0 {
+		return math.Inf(-1)
+	}
+	gamma := math.Log(rh/100) + magnusA*tempC/(magnusB+tempC)
+	return magnusB * gamma / (magnusA - gamma)
+}
+
+// VaporPressure returns the saturation vapour pressure in hPa at the given temperature
+func VaporPressure(tempC float64) float64 {
+	return 6.1094 * math.Exp(magnusA*tempC/(magnusB+tempC))
+}
+
+// AbsoluteHumidity returns the mass of water vapour per volume of air in g/m³
+func AbsoluteHumidity(tempC, rh float64) float64 {
+	// Ideal gas law with the specific gas constant of water vapour (461.5 J/(kg·K))
+	e := VaporPressure(tempC) * rh / 100 * 100 // Pa
+	return e / (461.5 * (tempC + 273.15)) * 1000
+}
+
+// HeatIndex returns the NWS heat index in °C, using the Rothfusz regression with
+// the Steadman simple formula below 80°F and the NWS low/high humidity adjustments.
+func HeatIndex(tempC, rh float64) float64 {
+	t := CelsiusToFahrenheit(tempC)
+
+	simple := 0.5 * (t + 61.0 + (t-68.0)*1.2 + rh*0.094)
+	if (simple+t)/2 < 80 {
+		return FahrenheitToCelsius(simple)
+	}
+
+	hi := -42.379 + 2.04901523*t + 10.14333127*rh -
+		0.22475541*t*rh - 0.00683783*t*t - 0.05481717*rh*rh +
+		0.00122874*t*t*rh + 0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+
+	switch {
+	case rh < 13 && t >= 80 && t <= 112:
+		hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+	case rh > 85 && t >= 80 && t <= 87:
+		hi += (rh - 85) / 10 * (87 - t) / 5
+	}
+	return FahrenheitToCelsius(hi)
+}
+
+// WindChill returns the wind chill temperature in °C (North American formula, 2001).
+// The index is only defined for temperatures at or below 10°C and winds above 4.8 km/h;
+// outside that range the air temperature is returned.
+func WindChill(tempC, windKmh float64) float64 {
+	if tempC > 10 || windKmh <= 4.8 {
+		return tempC
+	}
+	v := math.Pow(windKmh, 0.16)
+	return 13.12 + 0.6215*tempC - 11.37*v + 0.3965*tempC*v
+}
+
+// Humidex returns the Canadian humidex from air temperature and dew point, both in °C
+func Humidex(tempC, dewPointC float64) float64 {
+	e := 6.11 * math.Exp(5417.7530*(1/273.16-1/(dewPointC+273.15)))
+	return tempC + 0.5555*(e-10)
+}
+
+// ApparentTemperature returns the Australian apparent temperature in °C
+// (Steadman, 1994, as used by the Bureau of Meteorology), accounting for
+// humidity and wind but not solar radiation.
+func ApparentTemperature(tempC, rh, windMs float64) float64 {
+	e := rh / 100 * 6.105 * math.Exp(17.27*tempC/(237.7+tempC))
+	return tempC + 0.33*e - 0.70*windMs - 4.00
+}
+
+// WetBulb returns the wet-bulb temperature in °C at sea-level pressure (Stull, 2011).
+// The approximation is valid for relative humidity between 5% and 99% and
+// temperatures between -20°C and 50°C.
+func WetBulb(tempC, rh float64) float64 {
+	return tempC*math.Atan(0.151977*math.Sqrt(rh+8.313659)) +
+		math.Atan(tempC+rh) - math.Atan(rh-1.676331) +
+		0.00391838*math.Pow(rh, 1.5)*math.Atan(0.023101*rh) - 4.686035
+}
+
+// FeelsLike returns the temperature as perceived by people in °C: the wind chill
+// in cold, windy conditions, the heat index in hot weather and the air temperature otherwise.
+func FeelsLike(tempC, rh, windKmh float64) float64 {
+	switch {
+	case tempC <= 10 && windKmh > 4.8:
+		return WindChill(tempC, windKmh)
+	case tempC >= 27:
+		return HeatIndex(tempC, rh)
+	default:
+		return tempC
+	}
+}
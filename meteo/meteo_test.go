@@ -0,0 +1,81 @@
+package meteo
+
+import (
+	"math"
+	"testing"
+)
+
+// Reference values are taken from published tables and worked examples;
+// the tolerance accounts for the rounding used in those tables.
+func TestDerivedQuantities(t *testing.T) {
+	tests := []struct {
+		name      string
+		got       float64
+		want      float64
+		tolerance float64
+	}{
+		// NWS heat index chart (°F): 90°F/50% -> 95°F, 100°F/40% -> 109°F, 80°F/80% -> 84°F
+		{"heat index 90F 50%", CelsiusToFahrenheit(HeatIndex(FahrenheitToCelsius(90), 50)), 95, 1},
+		{"heat index 100F 40%", CelsiusToFahrenheit(HeatIndex(FahrenheitToCelsius(100), 40)), 109, 1},
+		{"heat index 80F 80%", CelsiusToFahrenheit(HeatIndex(FahrenheitToCelsius(80), 80)), 84, 1},
+		{"heat index mild weather", HeatIndex(20, 50), 19.7, 0.5},
+
+		// Environment Canada wind chill chart
+		{"wind chill -20C 30km/h", WindChill(-20, 30), -33, 0.5},
+		{"wind chill -10C 20km/h", WindChill(-10, 20), -18, 0.5},
+		{"wind chill 0C 10km/h", WindChill(0, 10), -3, 0.5},
+		{"wind chill undefined above 10C", WindChill(15, 30), 15, 0},
+		{"wind chill undefined in calm air", WindChill(-5, 3), -5, 0},
+
+		// Environment Canada humidex table: 30°C with dew point 15°C -> 34, 35°C/25°C -> 47
+		{"humidex 30C dew 15C", Humidex(30, 15), 34, 0.5},
+		{"humidex 35C dew 25C", Humidex(35, 25), 47, 0.5},
+
+		// Dew point tables
+		{"dew point 25C 60%", DewPoint(25, 60), 16.7, 0.1},
+		{"dew point 20C 50%", DewPoint(20, 50), 9.3, 0.1},
+		{"dew point saturated", DewPoint(15, 100), 15, 0.01},
+
+		// Absolute humidity: saturated air at 20°C holds 17.3 g/m³, 25°C/50% holds 11.5 g/m³
+		{"absolute humidity 20C 100%", AbsoluteHumidity(20, 100), 17.3, 0.1},
+		{"absolute humidity 25C 50%", AbsoluteHumidity(25, 50), 11.5, 0.1},
+
+		// Stull (2011) worked example: 20°C and 50% -> 13.7°C
+		{"wet bulb 20C 50%", WetBulb(20, 50), 13.7, 0.1},
+		{"wet bulb 30C 80%", WetBulb(30, 80), 27.1, 0.2},
+
+		// Bureau of Meteorology apparent temperature
+		{"apparent temperature 25C 50% calm", ApparentTemperature(25, 50, 0), 26.2, 0.1},
+		{"apparent temperature 10C 70% 5m/s", ApparentTemperature(10, 70, 5), 5.3, 0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if math.Abs(tt.got-tt.want) > tt.tolerance {
+				t.Errorf("got %.2f, want %.2f ± %.2f", tt.got, tt.want, tt.tolerance)
+			}
+		})
+	}
+}
+
+func TestFeelsLike(t *testing.T) {
+	tests := []struct {
+		name    string
+		tempC   float64
+		rh      float64
+		windKmh float64
+		want    float64
+	}{
+		{"cold and windy uses wind chill", -10, 50, 20, WindChill(-10, 20)},
+		{"hot uses heat index", 32, 60, 10, HeatIndex(32, 60)},
+		{"mild uses air temperature", 18, 60, 10, 18},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FeelsLike(tt.tempC, tt.rh, tt.windKmh); got != tt.want {
+				t.Errorf("FeelsLike(%v, %v, %v) = %.2f, want %.2f", tt.tempC, tt.rh, tt.windKmh, got, tt.want)
+			}
+		})
+	}
+}
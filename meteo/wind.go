@@ -0,0 +1,69 @@
+package meteo
+
+import "math"
+
+// Wind speed units accepted by ConvertWindSpeed
+const (
+	SpeedKmh   = "km/h"
+	SpeedMs    = "m/s"
+	SpeedMph   = "mph"
+	SpeedKnots = "knots"
+)
+
+// compassPoints16 the 16-point compass rose, clockwise from north
+var compassPoints16 = []string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// beaufortUpperKmh upper bounds (exclusive, km/h) of Beaufort forces 0 to 11; faster winds are force 12
+var beaufortUpperKmh = []float64{1, 6, 12, 20, 29, 39, 50, 62, 75, 89, 103, 118}
+
+// beaufortDescriptions WMO descriptions of Beaufort forces 0 to 12
+var beaufortDescriptions = []string{
+	"Calm", "Light air", "Light breeze", "Gentle breeze", "Moderate breeze", "Fresh breeze", "Strong breeze",
+	"Near gale", "Gale", "Strong gale", "Storm", "Violent storm", "Hurricane force",
+}
+
+// CompassPoint returns the 16-point compass direction (e.g. "NNE") for a bearing in degrees
+func CompassPoint(degrees float64) string {
+	degrees = math.Mod(degrees, 360)
+	if degrees < 0 {
+		degrees += 360
+	}
+	return compassPoints16[int(math.Round(degrees/22.5))%16]
+}
+
+// Beaufort returns the Beaufort force (0-12) for a wind speed in km/h
+func Beaufort(speedKmh float64) int {
+	for force, upper := range beaufortUpperKmh {
+		if speedKmh < upper {
+			return force
+		}
+	}
+	return 12
+}
+
+// BeaufortDescription returns the WMO description of a Beaufort force, or "" if out of range
+func BeaufortDescription(force int) string {
+	if force < 0 || force >= len(beaufortDescriptions) {
+		return ""
+	}
+	return beaufortDescriptions[force]
+}
+
+// ConvertWindSpeed converts a speed in km/h to the given unit; ok is false for unknown units
+func ConvertWindSpeed(speedKmh float64, unit string) (speed float64, ok bool) {
+	switch unit {
+	case SpeedKmh:
+		return speedKmh, true
+	case SpeedMs:
+		return speedKmh / 3.6, true
+	case SpeedMph:
+		return speedKmh / 1.609344, true
+	case SpeedKnots:
+		return speedKmh / 1.852, true
+	default:
+		return 0, false
+	}
+}
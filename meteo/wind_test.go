@@ -0,0 +1,82 @@
+package meteo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompassPoint(t *testing.T) {
+	tests := []struct {
+		degrees float64
+		want    string
+	}{
+		{0, "N"},
+		{11.2, "N"},
+		{11.3, "NNE"},
+		{45, "NE"},
+		{180, "S"},
+		{247.5, "WSW"},
+		{348.75, "N"},
+		{360, "N"},
+		{-90, "W"},
+	}
+
+	for _, tt := range tests {
+		if got := CompassPoint(tt.degrees); got != tt.want {
+			t.Errorf("CompassPoint(%v) = %q, want %q", tt.degrees, got, tt.want)
+		}
+	}
+}
+
+func TestBeaufort(t *testing.T) {
+	tests := []struct {
+		speedKmh    float64
+		want        int
+		description string
+	}{
+		{0, 0, "Calm"},
+		{5, 1, "Light air"},
+		{19, 3, "Gentle breeze"},
+		{20, 4, "Moderate breeze"},
+		{45, 6, "Strong breeze"},
+		{100, 10, "Storm"},
+		{150, 12, "Hurricane force"},
+	}
+
+	for _, tt := range tests {
+		got := Beaufort(tt.speedKmh)
+		if got != tt.want {
+			t.Errorf("Beaufort(%v) = %d, want %d", tt.speedKmh, got, tt.want)
+		}
+		if desc := BeaufortDescription(got); desc != tt.description {
+			t.Errorf("BeaufortDescription(%d) = %q, want %q", got, desc, tt.description)
+		}
+	}
+
+	if desc := BeaufortDescription(13); desc != "" {
+		t.Errorf("BeaufortDescription(13) = %q, want empty", desc)
+	}
+}
+
+func TestConvertWindSpeed(t *testing.T) {
+	tests := []struct {
+		unit string
+		want float64
+	}{
+		{SpeedKmh, 36},
+		{SpeedMs, 10},
+		{SpeedMph, 22.37},
+		{SpeedKnots, 19.44},
+	}
+
+	for _, tt := range tests {
+		got, ok := ConvertWindSpeed(36, tt.unit)
+		if !ok || math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("ConvertWindSpeed(36, %q) = (%.2f, %v), want %.2f", tt.unit, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := ConvertWindSpeed(36, "furlongs"); ok {
+		t.Error("ConvertWindSpeed accepted an unknown unit")
+	}
+}
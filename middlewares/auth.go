@@ -0,0 +1,189 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ProtectedResourceMetadataPath well-known path of the OAuth protected resource metadata (RFC 9728)
+const ProtectedResourceMetadataPath = "/.well-known/oauth-protected-resource"
+
+// ErrInvalidToken is returned by authenticators for tokens they do not accept
+var ErrInvalidToken = errors.New("invalid token")
+
+// ScopeError is returned by authenticators for valid tokens lacking required scopes
+type ScopeError struct {
+	Required []string
+}
+
+func (e *ScopeError) Error() string {
+	return fmt.Sprintf("token lacks required scopes: %s", strings.Join(e.Required, " "))
+}
+
+// Identity authenticated caller of a request
+type Identity struct {
+	Subject string // Token name or JWT subject
+	Scopes  []string
+}
+
+type identityKey struct{}
+
+// IdentityFromContext returns the identity AuthHandler authenticated, or nil
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+// Authenticator verifies bearer tokens
+type Authenticator interface {
+	// Authenticate returns the identity a token belongs to, ErrInvalidToken or a *ScopeError
+	Authenticate(r *http.Request, token string) (*Identity, error)
+}
+
+// Authenticators accepts a token if any of its authenticators does
+type Authenticators []Authenticator
+
+func (a Authenticators) Authenticate(r *http.Request, token string) (*Identity, error) {
+	err := ErrInvalidToken
+	for _, authenticator := range a {
+		identity, authErr := authenticator.Authenticate(r, token)
+		if authErr == nil {
+			return identity, nil
+		}
+		// A token recognised but lacking scopes is reported as such
+		var scopeErr *ScopeError
+		if errors.As(authErr, &scopeErr) {
+			err = authErr
+		}
+	}
+	return nil, err
+}
+
+// StaticTokens accepts fixed tokens, each naming the subject it authenticates
+type StaticTokens struct {
+	tokens []staticToken
+}
+
+type staticToken struct {
+	hash    [sha256.Size]byte
+	subject string
+}
+
+// NewStaticTokens creates an authenticator for tokens by subject
+func NewStaticTokens(tokensBySubject map[string]string) *StaticTokens {
+	s := &StaticTokens{}
+	for subject, token := range tokensBySubject {
+		s.tokens = append(s.tokens, staticToken{hash: sha256.Sum256([]byte(token)), subject: subject})
+	}
+	return s
+}
+
+func (s *StaticTokens) Authenticate(_ *http.Request, token string) (*Identity, error) {
+	// Hashes have a fixed length and every token is compared, so timing reveals nothing
+	hash := sha256.Sum256([]byte(token))
+	var match *staticToken
+	for i := range s.tokens {
+		if subtle.ConstantTimeCompare(hash[:], s.tokens[i].hash[:]) == 1 {
+			match = &s.tokens[i]
+		}
+	}
+	if match == nil {
+		return nil, ErrInvalidToken
+	}
+	return &Identity{Subject: match.subject}, nil
+}
+
+// AuthOptions settings of AuthHandler
+type AuthOptions struct {
+	// ResourceMetadataURL absolute URL of the protected resource metadata, advertised in
+	// WWW-Authenticate challenges
+	ResourceMetadataURL string
+}
+
+// AuthHandler requires requests to carry a bearer token accepted by the authenticator. Rejected
+// requests get a 401, or a 403 for missing scopes, with a WWW-Authenticate challenge pointing
+// clients to the protected resource metadata as the MCP authorization spec requires.
+func AuthHandler(handler http.Handler, authenticator Authenticator, opts AuthOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			challenge(w, opts, http.StatusUnauthorized, "", "", "missing bearer token")
+			return
+		}
+		identity, err := authenticator.Authenticate(r, token)
+		var scopeErr *ScopeError
+		switch {
+		case errors.As(err, &scopeErr):
+			challenge(w, opts, http.StatusForbidden, "insufficient_scope", strings.Join(scopeErr.Required, " "), err.Error())
+			return
+		case err != nil:
+			challenge(w, opts, http.StatusUnauthorized, "invalid_token", "", err.Error())
+			return
+		}
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, identity)))
+	})
+}
+
+// bearerToken returns the token of the Authorization header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	token = strings.TrimSpace(token)
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// challenge rejects a request with a Bearer WWW-Authenticate challenge (RFC 6750)
+func challenge(w http.ResponseWriter, opts AuthOptions, status int, errorCode, scope, description string) {
+	var params []string
+	if errorCode != "" {
+		params = append(params, fmt.Sprintf("error=%q", errorCode), fmt.Sprintf("error_description=%q", description))
+	}
+	if scope != "" {
+		params = append(params, fmt.Sprintf("scope=%q", scope))
+	}
+	if opts.ResourceMetadataURL != "" {
+		params = append(params, fmt.Sprintf("resource_metadata=%q", opts.ResourceMetadataURL))
+	}
+	value := "Bearer"
+	if len(params) > 0 {
+		value += " " + strings.Join(params, ", ")
+	}
+	w.Header().Set("WWW-Authenticate", value)
+	http.Error(w, description, status)
+}
+
+// ProtectedResourceMetadata OAuth protected resource metadata (RFC 9728)
+type ProtectedResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers,omitempty"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported"`
+	ResourceName           string   `json:"resource_name,omitempty"`
+}
+
+// ProtectedResourceMetadataHandler serves the protected resource metadata. It must be mounted
+// outside AuthHandler, since clients read it before they have a token.
+func ProtectedResourceMetadataHandler(metadata ProtectedResourceMetadata) http.Handler {
+	if metadata.BearerMethodsSupported == nil {
+		metadata.BearerMethodsSupported = []string{"header"}
+	}
+	body, _ := json.Marshal(metadata)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Write(body)
+	})
+}
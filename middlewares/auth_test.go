@@ -0,0 +1,162 @@
+package middlewares
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testResource = "https://mcp.example.com/mcp"
+
+// signJWT signs claims with a key, as an issuer would
+func signJWT(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(k, []byte(signed))
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		r, s, _ := ecdsa.Sign(rand.Reader, k, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// writeJWKS writes the public keys of an RSA, an EC and an Ed25519 key to a JWKS file
+func writeJWKS(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey, edKey ed25519.PrivateKey) string {
+	t.Helper()
+	encode := base64.RawURLEncoding.EncodeToString
+	jwks := map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "alg": "RS256", "use": "sig", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": encode(ecKey.X.FillBytes(make([]byte, 32))), "y": encode(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "OKP", "kid": "ed-1", "crv": "Ed25519", "x": encode(edKey.Public().(ed25519.PublicKey))},
+	}}
+	data, _ := json.Marshal(jwks)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write JWKS: %v", err)
+	}
+	return path
+}
+
+func TestAuthHandler(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	verifier, err := NewJWTVerifier(writeJWKS(t, rsaKey, ecKey, edKey), JWTOptions{
+		Issuer:         "https://auth.example.com",
+		Audience:       testResource,
+		RequiredScopes: []string{"weather:read"},
+	})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier failed: %v", err)
+	}
+	metadataURL := "https://mcp.example.com" + ProtectedResourceMetadataPath + "/mcp"
+	var subject string
+	h := AuthHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject = IdentityFromContext(r.Context()).Subject
+	}), Authenticators{NewStaticTokens(map[string]string{"ci": "static-secret"}), verifier}, AuthOptions{ResourceMetadataURL: metadataURL})
+
+	now := time.Now().Unix()
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"iss": "https://auth.example.com", "sub": "user-1", "aud": []string{testResource},
+			"exp": now + 300, "scope": "weather:read profile",
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantSubject   string
+		wantChallenge string
+	}{
+		{"missing token", "", http.StatusUnauthorized, "", `Bearer resource_metadata="` + metadataURL + `"`},
+		{"basic auth", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, "", `resource_metadata=`},
+		{"static token", "Bearer static-secret", http.StatusOK, "ci", ""},
+		{"wrong static token", "Bearer static-secre", http.StatusUnauthorized, "", `error="invalid_token"`},
+		{"rs256", "Bearer " + signJWT(t, "RS256", "rsa-1", rsaKey, claims(nil)), http.StatusOK, "user-1", ""},
+		{"es256", "Bearer " + signJWT(t, "ES256", "ec-1", ecKey, claims(nil)), http.StatusOK, "user-1", ""},
+		{"eddsa string audience", "Bearer " + signJWT(t, "EdDSA", "ed-1", edKey, claims(map[string]any{"aud": testResource})), http.StatusOK, "user-1", ""},
+		{"unknown signer", "Bearer " + signJWT(t, "RS256", "rsa-1", otherKey, claims(nil)), http.StatusUnauthorized, "", `error="invalid_token"`},
+		{"algorithm mismatch", "Bearer " + signJWT(t, "EdDSA", "rsa-1", edKey, claims(nil)), http.StatusUnauthorized, "", `error="invalid_token"`},
+		{"expired", "Bearer " + signJWT(t, "EdDSA", "ed-1", edKey, claims(map[string]any{"exp": now - 3600})), http.StatusUnauthorized, "", `error="invalid_token"`},
+		{"no expiry", "Bearer " + signJWT(t, "EdDSA", "ed-1", edKey, claims(map[string]any{"exp": nil})), http.StatusUnauthorized, "", `error="invalid_token"`},
+		{"other audience", "Bearer " + signJWT(t, "EdDSA", "ed-1", edKey, claims(map[string]any{"aud": "https://other.example.com"})), http.StatusUnauthorized, "", `error="invalid_token"`},
+		{"other issuer", "Bearer " + signJWT(t, "EdDSA", "ed-1", edKey, claims(map[string]any{"iss": "https://evil.example.com"})), http.StatusUnauthorized, "", `error="invalid_token"`},
+		{"missing scope", "Bearer " + signJWT(t, "EdDSA", "ed-1", edKey, claims(map[string]any{"scope": "profile"})), http.StatusForbidden, "", `error="insufficient_scope", error_description="token lacks required scopes: weather:read", scope="weather:read"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject = ""
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus || subject != tt.wantSubject {
+				t.Fatalf("status = %d, subject = %q; want %d, %q (body %q)", rr.Code, subject, tt.wantStatus, tt.wantSubject, rr.Body.String())
+			}
+			if challenge := rr.Header().Get("WWW-Authenticate"); !strings.Contains(challenge, tt.wantChallenge) {
+				t.Fatalf("WWW-Authenticate = %q, want to contain %q", challenge, tt.wantChallenge)
+			}
+		})
+	}
+}
+
+func TestProtectedResourceMetadataHandler(t *testing.T) {
+	h := ProtectedResourceMetadataHandler(ProtectedResourceMetadata{
+		Resource:             testResource,
+		AuthorizationServers: []string{"https://auth.example.com"},
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, ProtectedResourceMetadataPath+"/mcp", nil))
+
+	var metadata map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &metadata); err != nil {
+		t.Fatalf("metadata is not JSON: %v", err)
+	}
+	if metadata["resource"] != testResource || rr.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("metadata = %v", metadata)
+	}
+	if methods, _ := metadata["bearer_methods_supported"].([]any); len(methods) != 1 || methods[0] != "header" {
+		t.Fatalf("bearer_methods_supported = %v", metadata["bearer_methods_supported"])
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, ProtectedResourceMetadataPath, nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST status = %d, want %d", rr.Code, http.StatusMethodNotAllowed)
+	}
+}
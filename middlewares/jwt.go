@@ -0,0 +1,270 @@
+package middlewares
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultJWTLeeway clock skew tolerated when checking exp and nbf
+const DefaultJWTLeeway = time.Minute
+
+// JWTOptions claims a JWT must carry
+type JWTOptions struct {
+	Issuer         string   // Required iss; not checked when empty
+	Audience       string   // Required aud entry, normally the resource URL; not checked when empty
+	RequiredScopes []string // Scopes the scope (or scp) claim must include
+	Leeway         time.Duration
+}
+
+// JWTVerifier accepts OAuth2 access tokens that are JWTs signed by a key of a local JWKS file
+type JWTVerifier struct {
+	keys []jwk
+	opts JWTOptions
+	now  func() time.Time
+}
+
+// jwk verification key of a JWKS
+type jwk struct {
+	kid string
+	alg string // Algorithm the key is restricted to; any matching its type when empty
+	key crypto.PublicKey
+}
+
+// jwkJSON JSON Web Key members used for RSA, EC and OKP public keys (RFC 7517, 7518, 8037)
+type jwkJSON struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewJWTVerifier creates a verifier with the keys of a JWKS file
+func NewJWTVerifier(jwksPath string, opts JWTOptions) (*JWTVerifier, error) {
+	data, err := os.ReadFile(jwksPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS: %w", err)
+	}
+	var set struct {
+		Keys []jwkJSON `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	v := &JWTVerifier{opts: opts, now: time.Now}
+	if v.opts.Leeway <= 0 {
+		v.opts.Leeway = DefaultJWTLeeway
+	}
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWKS key %d: %w", i+1, err)
+		}
+		v.keys = append(v.keys, jwk{kid: k.Kid, alg: k.Alg, key: key})
+	}
+	if len(v.keys) == 0 {
+		return nil, fmt.Errorf("JWKS has no signature keys")
+	}
+	return v, nil
+}
+
+// publicKey decodes the public key of a JWK
+func (k jwkJSON) publicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch k.Kty {
+	case "RSA":
+		n, errN := decode(k.N)
+		e, errE := decode(k.E)
+		if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid RSA key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", k.Crv)
+		}
+		x, errX := decode(k.X)
+		y, errY := decode(k.Y)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("invalid EC key")
+		}
+		// The uncompressed point encoding lets ecdsa validate that the point is on the curve
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, fmt.Errorf("invalid EC key")
+		}
+		point := append(append([]byte{4}, x...), y...)
+		key, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC key: %w", err)
+		}
+		return key, nil
+	case "OKP":
+		x, err := decode(k.X)
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported or invalid OKP key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// jwtClaims registered claims checked by the verifier
+type jwtClaims struct {
+	Issuer    string          `json:"iss"`
+	Subject   string          `json:"sub"`
+	Audience  json.RawMessage `json:"aud"` // String or array of strings
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+	Scope     string          `json:"scope"`
+	Scp       []string        `json:"scp"`
+}
+
+func (v *JWTVerifier) Authenticate(_ *http.Request, token string) (*Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a JWT", ErrInvalidToken)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	if !v.verifySignature(header.Alg, header.Kid, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, fmt.Errorf("%w: signature verification failed", ErrInvalidToken)
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+	now := v.now()
+	if claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("%w: missing exp", ErrInvalidToken)
+	}
+	if now.Add(-v.opts.Leeway).After(time.Unix(int64(*claims.ExpiresAt), 0)) {
+		return nil, fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if claims.NotBefore != nil && now.Add(v.opts.Leeway).Before(time.Unix(int64(*claims.NotBefore), 0)) {
+		return nil, fmt.Errorf("%w: not yet valid", ErrInvalidToken)
+	}
+	if v.opts.Issuer != "" && claims.Issuer != v.opts.Issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if v.opts.Audience != "" && !slices.Contains(audiences(claims.Audience), v.opts.Audience) {
+		return nil, fmt.Errorf("%w: token not issued for this resource", ErrInvalidToken)
+	}
+
+	scopes := claims.Scp
+	if claims.Scope != "" {
+		scopes = strings.Fields(claims.Scope)
+	}
+	for _, scope := range v.opts.RequiredScopes {
+		if !slices.Contains(scopes, scope) {
+			return nil, &ScopeError{Required: v.opts.RequiredScopes}
+		}
+	}
+	return &Identity{Subject: claims.Subject, Scopes: scopes}, nil
+}
+
+// verifySignature checks a signature with the keys matching the key ID and algorithm
+func (v *JWTVerifier) verifySignature(alg, kid string, signed, signature []byte) bool {
+	for _, k := range v.keys {
+		if (kid != "" && k.kid != kid) || (k.alg != "" && k.alg != alg) {
+			continue
+		}
+		if verifyWithKey(alg, k.key, signed, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyWithKey checks a signature of one of the supported algorithms; "none" and algorithms not
+// matching the key type never verify
+func verifyWithKey(alg string, key crypto.PublicKey, signed, signature []byte) bool {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512":
+		hash = crypto.SHA512
+	case "EdDSA":
+		k, ok := key.(ed25519.PublicKey)
+		return ok && ed25519.Verify(k, signed, signature)
+	default:
+		return false
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") && rsa.VerifyPKCS1v15(k, hash, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		// JWS ECDSA signatures are r and s concatenated, each the size of the curve
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(alg, "ES") || len(signature) != 2*size || (alg == "ES256") != (size == 32) {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(k, digest, r, s)
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url JSON segment of a JWT
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// audiences returns the entries of an aud claim
+func audiences(raw json.RawMessage) []string {
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		return []string{single}
+	}
+	var list []string
+	json.Unmarshal(raw, &list)
+	return list
+}
@@ -0,0 +1,147 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/tenant"
+)
+
+// rateLimitPruneInterval how often buckets of clients that stopped sending requests are dropped
+const rateLimitPruneInterval = time.Minute
+
+// RateLimitOptions settings of a RateLimiter
+type RateLimitOptions struct {
+	RequestsPerMinute float64
+	Burst             int  // Requests a client may send at once; RequestsPerMinute rounded up when zero
+	TrustProxy        bool // Identify anonymous clients by the X-Forwarded-For address the proxy appended
+	// TenantCredentials identifies clients by the QWeather credentials they send, which is only
+	// safe in multi-tenant mode: otherwise the headers are ignored and any value would be accepted
+	TenantCredentials bool
+}
+
+// RateLimiter token bucket rate limits per client
+type RateLimiter struct {
+	rate              float64 // Tokens added per second
+	burst             float64
+	trustProxy        bool
+	tenantCredentials bool
+	now               func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+// bucket tokens left to a client
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter creates a rate limiter
+func NewRateLimiter(opts RateLimitOptions) *RateLimiter {
+	burst := float64(opts.Burst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(opts.RequestsPerMinute))
+	}
+	return &RateLimiter{
+		rate:              opts.RequestsPerMinute / 60,
+		burst:             burst,
+		trustProxy:        opts.TrustProxy,
+		tenantCredentials: opts.TenantCredentials,
+		now:               time.Now,
+		buckets:           make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from a client's bucket. When the bucket is empty it returns false and how
+// long until the next token.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastPrune) >= rateLimitPruneInterval {
+		l.pruneLocked(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// pruneLocked drops full buckets, which are the same as new ones
+func (l *RateLimiter) pruneLocked(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastPrune = now
+}
+
+// ClientKey identifies the client of a request: by its authenticated subject, else by the
+// QWeather credentials it sends if TenantCredentials is set, else by its IP address
+func (l *RateLimiter) ClientKey(r *http.Request) string {
+	if identity := IdentityFromContext(r.Context()); identity != nil {
+		return "subject:" + identity.Subject
+	}
+	if l.tenantCredentials {
+		for _, header := range []string{tenant.HeaderAPIKey, tenant.HeaderJWTKeyID} {
+			if credential := strings.TrimSpace(r.Header.Get(header)); credential != "" {
+				// Credentials are hashed so they are not kept in memory as keys
+				sum := sha256.Sum256([]byte(credential))
+				return "token:" + hex.EncodeToString(sum[:8])
+			}
+		}
+	}
+	if l.trustProxy {
+		// Clients can send X-Forwarded-For themselves; only the last entry, appended by the
+		// proxy, is the address it received the request from
+		forwarded := r.Header.Values("X-Forwarded-For")
+		if len(forwarded) > 0 {
+			entries := strings.Split(forwarded[len(forwarded)-1], ",")
+			if last := strings.TrimSpace(entries[len(entries)-1]); last != "" {
+				return "ip:" + last
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// RateLimitHandler rejects requests of clients exceeding their rate with 429 Too Many Requests.
+// It must run inside AuthHandler to identify clients by subject.
+func RateLimitHandler(handler http.Handler, limiter *RateLimiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := limiter.ClientKey(r)
+		if ok, retryAfter := limiter.Allow(key); !ok {
+			slog.Debug("Rate limit exceeded", "client", key, "path", r.URL.Path)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
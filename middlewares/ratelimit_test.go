@@ -0,0 +1,99 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/tenant"
+)
+
+func TestRateLimitHandler(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(RateLimitOptions{RequestsPerMinute: 60, Burst: 2})
+	limiter.now = func() time.Time { return now }
+	h := RateLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), limiter)
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// The burst is allowed, then the client must wait for the next token
+	for i := 0; i < 2; i++ {
+		if rr := send("192.0.2.1:1000"); rr.Code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want %d", i+1, rr.Code, http.StatusOK)
+		}
+	}
+	rr := send("192.0.2.1:1001")
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "1" {
+		t.Fatalf("status = %d, Retry-After = %q; want %d, 1", rr.Code, rr.Header().Get("Retry-After"), http.StatusTooManyRequests)
+	}
+
+	// Other clients have their own budget
+	if rr := send("192.0.2.2:1000"); rr.Code != http.StatusOK {
+		t.Fatalf("other client status = %d, want %d", rr.Code, http.StatusOK)
+	}
+
+	now = now.Add(time.Second)
+	if rr := send("192.0.2.1:1000"); rr.Code != http.StatusOK {
+		t.Fatalf("status after refill = %d, want %d", rr.Code, http.StatusOK)
+	}
+
+	// Credential headers do not identify clients unless tenants send their own credentials
+	now = now.Add(2 * time.Second)
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = "192.0.2.1:1000"
+		req.Header.Set(tenant.HeaderAPIKey, fmt.Sprintf("random-%d", i))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}[i]; rr.Code != want {
+			t.Fatalf("request %d with a rotated key status = %d, want %d", i+1, rr.Code, want)
+		}
+	}
+
+	// Full buckets are dropped
+	now = now.Add(time.Hour)
+	limiter.Allow("ip:192.0.2.3")
+	if len(limiter.buckets) != 1 {
+		t.Fatalf("limiter keeps %d buckets, want 1", len(limiter.buckets))
+	}
+}
+
+func TestRateLimiter_ClientKey(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitOptions{RequestsPerMinute: 60})
+	proxied := NewRateLimiter(RateLimitOptions{RequestsPerMinute: 60, TrustProxy: true})
+	tenants := NewRateLimiter(RateLimitOptions{RequestsPerMinute: 60, TenantCredentials: true})
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.RemoteAddr = "192.0.2.1:1000"
+	// The client sent the first entry; the proxy appended the address it saw
+	req.Header.Set("X-Forwarded-For", "198.51.100.7, 203.0.113.9")
+	if key := limiter.ClientKey(req); key != "ip:192.0.2.1" {
+		t.Fatalf("key = %q, want remote address", key)
+	}
+	if key := proxied.ClientKey(req); key != "ip:203.0.113.9" {
+		t.Fatalf("key behind proxy = %q, want the address appended by the proxy", key)
+	}
+
+	req.Header.Set(tenant.HeaderAPIKey, "tenant-key")
+	if key := limiter.ClientKey(req); key != "ip:192.0.2.1" {
+		t.Fatalf("key without tenants = %q, want remote address", key)
+	}
+	key := tenants.ClientKey(req)
+	if key[:6] != "token:" || key == "token:tenant-key" {
+		t.Fatalf("key = %q, want hashed tenant key", key)
+	}
+
+	req = req.WithContext(context.WithValue(req.Context(), identityKey{}, &Identity{Subject: "ci"}))
+	if key := limiter.ClientKey(req); key != "subject:ci" {
+		t.Fatalf("key = %q, want authenticated subject", key)
+	}
+}
@@ -0,0 +1,149 @@
+// Package recorder periodically records current weather and air quality for a set of
+// locations, building a local history beyond the short window offered by QWeather.
+package recorder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// Defaults applied to a zero Config
+const (
+	DefaultInterval  = 30 * time.Minute
+	DefaultRetention = 90 * 24 * time.Hour
+)
+
+// Config locations and schedule of a Recorder
+type Config struct {
+	Locations []string      // City names or longitude,latitude coordinates
+	Interval  time.Duration // Time between two recordings
+	Retention time.Duration // How long observations are kept
+}
+
+// Recorder polls current weather and air quality for the configured locations and stores them
+type Recorder struct {
+	client *api.Client
+	store  Store
+	config Config
+
+	mu       sync.Mutex
+	resolved map[string]*api.Location // Location lookups by configured name
+}
+
+// New creates a recorder, applying defaults to unset config values
+func New(client *api.Client, store Store, config Config) *Recorder {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Retention <= 0 {
+		config.Retention = DefaultRetention
+	}
+	return &Recorder{client: client, store: store, config: config, resolved: make(map[string]*api.Location)}
+}
+
+// Run records immediately and then on every interval until the context is cancelled
+func (r *Recorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+	for {
+		if err := r.RecordOnce(ctx, time.Now()); err != nil {
+			slog.Error("Recorder: failed to record conditions", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RecordOnce records every location and applies the retention policy.
+// A failing location does not prevent the others from being recorded.
+func (r *Recorder) RecordOnce(ctx context.Context, now time.Time) error {
+	var errs []error
+	for _, name := range r.config.Locations {
+		if err := r.record(ctx, name, now); err != nil {
+			errs = append(errs, fmt.Errorf("failed to record %s: %w", name, err))
+		}
+	}
+	if err := r.store.Prune(now.Add(-r.config.Retention)); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// location resolves a configured location once and caches the result
+func (r *Recorder) location(ctx context.Context, name string) (*api.Location, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if location, ok := r.resolved[name]; ok {
+		return location, nil
+	}
+	locationData, err := r.client.GetLocationByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if locationData.Code != api.APICodeSuccess || len(locationData.Location) == 0 {
+		return nil, fmt.Errorf("no matching city found")
+	}
+	location := &locationData.Location[0]
+	r.resolved[name] = location
+	return location, nil
+}
+
+// record fetches and stores one observation. It fails only if neither weather nor air quality is available.
+func (r *Recorder) record(ctx context.Context, name string, now time.Time) error {
+	location, err := r.location(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	obs := Observation{Time: now, LocationID: location.ID, Location: location.Name}
+
+	weather, weatherErr := r.client.GetWeatherNow(ctx, location.ID)
+	if weatherErr == nil && weather.Code != api.APICodeSuccess {
+		weatherErr = fmt.Errorf("API returned error code: %s", weather.Code)
+	}
+	if weatherErr == nil {
+		current := weather.Now
+		obs.Text = current.Text
+		obs.Temp = parseValue(current.Temp)
+		obs.Humidity = parseValue(current.Humidity)
+		obs.Precip = parseValue(current.Precip)
+		obs.WindSpeed = parseValue(current.WindSpeed)
+		obs.Pressure = parseValue(current.Pressure)
+	}
+
+	lat, lon := location.Coordinates()
+	airQuality, airErr := r.client.GetAirQuality(ctx, lat, lon)
+	if airErr == nil {
+		for _, index := range airQuality.Indexes {
+			if obs.AQI == nil {
+				obs.AQI = make(map[string]int, len(airQuality.Indexes))
+			}
+			obs.AQI[index.Code] = index.Aqi
+		}
+	}
+
+	if weatherErr != nil && airErr != nil {
+		return errors.Join(weatherErr, airErr)
+	}
+	return r.store.Append(obs)
+}
+
+// parseValue parses a numeric API value, returning nil if it is missing or invalid
+func parseValue(s string) *float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
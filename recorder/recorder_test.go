@@ -0,0 +1,92 @@
+package recorder
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+func TestRecordOnce(t *testing.T) {
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			lookups++
+			if r.URL.Query().Get("location") == "Atlantis" {
+				w.Write([]byte(`{"code":"404","location":[]}`))
+				return
+			}
+			w.Write([]byte(`{"code":"200","location":[{"name":"Beijing","id":"101010100","lat":"39.90","lon":"116.41"}]}`))
+		case "/v7/weather/now":
+			w.Write([]byte(`{"code":"200","now":{"temp":"21","humidity":"40","precip":"0.2","windSpeed":"12","pressure":"1010","text":"Cloudy"}}`))
+		case "/airquality/v1/current/39.90/116.41":
+			w.Write([]byte(`{"code":"200","indexes":[{"code":"qaqi","aqi":2},{"code":"cn-mee","aqi":55}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	rec := New(api.NewClient(server.URL, "test-key"), store, Config{Locations: []string{"Beijing", "Atlantis"}})
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := rec.RecordOnce(context.Background(), now); err == nil {
+		t.Fatal("expected an error for the unknown location")
+	}
+	if err := rec.RecordOnce(context.Background(), now.Add(30*time.Minute)); err == nil {
+		t.Fatal("expected an error for the unknown location")
+	}
+	if lookups != 3 {
+		t.Errorf("lookups = %d, want the resolved location to be cached", lookups)
+	}
+
+	got, err := store.Query("101010100", now, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("recorded %d observations, want 2", len(got))
+	}
+	obs := got[0]
+	if obs.Location != "Beijing" || obs.Text != "Cloudy" || *obs.Temp != 21 || *obs.Pressure != 1010 || obs.AQI["cn-mee"] != 55 {
+		t.Fatalf("observation = %+v, want the upstream values", obs)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	observations := []Observation{
+		{Time: base, Temp: value(10), Precip: value(0.5), AQI: map[string]int{"qaqi": 2}},
+		{Time: base.Add(30 * time.Minute), Temp: value(14), Precip: value(1.0), AQI: map[string]int{"qaqi": 4}},
+		{Time: base.Add(time.Hour), Temp: value(12), Precip: value(0)},
+		{Time: base.Add(2 * time.Hour), Precip: value(2.0)},
+	}
+
+	s := Summarize(observations, "qaqi")
+	if s.Samples != 4 || !s.First.Equal(base) || !s.Last.Equal(base.Add(2*time.Hour)) {
+		t.Fatalf("Summary = %+v, want 4 samples over two hours", s)
+	}
+	if s.Temp.Count != 3 || s.Temp.Min != 10 || s.Temp.Max != 14 || s.Temp.Mean != 12 {
+		t.Errorf("Temp = %+v, want min 10, max 14, mean 12", s.Temp)
+	}
+	// Highest past-hour amount per clock hour: 1.0 + 0 + 2.0
+	if math.Abs(s.PrecipTotal-3.0) > 1e-9 || s.PrecipHours != 2 {
+		t.Errorf("precipitation = %.2fmm over %d hours, want 3.00mm over 2", s.PrecipTotal, s.PrecipHours)
+	}
+	if s.AQI.Count != 2 || s.AQI.Mean != 3 {
+		t.Errorf("AQI = %+v, want mean 3 over 2 samples", s.AQI)
+	}
+	if s.Humidity.Count != 0 {
+		t.Errorf("Humidity = %+v, want no data", s.Humidity)
+	}
+}
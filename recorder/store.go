@@ -0,0 +1,176 @@
+package recorder
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Observation the conditions recorded for a location at one point in time.
+// Values missing from the upstream response are nil.
+type Observation struct {
+	Time       time.Time      `json:"time"`
+	LocationID string         `json:"locationId"`
+	Location   string         `json:"location"`
+	Text       string         `json:"text,omitempty"`
+	Temp       *float64       `json:"temp,omitempty"`
+	Humidity   *float64       `json:"humidity,omitempty"`
+	Precip     *float64       `json:"precip,omitempty"` // Precipitation over the past hour in mm
+	WindSpeed  *float64       `json:"windSpeed,omitempty"`
+	Pressure   *float64       `json:"pressure,omitempty"`
+	AQI        map[string]int `json:"aqi,omitempty"` // Air quality index by standard code
+}
+
+// Store persists recorded observations. Implementations must be safe for concurrent use.
+type Store interface {
+	// Append adds an observation
+	Append(obs Observation) error
+	// Query returns the observations of a location recorded in [from, to), ordered by time
+	Query(locationID string, from, to time.Time) ([]Observation, error)
+	// Prune deletes observations recorded before the given time
+	Prune(before time.Time) error
+}
+
+// dayLayout names the per-day files of a FileStore
+const dayLayout = "2006-01-02"
+
+// FileStore keeps observations as JSON lines, one directory per location and one file per UTC day.
+// Retention is applied by whole days.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates a file store rooted at dir, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recorder directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// locationDir returns the directory holding the observations of a location
+func (f *FileStore) locationDir(locationID string) string {
+	// Location IDs are numeric or coordinates; keep them from escaping the store directory
+	return filepath.Join(f.dir, strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(locationID))
+}
+
+// Append writes the observation to the file of its day
+func (f *FileStore) Append(obs Observation) error {
+	data, err := json.Marshal(obs)
+	if err != nil {
+		return fmt.Errorf("failed to encode observation: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dir := f.locationDir(obs.LocationID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create recorder directory: %w", err)
+	}
+	file, err := os.OpenFile(filepath.Join(dir, obs.Time.UTC().Format(dayLayout)+".jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open observation file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write observation: %w", err)
+	}
+	return nil
+}
+
+// Query reads the day files overlapping the range and returns the matching observations
+func (f *FileStore) Query(locationID string, from, to time.Time) ([]Observation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := os.ReadDir(f.locationDir(locationID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list observations: %w", err)
+	}
+
+	firstDay := from.UTC().Format(dayLayout)
+	lastDay := to.UTC().Format(dayLayout)
+	var observations []Observation
+	for _, entry := range entries {
+		day, ok := strings.CutSuffix(entry.Name(), ".jsonl")
+		if !ok || day < firstDay || day > lastDay {
+			continue
+		}
+		dayObservations, err := readObservations(filepath.Join(f.locationDir(locationID), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, obs := range dayObservations {
+			if !obs.Time.Before(from) && obs.Time.Before(to) {
+				observations = append(observations, obs)
+			}
+		}
+	}
+
+	sort.SliceStable(observations, func(i, j int) bool { return observations[i].Time.Before(observations[j].Time) })
+	return observations, nil
+}
+
+// readObservations decodes a day file, skipping lines that cannot be decoded such as a partially written last line
+func readObservations(path string) ([]Observation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read observations: %w", err)
+	}
+	defer file.Close()
+
+	var observations []Observation
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var obs Observation
+		if err := json.Unmarshal(scanner.Bytes(), &obs); err == nil {
+			observations = append(observations, obs)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read observations: %w", err)
+	}
+	return observations, nil
+}
+
+// Prune deletes the day files of all locations that end before the given time
+func (f *FileStore) Prune(before time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cutoff := before.UTC().Format(dayLayout)
+	locations, err := os.ReadDir(f.dir)
+	if err != nil {
+		return fmt.Errorf("failed to list recorded locations: %w", err)
+	}
+	for _, location := range locations {
+		if !location.IsDir() {
+			continue
+		}
+		dir := filepath.Join(f.dir, location.Name())
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to list observations: %w", err)
+		}
+		for _, entry := range entries {
+			day, ok := strings.CutSuffix(entry.Name(), ".jsonl")
+			if ok && day < cutoff {
+				if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+					return fmt.Errorf("failed to delete expired observations: %w", err)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package recorder
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+
+	base := time.Date(2024, 5, 1, 22, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		temp := float64(i)
+		obs := Observation{Time: base.Add(time.Duration(i) * time.Hour), LocationID: "101010100", Location: "Beijing", Temp: &temp}
+		if err := store.Append(obs); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	store.Append(Observation{Time: base, LocationID: "101020100", Location: "Shanghai"})
+
+	// The range spans the two day files and excludes its end
+	got, err := store.Query("101010100", base.Add(time.Hour), base.Add(4*time.Hour))
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(got) != 3 || *got[0].Temp != 1 || *got[2].Temp != 3 {
+		t.Fatalf("Query returned %+v, want observations 1 to 3", got)
+	}
+
+	if err := store.Prune(time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	got, _ = store.Query("101010100", base, base.Add(24*time.Hour))
+	if len(got) != 4 || !got[0].Time.Equal(base.Add(2*time.Hour)) {
+		t.Fatalf("after Prune Query returned %d observations starting %v, want the 4 of 2024-05-02", len(got), got[0].Time)
+	}
+	if got, _ := store.Query("101020100", base, base.Add(time.Hour)); len(got) != 0 {
+		t.Fatalf("Prune kept %d expired observations of another location", len(got))
+	}
+
+	if got, err := store.Query("unknown", base, base.Add(time.Hour)); err != nil || len(got) != 0 {
+		t.Fatalf("Query for an unrecorded location = %v, %v; want nothing", got, err)
+	}
+}
@@ -0,0 +1,85 @@
+package recorder
+
+import (
+	"math"
+	"time"
+)
+
+// Stat minimum, maximum and mean of a recorded quantity
+type Stat struct {
+	Count int
+	Min   float64
+	Max   float64
+	Mean  float64
+}
+
+// add includes a value in the statistic, keeping a running mean
+func (s *Stat) add(v float64) {
+	if s.Count == 0 {
+		s.Min, s.Max = v, v
+	}
+	s.Count++
+	s.Min = math.Min(s.Min, v)
+	s.Max = math.Max(s.Max, v)
+	s.Mean += (v - s.Mean) / float64(s.Count)
+}
+
+// Summary aggregates of the observations recorded over a period
+type Summary struct {
+	Samples     int
+	First, Last time.Time
+	Temp        Stat
+	Humidity    Stat
+	WindSpeed   Stat
+	Pressure    Stat
+	AQI         Stat
+	PrecipTotal float64 // Total precipitation in mm over the hours with at least one observation
+	PrecipHours int     // Hours with precipitation
+}
+
+// Summarize aggregates observations. AQI statistics use the given standard code.
+//
+// Each observation reports precipitation over the past hour, so the total is the sum over
+// clock hours of the highest value observed in that hour; hours without observations
+// are not counted.
+func Summarize(observations []Observation, aqiStandard string) Summary {
+	var s Summary
+	precipByHour := make(map[int64]float64)
+	for _, obs := range observations {
+		if s.Samples == 0 || obs.Time.Before(s.First) {
+			s.First = obs.Time
+		}
+		if s.Samples == 0 || obs.Time.After(s.Last) {
+			s.Last = obs.Time
+		}
+		s.Samples++
+
+		if obs.Temp != nil {
+			s.Temp.add(*obs.Temp)
+		}
+		if obs.Humidity != nil {
+			s.Humidity.add(*obs.Humidity)
+		}
+		if obs.WindSpeed != nil {
+			s.WindSpeed.add(*obs.WindSpeed)
+		}
+		if obs.Pressure != nil {
+			s.Pressure.add(*obs.Pressure)
+		}
+		if aqi, ok := obs.AQI[aqiStandard]; ok {
+			s.AQI.add(float64(aqi))
+		}
+		if obs.Precip != nil {
+			hour := obs.Time.Truncate(time.Hour).Unix()
+			precipByHour[hour] = math.Max(precipByHour[hour], *obs.Precip)
+		}
+	}
+
+	for _, precip := range precipByHour {
+		s.PrecipTotal += precip
+		if precip > 0 {
+			s.PrecipHours++
+		}
+	}
+	return s
+}
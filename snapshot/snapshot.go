@@ -0,0 +1,206 @@
+// Package snapshot keeps point-in-time copies of the forecasts for a location,
+// so that later forecasts can be compared with earlier ones.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// Snapshot the forecasts of one location as they were at a point in time
+type Snapshot struct {
+	LocationID string                    `json:"locationId"`
+	Location   string                    `json:"location"`
+	TakenAt    time.Time                 `json:"takenAt"`
+	Daily      *api.WeatherDailyResponse `json:"daily,omitempty"`
+	Hourly     *api.HourlyResponse       `json:"hourly,omitempty"`
+}
+
+// Store persists snapshots. Implementations must be safe for concurrent use.
+type Store interface {
+	// Save stores a snapshot
+	Save(s *Snapshot) error
+	// Latest returns the most recent snapshot of the location taken at or before the given time,
+	// or nil if there is none
+	Latest(locationID string, before time.Time) (*Snapshot, error)
+}
+
+// DefaultMemoryLimit number of snapshots kept per location by a MemoryStore created with a non-positive limit
+const DefaultMemoryLimit = 48
+
+// MemoryStore keeps snapshots in memory, dropping the oldest once a location has reached its limit
+type MemoryStore struct {
+	mu        sync.RWMutex
+	limit     int
+	snapshots map[string][]*Snapshot // Ordered by TakenAt
+}
+
+// NewMemoryStore creates an in-memory store keeping at most limit snapshots per location
+func NewMemoryStore(limit int) *MemoryStore {
+	if limit <= 0 {
+		limit = DefaultMemoryLimit
+	}
+	return &MemoryStore{limit: limit, snapshots: make(map[string][]*Snapshot)}
+}
+
+// Save stores a snapshot
+func (m *MemoryStore) Save(s *Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := m.snapshots[s.LocationID]
+	i := sort.Search(len(list), func(i int) bool { return list[i].TakenAt.After(s.TakenAt) })
+	list = append(list, nil)
+	copy(list[i+1:], list[i:])
+	list[i] = s
+	if len(list) > m.limit {
+		list = list[len(list)-m.limit:]
+	}
+	m.snapshots[s.LocationID] = list
+	return nil
+}
+
+// Latest returns the most recent snapshot taken at or before the given time
+func (m *MemoryStore) Latest(locationID string, before time.Time) (*Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := m.snapshots[locationID]
+	i := sort.Search(len(list), func(i int) bool { return list[i].TakenAt.After(before) })
+	if i == 0 {
+		return nil, nil
+	}
+	return list[i-1], nil
+}
+
+// DefaultFileLimit number of snapshots kept per location by a FileStore created with a non-positive limit
+const DefaultFileLimit = 48
+
+// FileStore keeps snapshots as JSON files, one directory per location and one file per snapshot,
+// deleting the oldest files once a location has reached its limit
+type FileStore struct {
+	dir   string
+	limit int
+	mu    sync.Mutex
+}
+
+// NewFileStore creates a file store rooted at dir keeping at most limit snapshots per location,
+// creating the directory if needed
+func NewFileStore(dir string, limit int) (*FileStore, error) {
+	if limit <= 0 {
+		limit = DefaultFileLimit
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	return &FileStore{dir: dir, limit: limit}, nil
+}
+
+// locationDir returns the directory holding the snapshots of a location
+func (f *FileStore) locationDir(locationID string) string {
+	// Location IDs are numeric or coordinates; keep them from escaping the store directory
+	return filepath.Join(f.dir, strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(locationID))
+}
+
+// Save writes the snapshot to a new file, named after the time it was taken, and deletes the
+// oldest snapshots beyond the limit
+func (f *FileStore) Save(s *Snapshot) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dir := f.locationDir(s.LocationID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial snapshot
+	name := filepath.Join(dir, strconv.FormatInt(s.TakenAt.UnixNano(), 10)+".json")
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	times, err := f.snapshotTimes(s.LocationID)
+	if err != nil {
+		return err
+	}
+	for _, takenAt := range times[:max(0, len(times)-f.limit)] {
+		if err := os.Remove(filepath.Join(dir, strconv.FormatInt(takenAt, 10)+".json")); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete old snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+// Latest reads the most recent snapshot taken at or before the given time
+func (f *FileStore) Latest(locationID string, before time.Time) (*Snapshot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	times, err := f.snapshotTimes(locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := before.UnixNano()
+	i := sort.Search(len(times), func(i int) bool { return times[i] > limit })
+	if i == 0 {
+		return nil, nil
+	}
+	return f.read(locationID, times[i-1])
+}
+
+// snapshotTimes lists the times of the stored snapshots of a location in ascending order.
+// Callers hold f.mu.
+func (f *FileStore) snapshotTimes(locationID string) ([]int64, error) {
+	entries, err := os.ReadDir(f.locationDir(locationID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	times := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if t, err := strconv.ParseInt(name, 10, 64); err == nil {
+			times = append(times, t)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times, nil
+}
+
+// read loads a single snapshot file
+func (f *FileStore) read(locationID string, takenAt int64) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(f.locationDir(locationID), strconv.FormatInt(takenAt, 10)+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	return &s, nil
+}
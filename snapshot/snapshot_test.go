@@ -0,0 +1,94 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// testStores returns one store of each implementation
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+	fileStore, err := NewFileStore(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	return map[string]Store{"memory": NewMemoryStore(0), "file": fileStore}
+}
+
+func TestStoreLatest(t *testing.T) {
+	base := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			got, err := store.Latest("101010100", base)
+			if err != nil || got != nil {
+				t.Fatalf("Latest on empty store = %v, %v; want nil, nil", got, err)
+			}
+
+			// Saved out of order on purpose
+			for _, hours := range []int{2, 0, 1} {
+				s := &Snapshot{LocationID: "101010100", Location: "Beijing", TakenAt: base.Add(time.Duration(hours) * time.Hour)}
+				s.Daily = &api.WeatherDailyResponse{Code: "200", UpdateTime: s.TakenAt.Format(time.RFC3339)}
+				if err := store.Save(s); err != nil {
+					t.Fatalf("Save failed: %v", err)
+				}
+			}
+
+			tests := []struct {
+				before time.Time
+				want   time.Time
+				found  bool
+			}{
+				{base.Add(-time.Minute), time.Time{}, false},
+				{base, base, true},
+				{base.Add(90 * time.Minute), base.Add(time.Hour), true},
+				{base.Add(24 * time.Hour), base.Add(2 * time.Hour), true},
+			}
+			for _, tt := range tests {
+				got, err := store.Latest("101010100", tt.before)
+				if err != nil {
+					t.Fatalf("Latest failed: %v", err)
+				}
+				if (got != nil) != tt.found {
+					t.Fatalf("Latest(%v) = %v, want found=%v", tt.before, got, tt.found)
+				}
+				if got != nil && (!got.TakenAt.Equal(tt.want) || got.Daily.UpdateTime != tt.want.Format(time.RFC3339)) {
+					t.Errorf("Latest(%v) took at %v, want %v", tt.before, got.TakenAt, tt.want)
+				}
+			}
+
+			if got, _ := store.Latest("other", base.Add(24*time.Hour)); got != nil {
+				t.Errorf("Latest for another location = %v, want nil", got)
+			}
+		})
+	}
+}
+
+func TestStoreLimit(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir(), 2)
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	for name, store := range map[string]Store{"memory": NewMemoryStore(2), "file": fileStore} {
+		t.Run(name, func(t *testing.T) {
+			base := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+			for i := 0; i < 3; i++ {
+				if err := store.Save(&Snapshot{LocationID: "101010100", TakenAt: base.Add(time.Duration(i) * time.Hour)}); err != nil {
+					t.Fatalf("Save failed: %v", err)
+				}
+			}
+			if got, _ := store.Latest("101010100", base); got != nil {
+				t.Errorf("oldest snapshot should have been dropped, got %v", got.TakenAt)
+			}
+			if got, _ := store.Latest("101010100", base.Add(time.Hour)); got == nil {
+				t.Error("second snapshot should be kept")
+			}
+		})
+	}
+
+	// Only the kept snapshots remain on disk
+	if times, _ := fileStore.snapshotTimes("101010100"); len(times) != 2 {
+		t.Errorf("file store keeps %d snapshot files, want 2", len(times))
+	}
+}
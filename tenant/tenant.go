@@ -0,0 +1,357 @@
+// Package tenant serves multi-tenant HTTP deployments in which every tenant brings its own
+// QWeather credentials in request headers. Credentials are checked upstream before a tenant is
+// admitted; each tenant then gets an isolated API client and MCP server, reused by all of its
+// sessions and dropped once idle. Credentials are never logged; tenants are identified in logs
+// by a fingerprint of their credentials.
+package tenant
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// Request headers carrying tenant credentials: either an API key, or the material to sign JWTs
+const (
+	HeaderAPIKey        = "X-QWeather-Api-Key"
+	HeaderJWTKeyID      = "X-QWeather-Jwt-Key-Id"
+	HeaderJWTProjectID  = "X-QWeather-Jwt-Project-Id"
+	HeaderJWTPrivateKey = "X-QWeather-Jwt-Private-Key" // Base64 encoded PEM private key
+)
+
+// Defaults applied to unset Options values
+const (
+	DefaultIdleTimeout         = 30 * time.Minute
+	DefaultMaxTenants          = 1000
+	DefaultMaxTenantsPerClient = 5
+)
+
+// evictInterval time between two checks for idle tenants
+const evictInterval = time.Minute
+
+// rejectTTL how long credentials that failed the upstream check are refused without checking again
+const rejectTTL = time.Minute
+
+// checkLocation location looked up to check credentials; a geo lookup of an ID is the cheapest request
+const checkLocation = "101010100"
+
+// Credentials QWeather credentials of a tenant
+type Credentials struct {
+	APIKey     string
+	KeyID      string
+	ProjectID  string
+	PrivateKey []byte // PEM encoded Ed25519 private key
+}
+
+// CredentialsFromRequest reads the credentials of a request. It returns nil without error when
+// the request carries none.
+func CredentialsFromRequest(r *http.Request) (*Credentials, error) {
+	creds := &Credentials{
+		APIKey:    strings.TrimSpace(r.Header.Get(HeaderAPIKey)),
+		KeyID:     strings.TrimSpace(r.Header.Get(HeaderJWTKeyID)),
+		ProjectID: strings.TrimSpace(r.Header.Get(HeaderJWTProjectID)),
+	}
+	if encoded := strings.TrimSpace(r.Header.Get(HeaderJWTPrivateKey)); encoded != "" {
+		privateKey, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be base64 encoded", HeaderJWTPrivateKey)
+		}
+		creds.PrivateKey = privateKey
+	}
+
+	jwt := creds.KeyID != "" || creds.ProjectID != "" || len(creds.PrivateKey) > 0
+	switch {
+	case creds.APIKey == "" && !jwt:
+		return nil, nil
+	case creds.APIKey != "" && jwt:
+		return nil, fmt.Errorf("send either %s or JWT credentials, not both", HeaderAPIKey)
+	case jwt && (creds.KeyID == "" || creds.ProjectID == "" || len(creds.PrivateKey) == 0):
+		return nil, fmt.Errorf("JWT credentials need %s, %s and %s", HeaderJWTKeyID, HeaderJWTProjectID, HeaderJWTPrivateKey)
+	}
+	return creds, nil
+}
+
+// fingerprint identifies the tenant owning the credentials without revealing them
+func (c *Credentials) fingerprint() string {
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(c.APIKey), []byte(c.KeyID), []byte(c.ProjectID), c.PrivateKey} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Options settings of the tenant clients and the pool
+type Options struct {
+	BaseURL     string
+	Timeout     time.Duration // Timeout of API requests
+	Lang        string
+	IdleTimeout time.Duration // Tenants without sessions are dropped after this long
+	MaxTenants  int
+
+	// MaxTenantsPerClient tenants a single client may create, so that one client cannot fill the pool
+	MaxTenantsPerClient int
+	// ClientKey identifies the client of a request; defaults to its IP address
+	ClientKey func(*http.Request) string
+}
+
+// NewServerFunc builds the MCP server of a tenant around its API client. The context is
+// cancelled when the tenant is dropped, stopping the server's background work.
+type NewServerFunc func(ctx context.Context, client *api.Client) *mcp.Server
+
+// tenant client and server of one set of credentials
+type tenant struct {
+	id       string // Short fingerprint, safe to log
+	client   *api.Client
+	server   *mcp.Server
+	cancel   context.CancelFunc
+	owner    string // Client that created the tenant
+	lastUsed time.Time
+}
+
+// Pool per-tenant clients and servers, by credential fingerprint
+type Pool struct {
+	opts      Options
+	newServer NewServerFunc
+
+	mu       sync.Mutex
+	tenants  map[string]*tenant
+	rejected map[string]time.Time // Expiry of failed credential checks, by fingerprint
+}
+
+// NewPool creates an empty pool
+func NewPool(newServer NewServerFunc, opts Options) *Pool {
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = DefaultIdleTimeout
+	}
+	if opts.MaxTenants <= 0 {
+		opts.MaxTenants = DefaultMaxTenants
+	}
+	if opts.MaxTenantsPerClient <= 0 {
+		opts.MaxTenantsPerClient = DefaultMaxTenantsPerClient
+	}
+	if opts.ClientKey == nil {
+		opts.ClientKey = RemoteIP
+	}
+	return &Pool{
+		opts:      opts,
+		newServer: newServer,
+		tenants:   make(map[string]*tenant),
+		rejected:  make(map[string]time.Time),
+	}
+}
+
+// RemoteIP identifies the client of a request by its IP address
+func RemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Server returns the server of the tenant whose credentials the request carries, creating it on
+// first use once its credentials pass an upstream check. It returns nil, which the MCP HTTP
+// handlers answer with 400 Bad Request, when the request has no valid credentials, or the pool or
+// the client's share of it is full.
+func (p *Pool) Server(r *http.Request) *mcp.Server {
+	creds, err := CredentialsFromRequest(r)
+	if creds == nil {
+		if err == nil {
+			err = fmt.Errorf("missing %s or JWT credential headers", HeaderAPIKey)
+		}
+		slog.Warn("Rejected session without valid tenant credentials", "remote", r.RemoteAddr, "error", err)
+		return nil
+	}
+	t, err := p.tenant(r.Context(), creds, p.opts.ClientKey(r), time.Now())
+	if err != nil {
+		slog.Warn("Rejected tenant session", "remote", r.RemoteAddr, "error", err)
+		return nil
+	}
+	return t.server
+}
+
+// tenant returns the tenant of a set of credentials, creating it for the owner if needed. New
+// credentials are checked upstream without holding the lock; credentials failing the check are
+// refused for rejectTTL.
+func (p *Pool) tenant(ctx context.Context, creds *Credentials, owner string, now time.Time) (*tenant, error) {
+	fingerprint := creds.fingerprint()
+
+	p.mu.Lock()
+	t, err := p.lookupLocked(fingerprint, owner, now)
+	p.mu.Unlock()
+	if t != nil || err != nil {
+		return t, err
+	}
+
+	client, err := p.newClient(creds)
+	if err == nil {
+		err = checkCredentials(ctx, client)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		if ctx.Err() == nil {
+			p.rejected[fingerprint] = now.Add(rejectTTL)
+		}
+		return nil, fmt.Errorf("tenant %s failed the credential check: %w", fingerprint[:12], err)
+	}
+	// Another session may have created the tenant, or filled the pool, during the check
+	if t, err := p.lookupLocked(fingerprint, owner, now); t != nil || err != nil {
+		return t, err
+	}
+	tenantCtx, cancel := context.WithCancel(context.Background())
+	t = &tenant{
+		id:       fingerprint[:12],
+		client:   client,
+		server:   p.newServer(tenantCtx, client),
+		cancel:   cancel,
+		owner:    owner,
+		lastUsed: now,
+	}
+	p.tenants[fingerprint] = t
+	slog.Info("Created tenant", "tenant", t.id, "tenants", len(p.tenants))
+	return t, nil
+}
+
+// lookupLocked returns the existing tenant of a fingerprint, or an error when a new tenant cannot
+// be admitted for the owner; both are nil when it can. Callers hold p.mu.
+func (p *Pool) lookupLocked(fingerprint, owner string, now time.Time) (*tenant, error) {
+	if t, ok := p.tenants[fingerprint]; ok {
+		t.lastUsed = now
+		return t, nil
+	}
+	if expires, ok := p.rejected[fingerprint]; ok {
+		if now.Before(expires) {
+			return nil, fmt.Errorf("tenant %s recently failed the credential check", fingerprint[:12])
+		}
+		delete(p.rejected, fingerprint)
+	}
+	if len(p.tenants) >= p.opts.MaxTenants || p.ownedLocked(owner) >= p.opts.MaxTenantsPerClient {
+		p.evictIdleLocked(now)
+	}
+	if len(p.tenants) >= p.opts.MaxTenants {
+		return nil, fmt.Errorf("tenant limit of %d reached", p.opts.MaxTenants)
+	}
+	if p.ownedLocked(owner) >= p.opts.MaxTenantsPerClient {
+		return nil, fmt.Errorf("limit of %d tenants per client reached", p.opts.MaxTenantsPerClient)
+	}
+	return nil, nil
+}
+
+// ownedLocked returns the number of tenants created by a client. Callers hold p.mu.
+func (p *Pool) ownedLocked(owner string) int {
+	n := 0
+	for _, t := range p.tenants {
+		if t.owner == owner {
+			n++
+		}
+	}
+	return n
+}
+
+// checkCredentials looks a location up with a tenant's client to check that its credentials
+// are accepted upstream
+func checkCredentials(ctx context.Context, client *api.Client) error {
+	data, err := client.MakeRequestWithContext(ctx, "/geo/v2/city/lookup", map[string]string{
+		"location": checkLocation,
+		"number":   "1",
+	})
+	if err != nil {
+		return err
+	}
+	var response api.LocationResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("failed to parse location data: %w", err)
+	}
+	if response.Code != api.APICodeSuccess {
+		return fmt.Errorf("API returned error code: %s", response.Code)
+	}
+	return nil
+}
+
+// newClient creates an API client authenticating with a tenant's credentials
+func (p *Pool) newClient(creds *Credentials) (*api.Client, error) {
+	client := api.NewClient(p.opts.BaseURL, creds.APIKey)
+	if p.opts.Timeout > 0 {
+		client.HTTPClient.Timeout = p.opts.Timeout
+	}
+	client.Lang = p.opts.Lang
+	client.SetLogLevel(api.LogLevelDebug)
+	if creds.APIKey == "" {
+		signer, err := api.NewJWTSigner(creds.KeyID, creds.ProjectID, creds.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		client.JWT = signer
+	}
+	return client, nil
+}
+
+// Run drops idle tenants periodically until the context is cancelled
+func (p *Pool) Run(ctx context.Context) {
+	ticker := time.NewTicker(evictInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			p.mu.Lock()
+			p.evictIdleLocked(now)
+			for fingerprint, expires := range p.rejected {
+				if !now.Before(expires) {
+					delete(p.rejected, fingerprint)
+				}
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// Len returns the number of tenants
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.tenants)
+}
+
+// evictIdleLocked drops the tenants that have had no session for the idle timeout.
+// Callers hold p.mu.
+func (p *Pool) evictIdleLocked(now time.Time) {
+	for fingerprint, t := range p.tenants {
+		if hasSessions(t.server) {
+			// Idle time counts from the end of the last session
+			t.lastUsed = now
+			continue
+		}
+		if now.Sub(t.lastUsed) < p.opts.IdleTimeout {
+			continue
+		}
+		t.cancel()
+		t.client.HTTPClient.CloseIdleConnections()
+		delete(p.tenants, fingerprint)
+		slog.Info("Dropped idle tenant", "tenant", t.id, "tenants", len(p.tenants))
+	}
+}
+
+// hasSessions reports whether a server has connected sessions
+func hasSessions(server *mcp.Server) bool {
+	for range server.Sessions() {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package tenant
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// request returns a request carrying the given headers
+func request(headers map[string]string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	return r
+}
+
+func TestCredentialsFromRequest(t *testing.T) {
+	_, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(privateKey)
+	encodedKey := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool // Credentials returned
+		wantErr bool
+	}{
+		{"none", nil, false, false},
+		{"api key", map[string]string{HeaderAPIKey: "key-a"}, true, false},
+		{"jwt", map[string]string{HeaderJWTKeyID: "kid", HeaderJWTProjectID: "pid", HeaderJWTPrivateKey: encodedKey}, true, false},
+		{"incomplete jwt", map[string]string{HeaderJWTKeyID: "kid"}, false, true},
+		{"key and jwt", map[string]string{HeaderAPIKey: "key-a", HeaderJWTKeyID: "kid"}, false, true},
+		{"private key not base64", map[string]string{HeaderJWTKeyID: "kid", HeaderJWTProjectID: "pid", HeaderJWTPrivateKey: "-----BEGIN"}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := CredentialsFromRequest(request(tt.headers))
+			if (err != nil) != tt.wantErr || (creds != nil) != tt.want {
+				t.Fatalf("CredentialsFromRequest = %+v, %v", creds, err)
+			}
+		})
+	}
+}
+
+func TestPool(t *testing.T) {
+	var upstreamKeys []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/geo/v2/city/lookup" {
+			upstreamKeys = append(upstreamKeys, r.Header.Get("X-QW-Api-Key"))
+		}
+		w.Write([]byte(`{"code":"200"}`))
+	}))
+	defer upstream.Close()
+
+	created := map[*mcp.Server]*api.Client{}
+	var cancelled []context.Context
+	pool := NewPool(func(ctx context.Context, client *api.Client) *mcp.Server {
+		server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+		created[server] = client
+		cancelled = append(cancelled, ctx)
+		return server
+	}, Options{BaseURL: upstream.URL, IdleTimeout: time.Minute, MaxTenants: 2})
+
+	a := pool.Server(request(map[string]string{HeaderAPIKey: "key-a"}))
+	b := pool.Server(request(map[string]string{HeaderAPIKey: "key-b"}))
+	if a == nil || b == nil || a == b {
+		t.Fatalf("tenants share a server or were rejected: %p, %p", a, b)
+	}
+	if again := pool.Server(request(map[string]string{HeaderAPIKey: "key-a"})); again != a {
+		t.Fatal("tenant server was not reused")
+	}
+	if pool.Server(request(nil)) != nil {
+		t.Fatal("request without credentials was given a server")
+	}
+	if pool.Server(request(map[string]string{HeaderAPIKey: "key-c"})) != nil {
+		t.Fatal("tenant was created beyond the limit")
+	}
+
+	// Each tenant's requests use its own key
+	created[a].MakeRequest("/v7/weather/now", nil)
+	created[b].MakeRequest("/v7/weather/now", nil)
+	if len(upstreamKeys) != 2 || upstreamKeys[0] != "key-a" || upstreamKeys[1] != "key-b" {
+		t.Fatalf("upstream keys = %q", upstreamKeys)
+	}
+
+	// Idle tenants are dropped and their background work stopped
+	pool.mu.Lock()
+	pool.evictIdleLocked(time.Now().Add(30 * time.Second))
+	pool.mu.Unlock()
+	if pool.Len() != 2 {
+		t.Fatalf("pool has %d tenants before the idle timeout, want 2", pool.Len())
+	}
+	pool.mu.Lock()
+	pool.evictIdleLocked(time.Now().Add(2 * time.Minute))
+	pool.mu.Unlock()
+	if pool.Len() != 0 {
+		t.Fatalf("pool has %d tenants after the idle timeout, want 0", pool.Len())
+	}
+	for _, ctx := range cancelled {
+		if ctx.Err() == nil {
+			t.Fatal("context of a dropped tenant was not cancelled")
+		}
+	}
+	if pool.Server(request(map[string]string{HeaderAPIKey: "key-c"})) == nil {
+		t.Fatal("tenant rejected after idle tenants were dropped")
+	}
+}
+
+func TestPool_Admission(t *testing.T) {
+	var checks atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checks.Add(1)
+		if r.Header.Get("X-QW-Api-Key") == "invalid" {
+			w.Write([]byte(`{"code":"401"}`))
+			return
+		}
+		w.Write([]byte(`{"code":"200"}`))
+	}))
+	defer upstream.Close()
+
+	pool := NewPool(func(ctx context.Context, client *api.Client) *mcp.Server {
+		return mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	}, Options{BaseURL: upstream.URL, MaxTenantsPerClient: 2})
+	from := func(remote, key string) *http.Request {
+		r := request(map[string]string{HeaderAPIKey: key})
+		r.RemoteAddr = remote
+		return r
+	}
+
+	// Credentials rejected upstream create no tenant, and are not checked again for a while
+	for range 2 {
+		if pool.Server(from("192.0.2.1:1234", "invalid")) != nil {
+			t.Fatal("tenant was created with credentials rejected upstream")
+		}
+	}
+	if pool.Len() != 0 || checks.Load() != 1 {
+		t.Fatalf("pool has %d tenants after %d checks, want none after 1", pool.Len(), checks.Load())
+	}
+
+	// Accepted credentials are checked once
+	a := pool.Server(from("192.0.2.1:1234", "key-a"))
+	if a == nil || pool.Server(from("192.0.2.1:5678", "key-a")) != a || checks.Load() != 2 {
+		t.Fatalf("tenant not created or reused after %d checks", checks.Load())
+	}
+
+	// A client cannot create more than its share of tenants, but can use tenants created by others
+	if pool.Server(from("192.0.2.1:1234", "key-b")) == nil {
+		t.Fatal("second tenant of a client was rejected")
+	}
+	if pool.Server(from("192.0.2.1:1234", "key-c")) != nil {
+		t.Fatal("tenant was created beyond the per-client limit")
+	}
+	if pool.Server(from("192.0.2.2:1234", "key-c")) == nil || pool.Server(from("192.0.2.2:1234", "key-a")) != a {
+		t.Fatal("another client was limited by the first client's tenants")
+	}
+}
@@ -0,0 +1,296 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// Defaults for the find-activity-windows tool
+const (
+	defaultActivityResults = 5
+	maxActivityResults     = 20
+)
+
+// ActivityWindowsInput input parameters for find-activity-windows tool
+type ActivityWindowsInput struct {
+	CityName         string   `json:"cityName" jsonschema:"Name of the city to search activity windows for"`
+	Hours            string   `json:"hours,omitempty" jsonschema:"Search horizon. Valid values: 24h, 72h or 168h (7 days). Defaults to 168h."`
+	MinTemp          *float64 `json:"minTemp,omitempty" jsonschema:"Minimum acceptable temperature in °C"`
+	MaxTemp          *float64 `json:"maxTemp,omitempty" jsonschema:"Maximum acceptable temperature in °C"`
+	NoPrecipitation  bool     `json:"noPrecipitation,omitempty" jsonschema:"Require hours without precipitation"`
+	MaxWindScale     *int     `json:"maxWindScale,omitempty" jsonschema:"Maximum acceptable wind force on the Beaufort scale (e.g. 4)"`
+	MaxAQI           *int     `json:"maxAqi,omitempty" jsonschema:"Maximum acceptable air quality index. Air quality is only forecast for the next 24 hours; later hours are accepted and marked as unknown."`
+	MinDurationHours int      `json:"minDurationHours,omitempty" jsonschema:"Minimum length of a window in hours. Defaults to 1."`
+	MaxResults       int      `json:"maxResults,omitempty" jsonschema:"Maximum number of windows to return (1 to 20). Defaults to 5."`
+}
+
+// ActivityWindowsOutput output structure for find-activity-windows tool
+type ActivityWindowsOutput struct {
+	WindowsInfo string `json:"windowsInfo" jsonschema:"Ranked time windows that satisfy all constraints, with the reasons each window qualifies"`
+}
+
+// activityHour an hourly forecast entry evaluated against the activity constraints
+type activityHour struct {
+	start    time.Time
+	temp     float64
+	precip   float64
+	wind     int
+	aqi      int
+	aqiKnown bool
+	text     string
+}
+
+// activityWindow a run of consecutive hours that satisfy all constraints
+type activityWindow struct {
+	hours []activityHour
+}
+
+func (w activityWindow) start() time.Time { return w.hours[0].start }
+func (w activityWindow) end() time.Time   { return w.hours[len(w.hours)-1].start.Add(time.Hour) }
+
+// reasons explains why the window qualifies, summarizing the observed ranges
+func (w activityWindow) reasons(input ActivityWindowsInput) string {
+	minTemp, maxTemp := w.hours[0].temp, w.hours[0].temp
+	maxWind, maxAQI, totalPrecip := 0, 0, 0.0
+	aqiUnknown := false
+	conditions := []string{}
+	seen := map[string]bool{}
+	for _, h := range w.hours {
+		minTemp = min(minTemp, h.temp)
+		maxTemp = max(maxTemp, h.temp)
+		maxWind = max(maxWind, h.wind)
+		totalPrecip += h.precip
+		if h.aqiKnown {
+			maxAQI = max(maxAQI, h.aqi)
+		} else {
+			aqiUnknown = true
+		}
+		if !seen[h.text] {
+			seen[h.text] = true
+			conditions = append(conditions, h.text)
+		}
+	}
+
+	reasons := []string{fmt.Sprintf("temperature %.0f~%.0f°C", minTemp, maxTemp)}
+	if input.MinTemp != nil || input.MaxTemp != nil {
+		reasons[0] += " (within range)"
+	}
+	if input.NoPrecipitation {
+		reasons = append(reasons, "no precipitation")
+	} else {
+		reasons = append(reasons, fmt.Sprintf("precipitation %.1fmm", totalPrecip))
+	}
+	reasons = append(reasons, fmt.Sprintf("wind up to force %d", maxWind))
+	if input.MaxAQI != nil {
+		switch {
+		case aqiUnknown && maxAQI == 0:
+			reasons = append(reasons, "AQI not forecast for this period")
+		case aqiUnknown:
+			reasons = append(reasons, fmt.Sprintf("AQI up to %d where forecast", maxAQI))
+		default:
+			reasons = append(reasons, fmt.Sprintf("AQI up to %d", maxAQI))
+		}
+	}
+	reasons = append(reasons, strings.Join(conditions, "/"))
+	return strings.Join(reasons, ", ")
+}
+
+// fetchActivityAQI returns the hourly air quality forecast keyed by hour, preferring the QWeather universal AQI
+func fetchActivityAQI(ctx context.Context, client *api.Client, location *api.Location) (map[int64]int, error) {
+	lat, lon := location.Coordinates()
+	airQualityData, err := client.GetAirQualityHourly(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	aqiByHour := make(map[int64]int)
+	for _, hour := range airQualityData.Hours {
+		t, err := parseForecastTime(hour.ForecastTime)
+		if err != nil {
+			continue
+		}
+		if index, ok := selectAQIIndex(hour.Indexes, ""); ok {
+			aqiByHour[t.Truncate(time.Hour).Unix()] = index.Aqi
+		}
+	}
+	return aqiByHour, nil
+}
+
+// meetsActivityConstraints reports whether an hour satisfies every constraint in the input
+func meetsActivityConstraints(h activityHour, input ActivityWindowsInput) bool {
+	if input.MinTemp != nil && h.temp < *input.MinTemp {
+		return false
+	}
+	if input.MaxTemp != nil && h.temp > *input.MaxTemp {
+		return false
+	}
+	if input.NoPrecipitation && h.precip > 0 {
+		return false
+	}
+	if input.MaxWindScale != nil && h.wind > *input.MaxWindScale {
+		return false
+	}
+	if input.MaxAQI != nil && h.aqiKnown && h.aqi > *input.MaxAQI {
+		return false
+	}
+	return true
+}
+
+// findActivityWindows groups consecutive qualifying hours into windows of at least minHours
+func findActivityWindows(hours []activityHour, input ActivityWindowsInput, minHours int) []activityWindow {
+	var windows []activityWindow
+	var current []activityHour
+	flush := func() {
+		if len(current) >= minHours {
+			windows = append(windows, activityWindow{hours: current})
+		}
+		current = nil
+	}
+	for _, h := range hours {
+		ok := meetsActivityConstraints(h, input)
+		contiguous := len(current) == 0 || h.start.Sub(current[len(current)-1].start) == time.Hour
+		if !ok || !contiguous {
+			flush()
+		}
+		if ok {
+			current = append(current, h)
+		}
+	}
+	flush()
+
+	// Longest windows first; earlier windows win ties
+	sort.SliceStable(windows, func(i, j int) bool {
+		return len(windows[i].hours) > len(windows[j].hours)
+	})
+	return windows
+}
+
+func handleActivityWindows(ctx context.Context, client *api.Client, input ActivityWindowsInput, progress *progressReporter) (ActivityWindowsOutput, error) {
+	if input.CityName == "" {
+		return ActivityWindowsOutput{}, fmt.Errorf("city name cannot be empty")
+	}
+
+	if input.Hours == "" {
+		input.Hours = "168h"
+	}
+	if !validHourlyHours[input.Hours] {
+		return ActivityWindowsOutput{}, fmt.Errorf("invalid hours parameter: must be one of 24h, 72h, 168h")
+	}
+
+	if input.MinTemp != nil && input.MaxTemp != nil && *input.MinTemp > *input.MaxTemp {
+		return ActivityWindowsOutput{}, fmt.Errorf("minTemp cannot be greater than maxTemp")
+	}
+
+	minHours := input.MinDurationHours
+	if minHours <= 0 {
+		minHours = 1
+	}
+	if input.MaxResults <= 0 {
+		input.MaxResults = defaultActivityResults
+	}
+	if input.MaxResults > maxActivityResults {
+		return ActivityWindowsOutput{}, fmt.Errorf("maxResults cannot exceed %d", maxActivityResults)
+	}
+
+	location, err := resolveLocation(ctx, client, input.CityName)
+	if err != nil {
+		return ActivityWindowsOutput{}, err
+	}
+	// Resolving, the hourly forecast, the optional air quality forecast, then searching
+	steps := 3
+	if input.MaxAQI != nil {
+		steps++
+	}
+	progress.setTotal(steps)
+	progress.step("resolved location %s", location.Name)
+
+	hourlyData, err := client.GetHourlyForecast(ctx, location.ID, input.Hours)
+	if err != nil {
+		return ActivityWindowsOutput{}, fmt.Errorf("failed to get hourly weather forecast data: %w", err)
+	}
+	if hourlyData.Code != api.APICodeSuccess {
+		return ActivityWindowsOutput{}, fmt.Errorf("failed to get hourly weather forecast data, API returned an error")
+	}
+	progress.step("fetched hourly forecast")
+
+	var aqiByHour map[int64]int
+	var aqiNote string
+	if input.MaxAQI != nil {
+		aqiByHour, err = fetchActivityAQI(ctx, client, location)
+		if err != nil {
+			aqiNote = fmt.Sprintf("Note: air quality forecast unavailable (%v), the AQI constraint was not applied", err)
+		}
+		progress.step("fetched air quality forecast")
+	}
+
+	hours := make([]activityHour, 0, len(hourlyData.Hourly))
+	for _, hour := range hourlyData.Hourly {
+		start, err := parseForecastTime(hour.FxTime)
+		if err != nil {
+			continue
+		}
+		h := activityHour{start: start, text: hour.Text}
+		h.temp, _ = strconv.ParseFloat(hour.Temp, 64)
+		h.precip, _ = strconv.ParseFloat(hour.Precip, 64)
+		h.wind, _ = parseWindScale(hour.WindScale)
+		h.aqi, h.aqiKnown = aqiByHour[start.Truncate(time.Hour).Unix()]
+		hours = append(hours, h)
+	}
+
+	progress.step("searching windows")
+	windows := findActivityWindows(hours, input, minHours)
+
+	windowsText := []string{
+		fmt.Sprintf("Activity Windows - %s (%s %s), next %s:", location.Name, location.Adm1, location.Adm2, input.Hours),
+	}
+	if aqiNote != "" {
+		windowsText = append(windowsText, aqiNote)
+	}
+	windowsText = append(windowsText, "")
+
+	if len(windows) == 0 {
+		windowsText = append(windowsText, fmt.Sprintf("No window of at least %d hour(s) meets all constraints", minHours))
+		return ActivityWindowsOutput{WindowsInfo: strings.Join(windowsText, "\n")}, nil
+	}
+
+	for i, w := range windows {
+		if i >= input.MaxResults {
+			break
+		}
+		windowsText = append(windowsText, fmt.Sprintf("%d. %s - %s (%d hours)\n   %s",
+			i+1,
+			w.start().Format("Mon 2006-01-02 15:04"),
+			w.end().Format("15:04"),
+			len(w.hours),
+			w.reasons(input)))
+	}
+	if len(windows) > input.MaxResults {
+		windowsText = append(windowsText, fmt.Sprintf("... and %d more shorter windows", len(windows)-input.MaxResults))
+	}
+
+	return ActivityWindowsOutput{WindowsInfo: strings.Join(windowsText, "\n")}, nil
+}
+
+// RegisterActivityTools Register activity planning tools
+func RegisterActivityTools(s *mcp.Server, client *api.Client) {
+	// Activity window finder tool
+	mcp.AddTool(s, catalogTool[ActivityWindowsOutput]("find-activity-windows"), func(ctx context.Context, req *mcp.CallToolRequest, input ActivityWindowsInput) (*mcp.CallToolResult, ActivityWindowsOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, ActivityWindowsOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleActivityWindows(ctx, client, input, newProgressReporter(ctx, req))
+		if err != nil {
+			return nil, ActivityWindowsOutput{}, err
+		}
+		return nil, out, nil
+	})
+}
@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+func TestHandleActivityWindows_RanksWindows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			json.NewEncoder(w).Encode(api.LocationResponse{
+				Code: "200",
+				Location: []api.Location{{
+					Name: "Beijing", ID: "101010100", Lat: "39.90", Lon: "116.41", Adm1: "Beijing", Adm2: "Beijing",
+				}},
+			})
+		case "/v7/weather/168h":
+			w.Write([]byte(`{"code":"200","hourly":[
+				{"fxTime":"2024-05-01T06:00+08:00","temp":"15","text":"Sunny","windScale":"2","precip":"0.0"},
+				{"fxTime":"2024-05-01T07:00+08:00","temp":"16","text":"Sunny","windScale":"3","precip":"0.0"},
+				{"fxTime":"2024-05-01T08:00+08:00","temp":"17","text":"Rain","windScale":"3","precip":"1.2"},
+				{"fxTime":"2024-05-01T09:00+08:00","temp":"18","text":"Cloudy","windScale":"2","precip":"0.0"},
+				{"fxTime":"2024-05-01T10:00+08:00","temp":"19","text":"Cloudy","windScale":"2","precip":"0.0"},
+				{"fxTime":"2024-05-01T11:00+08:00","temp":"20","text":"Sunny","windScale":"3","precip":"0.0"},
+				{"fxTime":"2024-05-01T12:00+08:00","temp":"29","text":"Sunny","windScale":"3","precip":"0.0"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	maxTemp := 25.0
+	client := api.NewClient(server.URL, "test-key")
+	out, err := handleActivityWindows(context.Background(), client, ActivityWindowsInput{
+		CityName:         "Beijing",
+		MaxTemp:          &maxTemp,
+		NoPrecipitation:  true,
+		MinDurationHours: 2,
+	}, nil)
+	if err != nil {
+		t.Fatalf("handleActivityWindows failed: %v", err)
+	}
+	for _, want := range []string{
+		"1. Wed 2024-05-01 09:00 - 12:00 (3 hours)\n   temperature 18~20°C (within range), no precipitation, wind up to force 3, Cloudy/Sunny",
+		"2. Wed 2024-05-01 06:00 - 08:00 (2 hours)",
+	} {
+		if !strings.Contains(out.WindowsInfo, want) {
+			t.Fatalf("WindowsInfo = %q, want to contain %q", out.WindowsInfo, want)
+		}
+	}
+}
+
+func TestHandleActivityWindows_MaxAQI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			json.NewEncoder(w).Encode(api.LocationResponse{
+				Code: "200",
+				Location: []api.Location{{
+					Name: "Beijing", ID: "101010100", Lat: "39.90", Lon: "116.41", Adm1: "Beijing", Adm2: "Beijing",
+				}},
+			})
+		case "/v7/weather/24h":
+			w.Write([]byte(`{"code":"200","hourly":[
+				{"fxTime":"2024-05-01T06:00+08:00","temp":"15","text":"Sunny","windScale":"2","precip":"0.0"},
+				{"fxTime":"2024-05-01T07:00+08:00","temp":"16","text":"Sunny","windScale":"2","precip":"0.0"},
+				{"fxTime":"2024-05-01T08:00+08:00","temp":"17","text":"Haze","windScale":"2","precip":"0.0"},
+				{"fxTime":"2024-05-01T09:00+08:00","temp":"18","text":"Sunny","windScale":"2","precip":"0.0"},
+				{"fxTime":"2024-05-01T10:00+08:00","temp":"19","text":"Sunny","windScale":"2","precip":"0.0"},
+				{"fxTime":"2024-05-01T11:00+08:00","temp":"20","text":"Sunny","windScale":"2","precip":"0.0"}]}`))
+		case "/airquality/v1/hourly/39.90/116.41":
+			// Forecast times in the minute precision QWeather uses
+			w.Write([]byte(`{"code":"200","hours":[
+				{"forecastTime":"2024-05-01T06:00+08:00","indexes":[{"code":"qaqi","aqi":40}]},
+				{"forecastTime":"2024-05-01T07:00+08:00","indexes":[{"code":"qaqi","aqi":45}]},
+				{"forecastTime":"2024-05-01T08:00+08:00","indexes":[{"code":"qaqi","aqi":150}]},
+				{"forecastTime":"2024-05-01T09:00+08:00","indexes":[{"code":"qaqi","aqi":60}]},
+				{"forecastTime":"2024-05-01T10:00+08:00","indexes":[{"code":"qaqi","aqi":70}]},
+				{"forecastTime":"2024-05-01T11:00+08:00","indexes":[{"code":"qaqi","aqi":60}]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	maxAQI := 100
+	client := api.NewClient(server.URL, "test-key")
+	out, err := handleActivityWindows(context.Background(), client, ActivityWindowsInput{
+		CityName: "Beijing",
+		Hours:    "24h",
+		MaxAQI:   &maxAQI,
+	}, nil)
+	if err != nil {
+		t.Fatalf("handleActivityWindows failed: %v", err)
+	}
+	for _, want := range []string{
+		"1. Wed 2024-05-01 09:00 - 12:00 (3 hours)\n   temperature 18~20°C, precipitation 0.0mm, wind up to force 2, AQI up to 70, Sunny",
+		"2. Wed 2024-05-01 06:00 - 08:00 (2 hours)\n   temperature 15~16°C, precipitation 0.0mm, wind up to force 2, AQI up to 45, Sunny",
+	} {
+		if !strings.Contains(out.WindowsInfo, want) {
+			t.Fatalf("WindowsInfo = %q, want to contain %q", out.WindowsInfo, want)
+		}
+	}
+	if strings.Contains(out.WindowsInfo, "Haze") {
+		t.Fatalf("WindowsInfo = %q, want the hour above the AQI limit excluded", out.WindowsInfo)
+	}
+}
+
+func TestHandleActivityWindows_Validation(t *testing.T) {
+	client := api.NewClient("http://example.com", "test-key")
+	minTemp, maxTemp := 20.0, 10.0
+	tests := []struct {
+		name  string
+		input ActivityWindowsInput
+	}{
+		{"empty city", ActivityWindowsInput{}},
+		{"invalid hours", ActivityWindowsInput{CityName: "Beijing", Hours: "48h"}},
+		{"inverted temperature range", ActivityWindowsInput{CityName: "Beijing", MinTemp: &minTemp, MaxTemp: &maxTemp}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := handleActivityWindows(context.Background(), client, tt.input, nil); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 // AirQualityInput input parameters for get-air-quality tool
 type AirQualityInput struct {
 	CityName string `json:"cityName" jsonschema:"Name of the city to query real-time air quality for. Returns AQI, pollutants, and health recommendations."`
+	Standard string `json:"standard,omitempty" jsonschema:"Optional air quality standard to report, e.g. qaqi (QWeather universal AQI), us-epa, cn-mee or eu-caqi. If the API does not provide it, us-epa, cn-mee and eu-caqi are computed locally from pollutant concentrations. Defaults to all indexes reported by the API."`
 }
 
 // AirQualityOutput output structure for get-air-quality tool
@@ -24,6 +26,7 @@ type AirQualityOutput struct {
 // AirQualityHourlyInput input parameters for get-air-quality-hourly tool
 type AirQualityHourlyInput struct {
 	CityName string `json:"cityName" jsonschema:"Name of the city to query hourly air quality forecast for. Returns next 24 hours of AQI predictions."`
+	Standard string `json:"standard,omitempty" jsonschema:"Optional air quality standard to report, e.g. qaqi (QWeather universal AQI), us-epa, cn-mee or eu-caqi. If the API does not provide it, us-epa, cn-mee and eu-caqi are computed locally from pollutant concentrations. Defaults to all indexes reported by the API."`
 }
 
 // AirQualityHourlyOutput output structure for get-air-quality-hourly tool
@@ -34,6 +37,7 @@ type AirQualityHourlyOutput struct {
 // AirQualityDailyInput input parameters for get-air-quality-daily tool
 type AirQualityDailyInput struct {
 	CityName string `json:"cityName" jsonschema:"Name of the city to query daily air quality forecast for. Returns next 5 days of AQI predictions."`
+	Standard string `json:"standard,omitempty" jsonschema:"Optional air quality standard to report, e.g. qaqi (QWeather universal AQI), us-epa, cn-mee or eu-caqi. If the API does not provide it, us-epa, cn-mee and eu-caqi are computed locally from pollutant concentrations. Defaults to all indexes reported by the API."`
 }
 
 // AirQualityDailyOutput output structure for get-air-quality-daily tool
@@ -41,17 +45,28 @@ type AirQualityDailyOutput struct {
 	DailyInfo string `json:"dailyInfo" jsonschema:"Formatted daily air quality forecast with AQI trends and predictions"`
 }
 
-func handleAirQuality(client *api.Client, input AirQualityInput) (AirQualityOutput, error) {
+// StationAirQualityInput input parameters for get-station-air-quality tool
+type StationAirQualityInput struct {
+	CityName  string `json:"cityName" jsonschema:"Name of the city whose nearby air quality monitoring stations should be queried"`
+	StationID string `json:"stationId,omitempty" jsonschema:"Optional monitoring station ID (as listed by get-air-quality). If omitted, all stations related to the city are queried and compared."`
+}
+
+// StationAirQualityOutput output structure for get-station-air-quality tool
+type StationAirQualityOutput struct {
+	StationInfo string `json:"stationInfo" jsonschema:"Formatted per-station pollutant concentrations and a comparison summary across stations"`
+}
+
+func handleAirQuality(ctx context.Context, client *api.Client, input AirQualityInput) (AirQualityOutput, error) {
 	if input.CityName == "" {
 		return AirQualityOutput{}, fmt.Errorf("city name cannot be empty")
 	}
 
-	lat, lon, cityInfo, err := client.GetCityCoordinates(input.CityName)
+	lat, lon, cityInfo, err := client.GetCityCoordinates(ctx, input.CityName)
 	if err != nil {
 		return AirQualityOutput{}, err
 	}
 
-	airQualityData, err := client.GetAirQuality(lat, lon)
+	airQualityData, err := client.GetAirQuality(ctx, lat, lon)
 	if err != nil {
 		return AirQualityOutput{}, fmt.Errorf("failed to get air quality data: %v (Coordinates: lat=%s, lon=%s)", err, lat, lon)
 	}
@@ -62,8 +77,7 @@ func handleAirQuality(client *api.Client, input AirQualityInput) (AirQualityOutp
 	}
 
 	if airQualityData.Code == "unknown" && len(airQualityData.Indexes) > 0 {
-		fmt.Printf("API returned status code 'unknown', but indexes array is not empty. Data is valid, continuing to process request\n")
-		fmt.Printf("Data structure analysis: API response contains %d air quality indexes\n", len(airQualityData.Indexes))
+		slog.DebugContext(ctx, "API returned status code 'unknown' with data, continuing", "indexes", len(airQualityData.Indexes))
 	}
 
 	if len(airQualityData.Indexes) == 0 {
@@ -71,15 +85,28 @@ func handleAirQuality(client *api.Client, input AirQualityInput) (AirQualityOutp
 			lat, lon, airQualityData.Code)
 	}
 
+	indexes, computed, err := resolveAQIStandard(airQualityData.Indexes, airQualityData.Pollutants, input.Standard, "")
+	if err != nil {
+		return AirQualityOutput{}, err
+	}
+
 	airQualityText := []string{
 		fmt.Sprintf("Real-time Air Quality - %s (%s %s):", cityInfo.Name, cityInfo.Adm1, cityInfo.Adm2),
 		"",
-		"Air Quality Index:",
+	}
+	if len(indexes) > 1 {
+		airQualityText = append(airQualityText,
+			fmt.Sprintf("Indexes are reported under several standards (%s); qaqi is the QWeather universal AQI, comparable across countries. Use the standard parameter to select one.", indexCodes(indexes)),
+			"")
+	}
+	airQualityText = append(airQualityText, "Air Quality Index:")
+	if computed != "" {
+		airQualityText = append(airQualityText, computed, "---")
 	}
 
-	for _, index := range airQualityData.Indexes {
+	for _, index := range indexes {
 		indexInfo := []string{
-			fmt.Sprintf("%s: %s", index.Name, index.AqiDisplay),
+			fmt.Sprintf("%s (%s): %s", index.Name, index.Code, index.AqiDisplay),
 		}
 
 		if index.Level != "" {
@@ -124,17 +151,17 @@ func handleAirQuality(client *api.Client, input AirQualityInput) (AirQualityOutp
 	return AirQualityOutput{AirQualityInfo: strings.Join(airQualityText, "\n")}, nil
 }
 
-func handleAirQualityHourly(client *api.Client, input AirQualityHourlyInput) (AirQualityHourlyOutput, error) {
+func handleAirQualityHourly(ctx context.Context, client *api.Client, input AirQualityHourlyInput) (AirQualityHourlyOutput, error) {
 	if input.CityName == "" {
 		return AirQualityHourlyOutput{}, fmt.Errorf("city name cannot be empty")
 	}
 
-	lat, lon, cityInfo, err := client.GetCityCoordinates(input.CityName)
+	lat, lon, cityInfo, err := client.GetCityCoordinates(ctx, input.CityName)
 	if err != nil {
 		return AirQualityHourlyOutput{}, err
 	}
 
-	airQualityData, err := client.GetAirQualityHourly(lat, lon)
+	airQualityData, err := client.GetAirQualityHourly(ctx, lat, lon)
 	if err != nil {
 		return AirQualityHourlyOutput{}, fmt.Errorf("failed to get hourly air quality forecast data: %v (Coordinates: lat=%s, lon=%s)", err, lat, lon)
 	}
@@ -145,8 +172,7 @@ func handleAirQualityHourly(client *api.Client, input AirQualityHourlyInput) (Ai
 	}
 
 	if airQualityData.Code == "unknown" && len(airQualityData.Hours) > 0 {
-		fmt.Printf("API returned status code 'unknown', but hours array is not empty. Data is valid, continuing to process request\n")
-		fmt.Printf("Data structure analysis: API response contains %d hours of forecast data\n", len(airQualityData.Hours))
+		slog.DebugContext(ctx, "API returned status code 'unknown' with data, continuing", "hours", len(airQualityData.Hours))
 	}
 
 	if len(airQualityData.Hours) == 0 {
@@ -167,7 +193,14 @@ func handleAirQualityHourly(client *api.Client, input AirQualityHourlyInput) (Ai
 		timeStr := t.Format("2006-01-02 15:04") + " UTC"
 
 		var indexInfos []string
-		for _, index := range hour.Indexes {
+		indexes, computed, err := resolveAQIStandard(hour.Indexes, hour.Pollutants, input.Standard, "  ")
+		switch {
+		case err != nil:
+			indexInfos = append(indexInfos, fmt.Sprintf("Air Quality Index: %v", err))
+		case computed != "":
+			indexInfos = append(indexInfos, "Air Quality Index:\n"+computed)
+		}
+		for _, index := range indexes {
 			var healthInfo string
 			if index.Health != nil {
 				healthInfo = fmt.Sprintf("Health Effects: %s\nHealth Recommendations:\n  General Population: %s\n  Sensitive Population: %s",
@@ -183,7 +216,7 @@ func handleAirQualityHourly(client *api.Client, input AirQualityHourlyInput) (Ai
 
 			indexInfo := []string{
 				"Air Quality Index:",
-				fmt.Sprintf("  %s: %s", index.Name, index.AqiDisplay),
+				fmt.Sprintf("  %s (%s): %s", index.Name, index.Code, index.AqiDisplay),
 				fmt.Sprintf("  Level: %s", index.Level),
 				fmt.Sprintf("  Category: %s", index.Category),
 				primaryPollutant,
@@ -217,17 +250,17 @@ func handleAirQualityHourly(client *api.Client, input AirQualityHourlyInput) (Ai
 	return AirQualityHourlyOutput{HourlyInfo: strings.Join(hourlyText, "\n")}, nil
 }
 
-func handleAirQualityDaily(client *api.Client, input AirQualityDailyInput) (AirQualityDailyOutput, error) {
+func handleAirQualityDaily(ctx context.Context, client *api.Client, input AirQualityDailyInput) (AirQualityDailyOutput, error) {
 	if input.CityName == "" {
 		return AirQualityDailyOutput{}, fmt.Errorf("city name cannot be empty")
 	}
 
-	lat, lon, cityInfo, err := client.GetCityCoordinates(input.CityName)
+	lat, lon, cityInfo, err := client.GetCityCoordinates(ctx, input.CityName)
 	if err != nil {
 		return AirQualityDailyOutput{}, err
 	}
 
-	airQualityData, err := client.GetAirQualityDaily(lat, lon)
+	airQualityData, err := client.GetAirQualityDaily(ctx, lat, lon)
 	if err != nil {
 		return AirQualityDailyOutput{}, fmt.Errorf("failed to get daily air quality forecast data: %v (Coordinates: lat=%s, lon=%s)", err, lat, lon)
 	}
@@ -238,8 +271,7 @@ func handleAirQualityDaily(client *api.Client, input AirQualityDailyInput) (AirQ
 	}
 
 	if airQualityData.Code == "unknown" && len(airQualityData.Days) > 0 {
-		fmt.Printf("API returned status code 'unknown', but days array is not empty. Data is valid, continuing to process request\n")
-		fmt.Printf("Data structure analysis: API response contains %d days of forecast data\n", len(airQualityData.Days))
+		slog.DebugContext(ctx, "API returned status code 'unknown' with data, continuing", "days", len(airQualityData.Days))
 	}
 
 	if len(airQualityData.Days) == 0 {
@@ -266,7 +298,14 @@ func handleAirQualityDaily(client *api.Client, input AirQualityDailyInput) (AirQ
 		endTimeStr := endTime.Format("2006-01-02 15:04") + " UTC"
 
 		var indexInfos []string
-		for _, index := range day.Indexes {
+		indexes, computed, err := resolveAQIStandard(day.Indexes, day.Pollutants, input.Standard, "  ")
+		switch {
+		case err != nil:
+			indexInfos = append(indexInfos, fmt.Sprintf("Air Quality Index: %v", err))
+		case computed != "":
+			indexInfos = append(indexInfos, "Air Quality Index:\n"+computed)
+		}
+		for _, index := range indexes {
 			var healthInfo string
 			if index.Health != nil {
 				healthInfo = fmt.Sprintf("Health Effects: %s\nHealth Recommendations:\n  General Population: %s\n  Sensitive Population: %s",
@@ -282,7 +321,7 @@ func handleAirQualityDaily(client *api.Client, input AirQualityDailyInput) (AirQ
 
 			indexInfo := []string{
 				"Air Quality Index:",
-				fmt.Sprintf("  %s: %s", index.Name, index.AqiDisplay),
+				fmt.Sprintf("  %s (%s): %s", index.Name, index.Code, index.AqiDisplay),
 				fmt.Sprintf("  Level: %s", index.Level),
 				fmt.Sprintf("  Category: %s", index.Category),
 				primaryPollutant,
@@ -316,14 +355,127 @@ func handleAirQualityDaily(client *api.Client, input AirQualityDailyInput) (AirQ
 	return AirQualityDailyOutput{DailyInfo: strings.Join(dailyText, "\n")}, nil
 }
 
+// stationReading pollutant concentrations observed by a single monitoring station
+type stationReading struct {
+	name       string
+	pollutants map[string]float64
+}
+
+func handleStationAirQuality(ctx context.Context, client *api.Client, input StationAirQualityInput) (StationAirQualityOutput, error) {
+	if input.CityName == "" {
+		return StationAirQualityOutput{}, fmt.Errorf("city name cannot be empty")
+	}
+
+	lat, lon, cityInfo, err := client.GetCityCoordinates(ctx, input.CityName)
+	if err != nil {
+		return StationAirQualityOutput{}, err
+	}
+
+	airQualityData, err := client.GetAirQuality(ctx, lat, lon)
+	if err != nil {
+		return StationAirQualityOutput{}, fmt.Errorf("failed to get related monitoring stations: %v (Coordinates: lat=%s, lon=%s)", err, lat, lon)
+	}
+
+	type station struct{ ID, Name string }
+	var stations []station
+	for _, s := range airQualityData.Stations {
+		if input.StationID == "" || s.ID == input.StationID {
+			stations = append(stations, station{ID: s.ID, Name: s.Name})
+		}
+	}
+	if input.StationID != "" && len(stations) == 0 {
+		stations = append(stations, station{ID: input.StationID, Name: input.StationID})
+	}
+
+	if len(stations) == 0 {
+		stationInfo := fmt.Sprintf("Currently %s (%s %s) has no related air quality monitoring stations", cityInfo.Name, cityInfo.Adm1, cityInfo.Adm2)
+		return StationAirQualityOutput{StationInfo: stationInfo}, nil
+	}
+
+	stationText := []string{
+		fmt.Sprintf("Monitoring Station Air Quality - %s (%s %s):", cityInfo.Name, cityInfo.Adm1, cityInfo.Adm2),
+		"",
+	}
+
+	// Pollutant order and display metadata, taken from the first station reporting each pollutant
+	var pollutantCodes []string
+	pollutantNames := make(map[string]string)
+	pollutantUnits := make(map[string]string)
+	var readings []stationReading
+
+	for _, st := range stations {
+		stationData, err := client.GetAirQualityStation(ctx, st.ID)
+		if err != nil {
+			stationText = append(stationText, fmt.Sprintf("Station: %s (ID: %s)\nData unavailable: %v\n---", st.Name, st.ID, err))
+			continue
+		}
+
+		reading := stationReading{name: st.Name, pollutants: make(map[string]float64)}
+		stationInfo := []string{fmt.Sprintf("Station: %s (ID: %s)", st.Name, st.ID)}
+		if len(stationData.Pollutants) == 0 {
+			stationInfo = append(stationInfo, "No pollutant data")
+		}
+		for _, pollutant := range stationData.Pollutants {
+			stationInfo = append(stationInfo, fmt.Sprintf("  %s: %.1f%s", pollutant.Name, pollutant.Concentration.Value, pollutant.Concentration.Unit))
+			if _, seen := pollutantNames[pollutant.Code]; !seen {
+				pollutantCodes = append(pollutantCodes, pollutant.Code)
+				pollutantNames[pollutant.Code] = pollutant.Name
+				pollutantUnits[pollutant.Code] = pollutant.Concentration.Unit
+			}
+			reading.pollutants[pollutant.Code] = pollutant.Concentration.Value
+		}
+		stationInfo = append(stationInfo, "---")
+		stationText = append(stationText, strings.Join(stationInfo, "\n"))
+		readings = append(readings, reading)
+	}
+
+	if len(readings) == 0 {
+		return StationAirQualityOutput{}, fmt.Errorf("failed to get station air quality data: no station returned data (Coordinates: lat=%s, lon=%s)", lat, lon)
+	}
+
+	if len(readings) > 1 {
+		stationText = append(stationText, "", "Station Comparison:")
+		for _, code := range pollutantCodes {
+			stationText = append(stationText, compareStationPollutant(readings, code, pollutantNames[code], pollutantUnits[code]))
+		}
+	}
+
+	return StationAirQualityOutput{StationInfo: strings.Join(stationText, "\n")}, nil
+}
+
+// compareStationPollutant summarizes one pollutant across stations: lowest, highest and average reading
+func compareStationPollutant(readings []stationReading, code, name, unit string) string {
+	var lowest, highest string
+	var minValue, maxValue, sum float64
+	count := 0
+	for _, r := range readings {
+		value, ok := r.pollutants[code]
+		if !ok {
+			continue
+		}
+		if count == 0 || value < minValue {
+			minValue, lowest = value, r.name
+		}
+		if count == 0 || value > maxValue {
+			maxValue, highest = value, r.name
+		}
+		sum += value
+		count++
+	}
+	return fmt.Sprintf("%s: lowest %.1f%s (%s), highest %.1f%s (%s), average %.1f%s across %d stations",
+		name, minValue, unit, lowest, maxValue, unit, highest, sum/float64(count), unit, count)
+}
+
 // RegisterAirQualityTools Register air quality related tools
 func RegisterAirQualityTools(s *mcp.Server, client *api.Client) {
 	// Real-time air quality tool
-	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get-air-quality",
-		Description: "Real-time air quality API provides air quality data for specific locations with 1x1 kilometer precision. Includes AQI based on different national/regional local standards, AQI level, color, main pollutants, QWeather universal AQI, pollutant concentrations, sub-indices, health recommendations, and related monitoring station information.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input AirQualityInput) (*mcp.CallToolResult, AirQualityOutput, error) {
-		out, err := handleAirQuality(client, input)
+	mcp.AddTool(s, catalogTool[AirQualityOutput]("get-air-quality"), func(ctx context.Context, req *mcp.CallToolRequest, input AirQualityInput) (*mcp.CallToolResult, AirQualityOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, AirQualityOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleAirQuality(ctx, client, input)
 		if err != nil {
 			return nil, AirQualityOutput{}, err
 		}
@@ -331,11 +483,13 @@ func RegisterAirQualityTools(s *mcp.Server, client *api.Client) {
 	})
 
 	// Hourly air quality forecast tool
-	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get-air-quality-hourly",
-		Description: "Hourly air quality forecast API provides air quality data for the next 24 hours, including AQI, pollutant concentrations, sub-indices, and health recommendations. Data includes various air quality standards (such as QAQI, GB-DEFRA, etc.) and specific concentrations of pollutants like PM2.5, PM10, NO2, O3, SO2, etc.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input AirQualityHourlyInput) (*mcp.CallToolResult, AirQualityHourlyOutput, error) {
-		out, err := handleAirQualityHourly(client, input)
+	mcp.AddTool(s, catalogTool[AirQualityHourlyOutput]("get-air-quality-hourly"), func(ctx context.Context, req *mcp.CallToolRequest, input AirQualityHourlyInput) (*mcp.CallToolResult, AirQualityHourlyOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, AirQualityHourlyOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleAirQualityHourly(ctx, client, input)
 		if err != nil {
 			return nil, AirQualityHourlyOutput{}, err
 		}
@@ -343,14 +497,30 @@ func RegisterAirQualityTools(s *mcp.Server, client *api.Client) {
 	})
 
 	// Daily air quality forecast tool
-	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get-air-quality-daily",
-		Description: "Daily air quality forecast API provides air quality predictions for the next 3 days, including AQI values, pollutant concentrations, and health recommendations. Data includes various air quality standards and specific concentrations of pollutants such as PM2.5, PM10, NO2, O3, SO2, etc.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input AirQualityDailyInput) (*mcp.CallToolResult, AirQualityDailyOutput, error) {
-		out, err := handleAirQualityDaily(client, input)
+	mcp.AddTool(s, catalogTool[AirQualityDailyOutput]("get-air-quality-daily"), func(ctx context.Context, req *mcp.CallToolRequest, input AirQualityDailyInput) (*mcp.CallToolResult, AirQualityDailyOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
 		if err != nil {
 			return nil, AirQualityDailyOutput{}, err
 		}
+		input.CityName = cityName
+		out, err := handleAirQualityDaily(ctx, client, input)
+		if err != nil {
+			return nil, AirQualityDailyOutput{}, err
+		}
+		return nil, out, nil
+	})
+
+	// Monitoring station air quality tool
+	mcp.AddTool(s, catalogTool[StationAirQualityOutput]("get-station-air-quality"), func(ctx context.Context, req *mcp.CallToolRequest, input StationAirQualityInput) (*mcp.CallToolResult, StationAirQualityOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, StationAirQualityOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleStationAirQuality(ctx, client, input)
+		if err != nil {
+			return nil, StationAirQualityOutput{}, err
+		}
 		return nil, out, nil
 	})
 }
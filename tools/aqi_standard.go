@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+	"github.com/overstarry/qweather-mcp-go/aqi"
+)
+
+// universalAQICode code of the QWeather universal AQI, preferred when no standard is requested
+// because local standards are not comparable across countries
+const universalAQICode = "qaqi"
+
+// selectAQIIndex returns the index reported under the requested standard. Without a standard
+// the QWeather universal AQI is preferred, falling back to the first index.
+func selectAQIIndex(indexes []api.AirQualityIndex, standard string) (api.AirQualityIndex, bool) {
+	if len(indexes) == 0 {
+		return api.AirQualityIndex{}, false
+	}
+	code := standard
+	if code == "" {
+		code = universalAQICode
+	}
+	for _, index := range indexes {
+		if strings.EqualFold(index.Code, code) {
+			return index, true
+		}
+	}
+	if standard == "" {
+		return indexes[0], true
+	}
+	return api.AirQualityIndex{}, false
+}
+
+// indexCodes lists the standards reported by the API
+func indexCodes(indexes []api.AirQualityIndex) string {
+	codes := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		codes = append(codes, index.Code)
+	}
+	return strings.Join(codes, ", ")
+}
+
+// resolveAQIStandard narrows the indexes to the requested standard. When the API does not report
+// that standard it is computed locally from the pollutant concentrations and returned as formatted text.
+func resolveAQIStandard(indexes []api.AirQualityIndex, pollutants []api.AirQualityPollutant, standard, indent string) ([]api.AirQualityIndex, string, error) {
+	if standard == "" {
+		return indexes, "", nil
+	}
+	if index, ok := selectAQIIndex(indexes, standard); ok {
+		return []api.AirQualityIndex{index}, "", nil
+	}
+
+	concentrations := make(aqi.Concentrations, len(pollutants))
+	names := make(map[string]string, len(pollutants))
+	for _, pollutant := range pollutants {
+		value, err := aqi.Normalize(pollutant.Code, pollutant.Concentration.Value, pollutant.Concentration.Unit)
+		if err != nil {
+			// A pollutant in a unit that cannot be converted is left out rather than misread
+			continue
+		}
+		concentrations[pollutant.Code] = value
+		names[pollutant.Code] = pollutant.Name
+	}
+	result, err := aqi.Compute(strings.ToLower(standard), concentrations)
+	if err != nil {
+		return nil, "", fmt.Errorf("standard %s is not reported for this location (available: %s) and cannot be computed locally: %w",
+			standard, indexCodes(indexes), err)
+	}
+	return nil, formatComputedAQI(result, names, indent), nil
+}
+
+// formatComputedAQI renders a locally computed index in the layout used for API indexes
+func formatComputedAQI(result aqi.Result, names map[string]string, indent string) string {
+	displayName := func(code string) string {
+		if name := names[code]; name != "" {
+			return name
+		}
+		return code
+	}
+
+	subIndexes := make([]string, 0, len(result.SubIndexes))
+	for _, sub := range result.SubIndexes {
+		subIndexes = append(subIndexes, fmt.Sprintf("%s %d", displayName(sub.Pollutant), sub.Value))
+	}
+
+	return strings.Join([]string{
+		fmt.Sprintf("%s%s (%s): %d - computed locally from pollutant concentrations", indent, result.Name, result.Standard, result.AQI),
+		fmt.Sprintf("%sCategory: %s", indent, result.Category),
+		fmt.Sprintf("%sMain Pollutant: %s", indent, displayName(result.Primary)),
+		fmt.Sprintf("%sSub-indexes: %s", indent, strings.Join(subIndexes, ", ")),
+	}, "\n")
+}
@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// briefingIndexTypes life index types included in the briefing: sports, dressing and UV
+const briefingIndexTypes = "1,3,5"
+
+// WeatherBriefingInput input parameters for get-weather-briefing tool
+type WeatherBriefingInput struct {
+	CityName string `json:"cityName" jsonschema:"Name of the city to prepare a weather briefing for (e.g. Beijing, London)"`
+}
+
+// WeatherBriefingOutput output structure for get-weather-briefing tool
+type WeatherBriefingOutput struct {
+	BriefingInfo string `json:"briefingInfo" jsonschema:"Compact briefing with current weather, today's and tomorrow's forecast, active warnings, air quality and key life indices"`
+}
+
+// weatherBriefing data sources fetched for a briefing; each source keeps its own error so
+// unavailable sections degrade gracefully instead of failing the whole briefing
+type weatherBriefing struct {
+	now         *api.WeatherNowResponse
+	nowErr      error
+	forecast    *api.WeatherDailyResponse
+	forecastErr error
+	warning     *api.WarningResponse
+	warningErr  error
+	aqi         *aqiReading
+	aqiErr      error
+	indices     *api.IndicesResponse
+	indicesErr  error
+}
+
+// briefingSources number of data sources fetched for a briefing
+const briefingSources = 5
+
+// fetchWeatherBriefing fetches all briefing sources for a resolved location in parallel
+func fetchWeatherBriefing(ctx context.Context, client *api.Client, location *api.Location, progress *progressReporter) *weatherBriefing {
+	b := &weatherBriefing{}
+	var wg sync.WaitGroup
+	wg.Add(briefingSources)
+	go func() {
+		defer wg.Done()
+		defer progress.fetched("sources", briefingSources)
+		b.now, b.nowErr = client.GetWeatherNow(ctx, location.ID)
+		if b.nowErr == nil {
+			b.nowErr = checkCode(b.now.Code)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		defer progress.fetched("sources", briefingSources)
+		b.forecast, b.forecastErr = client.GetWeatherForecast(ctx, location.ID, "3d")
+		if b.forecastErr == nil {
+			b.forecastErr = checkCode(b.forecast.Code)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		defer progress.fetched("sources", briefingSources)
+		b.warning, b.warningErr = client.GetWeatherWarning(ctx, location.ID)
+		if b.warningErr == nil {
+			b.warningErr = checkCode(b.warning.Code)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		defer progress.fetched("sources", briefingSources)
+		b.aqi, b.aqiErr = fetchComparableAQI(ctx, client, location)
+	}()
+	go func() {
+		defer wg.Done()
+		defer progress.fetched("sources", briefingSources)
+		b.indices, b.indicesErr = client.GetWeatherIndices(ctx, location.ID, "1d", briefingIndexTypes)
+		if b.indicesErr == nil {
+			b.indicesErr = checkCode(b.indices.Code)
+		}
+	}()
+	wg.Wait()
+	return b
+}
+
+func handleWeatherBriefing(ctx context.Context, client *api.Client, input WeatherBriefingInput, progress *progressReporter) (WeatherBriefingOutput, error) {
+	location, err := resolveLocation(ctx, client, input.CityName)
+	if err != nil {
+		return WeatherBriefingOutput{}, err
+	}
+
+	// Resolving, each source, then formatting
+	progress.setTotal(briefingSources + 2)
+	progress.step("resolved location %s", location.Name)
+
+	b := fetchWeatherBriefing(ctx, client, location, progress)
+	if b.nowErr != nil && b.forecastErr != nil && b.warningErr != nil && b.aqiErr != nil && b.indicesErr != nil {
+		return WeatherBriefingOutput{}, fmt.Errorf("failed to get weather briefing: all data sources unavailable (current weather: %v)", b.nowErr)
+	}
+
+	progress.step("formatting")
+	briefingText := []string{
+		fmt.Sprintf("Weather Briefing - %s (%s %s):", location.Name, location.Adm1, location.Adm2),
+	}
+
+	if b.nowErr != nil {
+		briefingText = append(briefingText, fmt.Sprintf("Now: unavailable (%v)", b.nowErr))
+	} else {
+		now := b.now.Now
+		briefingText = append(briefingText, fmt.Sprintf("Now: %s°C (feels like %s°C), %s, %s Force %s, humidity %s%%",
+			now.Temp, now.FeelsLike, now.Text, now.WindDir, now.WindScale, now.Humidity))
+	}
+
+	if b.forecastErr != nil {
+		briefingText = append(briefingText, fmt.Sprintf("Forecast: unavailable (%v)", b.forecastErr))
+	} else {
+		for i, label := range []string{"Today", "Tomorrow"} {
+			if i >= len(b.forecast.Daily) {
+				break
+			}
+			day := b.forecast.Daily[i]
+			briefingText = append(briefingText, fmt.Sprintf("%s (%s): %s~%s°C, day %s / night %s, precipitation %smm, UV %s",
+				label, day.FxDate, day.TempMin, day.TempMax, day.TextDay, day.TextNight, day.Precip, day.UvIndex))
+		}
+	}
+
+	switch {
+	case b.warningErr != nil:
+		briefingText = append(briefingText, fmt.Sprintf("Warnings: unavailable (%v)", b.warningErr))
+	case len(b.warning.Warning) == 0:
+		briefingText = append(briefingText, "Warnings: none active")
+	default:
+		briefingText = append(briefingText, "Warnings:")
+		for _, warning := range b.warning.Warning {
+			briefingText = append(briefingText, fmt.Sprintf("- %s (%s)", warning.Title, warning.Severity))
+		}
+	}
+
+	if b.aqiErr != nil {
+		briefingText = append(briefingText, fmt.Sprintf("Air Quality: unavailable (%v)", b.aqiErr))
+	} else {
+		briefingText = append(briefingText, fmt.Sprintf("Air Quality: %s %d (%s)", b.aqi.name, b.aqi.aqi, b.aqi.category))
+	}
+
+	if b.indicesErr != nil {
+		briefingText = append(briefingText, fmt.Sprintf("Life Indices: unavailable (%v)", b.indicesErr))
+	} else if len(b.indices.Daily) > 0 {
+		briefingText = append(briefingText, "Life Indices:")
+		for _, index := range b.indices.Daily {
+			briefingText = append(briefingText, fmt.Sprintf("- %s: %s", index.Name, index.Category))
+		}
+	}
+
+	return WeatherBriefingOutput{BriefingInfo: strings.Join(briefingText, "\n")}, nil
+}
+
+// RegisterBriefingTools Register weather briefing tools
+func RegisterBriefingTools(s *mcp.Server, client *api.Client) {
+	// One-shot weather briefing tool
+	mcp.AddTool(s, catalogTool[WeatherBriefingOutput]("get-weather-briefing"), func(ctx context.Context, req *mcp.CallToolRequest, input WeatherBriefingInput) (*mcp.CallToolResult, WeatherBriefingOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, WeatherBriefingOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleWeatherBriefing(ctx, client, input, newProgressReporter(ctx, req))
+		if err != nil {
+			return nil, WeatherBriefingOutput{}, err
+		}
+		return nil, out, nil
+	})
+}
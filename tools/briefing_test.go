@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+func TestHandleWeatherBriefing_DegradesGracefully(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			json.NewEncoder(w).Encode(api.LocationResponse{
+				Code: "200",
+				Location: []api.Location{{
+					Name: "Beijing", ID: "101010100", Lat: "39.90", Lon: "116.41", Adm1: "Beijing", Adm2: "Beijing",
+				}},
+			})
+		case "/v7/weather/now":
+			resp := api.WeatherNowResponse{Code: "200"}
+			resp.Now.Temp = "20"
+			resp.Now.Text = "Sunny"
+			json.NewEncoder(w).Encode(resp)
+		case "/v7/weather/3d":
+			w.Write([]byte(`{"code":"200","daily":[{"fxDate":"2024-01-01","tempMin":"10","tempMax":"20"},{"fxDate":"2024-01-02","tempMin":"8","tempMax":"15"}]}`))
+		case "/v7/warning/now":
+			w.Write([]byte(`{"code":"200","warning":[{"title":"Gale Blue Warning","severity":"Minor"}]}`))
+		case "/v7/indices/1d":
+			w.Write([]byte(`{"code":"403"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "test-key")
+	out, err := handleWeatherBriefing(context.Background(), client, WeatherBriefingInput{CityName: "Beijing"}, nil)
+	if err != nil {
+		t.Fatalf("handleWeatherBriefing failed: %v", err)
+	}
+	for _, want := range []string{
+		"Weather Briefing - Beijing",
+		"Now: 20°C",
+		"Today (2024-01-01): 10~20°C",
+		"Tomorrow (2024-01-02): 8~15°C",
+		"- Gale Blue Warning (Minor)",
+		"Air Quality: unavailable",
+		"Life Indices: unavailable (API returned error code: 403)",
+	} {
+		if !strings.Contains(out.BriefingInfo, want) {
+			t.Fatalf("BriefingInfo = %q, want to contain %q", out.BriefingInfo, want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolInfo catalog entry describing a tool to hosts
+type toolInfo struct {
+	title       string
+	description string
+	readOnly    bool // Does not modify any state, so hosts may auto-approve it
+	idempotent  bool // Repeated calls have no additional effect
+	openWorld   bool // Queries the QWeather API rather than only local data
+}
+
+// toolCatalog title, description and behaviour hints of every tool, by name.
+// get-forecast-changes stores a snapshot on each call, so it is neither read-only nor idempotent.
+var toolCatalog = map[string]toolInfo{
+	"get-weather-now": {
+		title:       "Current Weather",
+		description: "Real-time weather API provides current weather conditions for cities worldwide. Available data includes: temperature, feels-like temperature, weather conditions, wind direction, wind force level, relative humidity, precipitation, atmospheric pressure, visibility and dew point, with derived comfort metrics (apparent temperature, heat index, wind chill, humidex, wet-bulb temperature and absolute humidity). Data is updated in real-time, providing the most accurate current weather information.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"get-weather-forecast": {
+		title:       "Daily Weather Forecast",
+		description: "Weather forecast API provides detailed weather predictions for cities worldwide, supporting forecasts from 3 to 30 days. Available data includes: sunrise/sunset times, moonrise/moonset times, temperature range, weather conditions, wind direction and speed, relative humidity, precipitation, atmospheric pressure, cloud cover, and UV index. Forecasts are updated daily to ensure accuracy.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"get-minutely-precipitation": {
+		title:       "Minutely Precipitation Forecast",
+		description: "Minutely precipitation forecast API provides accurate precipitation predictions for the next 2 hours for cities worldwide. Available data includes precipitation type (rain/snow) and amount for each minute. This high-precision forecast is particularly useful for outdoor activity planning and real-time weather monitoring.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"get-hourly-forecast": {
+		title:       "Hourly Weather Forecast",
+		description: "Hourly weather forecast API provides detailed weather information for the next 24-168 hours for cities worldwide. Available data includes: temperature, weather conditions, wind force, wind speed, wind direction, relative humidity, atmospheric pressure, precipitation probability, dew point temperature, and cloud cover, with derived feels-like temperature and comfort metrics (apparent temperature, heat index, wind chill, humidex, wet-bulb temperature and absolute humidity). Forecast data is updated hourly to ensure accuracy.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"get-weather-warning": {
+		title:       "Weather Warnings",
+		description: "Weather warning API provides real-time weather warning data issued by official agencies in China and multiple countries/regions worldwide. Data includes warning issuing agency, publication time, warning title, detailed warning information, warning level, warning type, and other relevant information.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"get-air-quality": {
+		title:       "Current Air Quality",
+		description: "Real-time air quality API provides air quality data for specific locations with 1x1 kilometer precision. Includes AQI based on different national/regional local standards, AQI level, color, main pollutants, QWeather universal AQI, pollutant concentrations, sub-indices, health recommendations, and related monitoring station information.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"get-air-quality-hourly": {
+		title:       "Hourly Air Quality Forecast",
+		description: "Hourly air quality forecast API provides air quality data for the next 24 hours, including AQI, pollutant concentrations, sub-indices, and health recommendations. Data includes various air quality standards (such as QAQI, GB-DEFRA, etc.) and specific concentrations of pollutants like PM2.5, PM10, NO2, O3, SO2, etc.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"get-air-quality-daily": {
+		title:       "Daily Air Quality Forecast",
+		description: "Daily air quality forecast API provides air quality predictions for the next 3 days, including AQI values, pollutant concentrations, and health recommendations. Data includes various air quality standards and specific concentrations of pollutants such as PM2.5, PM10, NO2, O3, SO2, etc.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"get-station-air-quality": {
+		title:       "Monitoring Station Air Quality",
+		description: "Monitoring station air quality API provides pollutant concentrations (PM2.5, PM10, NO2, O3, SO2, CO, etc.) measured by the air quality monitoring stations related to a city. Query a single station by ID, or all stations around the city together with a comparison of the lowest, highest and average readings across stations.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"get-weather-indices": {
+		title: "Weather Life Indices",
+		description: "Weather life indices forecast API provides various life indices for cities worldwide. Supports 1-day and 3-day forecasts. Available index types:\n\n" +
+			"- Type 0: All index types\n" +
+			"- Type 1: Sports (indicates suitability for outdoor sports activities)\n" +
+			"- Type 2: Car Washing (suggests whether it's suitable to wash cars)\n" +
+			"- Type 3: Dressing (provides clothing suggestions based on weather)\n" +
+			"- Type 4: Fishing (shows suitability of fishing conditions)\n" +
+			"- Type 5: UV (ultraviolet radiation intensity level)\n" +
+			"- Type 6: Travel (indicates suitability for travel and sightseeing)\n" +
+			"- Type 7: Allergy (allergy and pollen risk level)\n" +
+			"- Type 8: Cold (cold risk level)\n" +
+			"- Type 9: Comfort (overall comfort level of weather)\n" +
+			"- Type 10: Wind (wind conditions and their effects)\n" +
+			"- Type 11: Sunglasses (need for wearing sunglasses)\n" +
+			"- Type 12: Makeup (weather effects on makeup)\n" +
+			"- Type 13: Sunscreen (sunscreen needs)\n" +
+			"- Type 14: Traffic (weather effects on traffic conditions)\n" +
+			"- Type 15: Sports Watching (suitability for watching outdoor sports)\n" +
+			"- Type 16: Air Pollution Diffusion Conditions (air pollution diffusion conditions)\n\n" +
+			"Note: Not all cities provide all indices. International cities mainly support types 1, 2, 4, and 5.",
+		readOnly:   true,
+		idempotent: true,
+		openWorld:  true,
+	},
+	"compare-locations": {
+		title:       "Compare Locations",
+		description: "Compare weather across multiple cities in a single call. Fetches current conditions, daily forecast (3 or 7 days) and real-time air quality for up to 10 cities concurrently, and returns side-by-side tables with rankings (warmest, driest, cleanest air, etc.). Locations that cannot be resolved or whose data is unavailable are reported without failing the whole comparison.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"get-weather-briefing": {
+		title:       "Weather Briefing",
+		description: "Weather briefing API answers \"what's it like in X today\" in a single call. Resolves the city once and fetches in parallel: current weather, today's and tomorrow's forecast, active weather warnings, real-time air quality and key life indices (sports, dressing, UV). Sections whose data is unavailable are marked as such instead of failing the briefing.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"get-route-weather": {
+		title:       "Weather Along a Route",
+		description: "Route weather API provides the weather a traveller will meet along a route rather than at the departure point. Takes ordered waypoints (city names or longitude,latitude coordinates) with a departure time and per-leg travel times or arrival times, picks the hourly forecast closest to each arrival time, and flags legs with rain, snow, low visibility, strong wind or active weather warnings. Arrival times up to 7 days ahead are supported.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"find-activity-windows": {
+		title:       "Find Activity Windows",
+		description: "Activity window finder searches the hourly forecast (up to 7 days) for time windows that meet user constraints, such as a temperature range, no precipitation, wind below a Beaufort force level and AQI below a threshold, with a minimum duration. Returns ranked candidate windows (longest first) with the reasons each window qualifies. Useful for questions like \"when can I go running this week\".",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"check-weather-condition": {
+		title:       "Check Weather Condition",
+		description: "Answers yes/no questions about the forecast with a structured answer: whether the condition occurs, first occurrence time, duration and the data source used. Supported conditions: precipitation, snow, temperature below/above a threshold and wind force above a Beaufort level. Uses the minutely nowcast for precipitation in the next 2 hours, the hourly forecast up to 7 days and the daily forecast up to 15 days. Examples: will it rain in the next 2 hours, will it drop below 0°C tonight, will wind exceed force 6 tomorrow.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   true,
+	},
+	"get-forecast-changes": {
+		title:       "Forecast Changes",
+		description: "Forecast change detection records a snapshot of the 7-day daily and 168-hour hourly forecast for a location on every call, and reports what changed since the previous snapshot or since a given time: newly forecast or no longer forecast rain, temperature shifts beyond a threshold and changed weather text. Changes are grouped by forecast date and marked as worse, better or changed. Snapshots are only taken when this tool is called: the first call for a location has nothing to compare with, and changes are reported since the previous call. Useful for questions like \"did the forecast for Saturday get worse?\".",
+		readOnly:    false,
+		idempotent:  false,
+		openWorld:   true,
+	},
+	"query-recorded-history": {
+		title:       "Recorded Weather History",
+		description: "Recorded history query returns aggregates of the current weather and air quality recorded locally by this server for its configured locations, over arbitrary time ranges of up to a year: temperature, humidity, wind speed and pressure min/max/mean, precipitation totals and AQI statistics, for the whole range or per day. Useful for conditions older than the short historical window offered by QWeather.",
+		readOnly:    true,
+		idempotent:  true,
+		openWorld:   false,
+	},
+}
+
+// ToolNames returns the names of all tools, sorted
+func ToolNames() []string {
+	names := make([]string, 0, len(toolCatalog))
+	for name := range toolCatalog {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// catalogTool builds the declaration of a catalogued tool, with annotations and an explicit
+// output schema for its output type. It panics on unknown names, like mcp.AddTool does on
+// invalid tools, since both are programming errors.
+func catalogTool[Out any](name string) *mcp.Tool {
+	info, ok := toolCatalog[name]
+	if !ok {
+		panic(fmt.Sprintf("tool %q is not in the tool catalog", name))
+	}
+	outputSchema, err := jsonschema.For[Out](nil)
+	if err != nil {
+		panic(fmt.Sprintf("failed to build output schema of tool %q: %v", name, err))
+	}
+	destructive := false
+	openWorld := info.openWorld
+	return &mcp.Tool{
+		Name:        name,
+		Title:       info.title,
+		Description: info.description,
+		Annotations: &mcp.ToolAnnotations{
+			Title:           info.title,
+			ReadOnlyHint:    info.readOnly,
+			IdempotentHint:  info.idempotent,
+			DestructiveHint: &destructive,
+			OpenWorldHint:   &openWorld,
+		},
+		OutputSchema: outputSchema,
+	}
+}
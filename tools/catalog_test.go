@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+	"github.com/overstarry/qweather-mcp-go/recorder"
+	"github.com/overstarry/qweather-mcp-go/snapshot"
+)
+
+func TestToolCatalog(t *testing.T) {
+	history, err := recorder.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	client := api.NewClient("http://localhost", "test-key")
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	RegisterWeatherTools(server, client)
+	RegisterAirQualityTools(server, client)
+	RegisterIndicesTools(server, client)
+	RegisterCompareTools(server, client)
+	RegisterBriefingTools(server, client)
+	RegisterRouteTools(server, client)
+	RegisterActivityTools(server, client)
+	RegisterConditionTools(server, client)
+	RegisterForecastChangeTools(server, client, snapshot.NewMemoryStore(0))
+	RegisterHistoryTools(server, client, history)
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server Connect failed: %v", err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect failed: %v", err)
+	}
+	defer session.Close()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(tools.Tools) != len(toolCatalog) {
+		t.Fatalf("listed %d tools, catalog has %d", len(tools.Tools), len(toolCatalog))
+	}
+	for _, tool := range tools.Tools {
+		info, ok := toolCatalog[tool.Name]
+		if !ok {
+			t.Errorf("tool %s is not in the catalog", tool.Name)
+			continue
+		}
+		if tool.Title == "" || tool.Description != info.description {
+			t.Errorf("tool %s: title %q, description not from catalog", tool.Name, tool.Title)
+		}
+		if tool.Annotations == nil || tool.Annotations.ReadOnlyHint != info.readOnly ||
+			tool.Annotations.OpenWorldHint == nil || *tool.Annotations.OpenWorldHint != info.openWorld {
+			t.Errorf("tool %s: annotations %+v do not match the catalog", tool.Name, tool.Annotations)
+		}
+		schema, _ := tool.OutputSchema.(map[string]any)
+		if properties, _ := schema["properties"].(map[string]any); len(properties) == 0 {
+			t.Errorf("tool %s: output schema %v has no properties", tool.Name, tool.OutputSchema)
+		}
+	}
+
+	// Only the tool storing forecast snapshots changes state
+	for name, info := range toolCatalog {
+		if info.readOnly != (name != "get-forecast-changes") {
+			t.Errorf("tool %s: readOnly = %v", name, info.readOnly)
+		}
+	}
+}
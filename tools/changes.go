@@ -0,0 +1,305 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+	"github.com/overstarry/qweather-mcp-go/snapshot"
+)
+
+// Defaults for the get-forecast-changes tool
+const (
+	defaultTempShift      = 3.0 // Minimum temperature change in °C that is reported
+	maxHourlyChangesShown = 6   // Hourly changes listed per day before the rest are summarized
+)
+
+// ForecastChangesInput input parameters for get-forecast-changes tool
+type ForecastChangesInput struct {
+	CityName      string   `json:"cityName" jsonschema:"Name of the city to check forecast changes for"`
+	Since         string   `json:"since,omitempty" jsonschema:"Compare with the latest snapshot taken at or before this time, in RFC3339 format (e.g. 2024-05-01T08:00:00+08:00). Defaults to the previous snapshot."`
+	Date          string   `json:"date,omitempty" jsonschema:"Only report changes for this forecast date (YYYY-MM-DD), e.g. the coming Saturday"`
+	TempThreshold *float64 `json:"tempThreshold,omitempty" jsonschema:"Minimum temperature shift in °C to report. Defaults to 3."`
+}
+
+// ForecastChangesOutput output structure for get-forecast-changes tool
+type ForecastChangesOutput struct {
+	ChangesInfo string `json:"changesInfo" jsonschema:"Changes between the current forecast and the earlier snapshot, grouped by forecast date"`
+}
+
+// dayChanges the changes found for one forecast date
+type dayChanges struct {
+	date    string
+	verdict string // worse, better or changed
+	daily   []string
+	hourly  []string
+}
+
+// isWet reports whether an icon or precipitation amount means rain or snow
+func isWet(icon, precip string) bool {
+	if group := iconGroup(icon); group == iconGroupRain || group == iconGroupSnow {
+		return true
+	}
+	amount, _ := strconv.ParseFloat(precip, 64)
+	return amount > 0
+}
+
+// tempShift describes a temperature change at or beyond the threshold, or returns "" if there is none
+func tempShift(label, before, after string, threshold float64) string {
+	b, errB := strconv.ParseFloat(before, 64)
+	a, errA := strconv.ParseFloat(after, 64)
+	if errB != nil || errA != nil || math.Abs(a-b) < threshold {
+		return ""
+	}
+	return fmt.Sprintf("%s %s°C → %s°C (%+.0f°C)", label, before, after, a-b)
+}
+
+// rainChange describes rain appearing in or disappearing from the forecast.
+// It returns the verdict the change implies, or "" if the rain outlook is unchanged.
+func rainChange(wetBefore, wetAfter bool, textBefore, textAfter, precipBefore, precipAfter string) (string, string) {
+	switch {
+	case !wetBefore && wetAfter:
+		return fmt.Sprintf("rain now forecast: %s → %s, precipitation %smm → %smm", textBefore, textAfter, precipBefore, precipAfter), "worse"
+	case wetBefore && !wetAfter:
+		return fmt.Sprintf("rain no longer forecast: %s → %s, precipitation %smm → %smm", textBefore, textAfter, precipBefore, precipAfter), "better"
+	}
+	return "", ""
+}
+
+// diffForecasts compares two snapshots date by date, in the order of the current forecast.
+// Only dates present in both snapshots are compared; date restricts the result to a single day.
+func diffForecasts(before, after *snapshot.Snapshot, threshold float64, date string) []*dayChanges {
+	var days []*dayChanges
+	byDate := make(map[string]*dayChanges)
+	day := func(d string) *dayChanges {
+		if c, ok := byDate[d]; ok {
+			return c
+		}
+		c := &dayChanges{date: d}
+		days = append(days, c)
+		byDate[d] = c
+		return c
+	}
+	verdict := func(c *dayChanges, v string) {
+		// A change for the worse outweighs one for the better on the same day
+		switch {
+		case v == "worse" || c.verdict == "worse":
+			c.verdict = "worse"
+		case v == "better" || c.verdict == "better":
+			c.verdict = "better"
+		default:
+			c.verdict = "changed"
+		}
+	}
+
+	if before.Daily != nil && after.Daily != nil {
+		previous := make(map[string]int, len(before.Daily.Daily))
+		for i, d := range before.Daily.Daily {
+			previous[d.FxDate] = i
+		}
+		for _, cur := range after.Daily.Daily {
+			i, ok := previous[cur.FxDate]
+			if !ok || (date != "" && cur.FxDate != date) {
+				continue
+			}
+			prev := before.Daily.Daily[i]
+
+			var changes []string
+			rain, v := rainChange(
+				isWet(prev.IconDay, prev.Precip) || isWet(prev.IconNight, "0"),
+				isWet(cur.IconDay, cur.Precip) || isWet(cur.IconNight, "0"),
+				prev.TextDay+"/"+prev.TextNight, cur.TextDay+"/"+cur.TextNight,
+				prev.Precip, cur.Precip)
+			if rain != "" {
+				changes = append(changes, rain)
+			} else if prev.TextDay != cur.TextDay || prev.TextNight != cur.TextNight {
+				changes = append(changes, fmt.Sprintf("weather changed: %s/%s → %s/%s", prev.TextDay, prev.TextNight, cur.TextDay, cur.TextNight))
+			}
+			if shift := tempShift("max temperature", prev.TempMax, cur.TempMax, threshold); shift != "" {
+				changes = append(changes, shift)
+			}
+			if shift := tempShift("min temperature", prev.TempMin, cur.TempMin, threshold); shift != "" {
+				changes = append(changes, shift)
+			}
+			if len(changes) > 0 {
+				c := day(cur.FxDate)
+				c.daily = append(c.daily, changes...)
+				verdict(c, v)
+			}
+		}
+	}
+
+	if before.Hourly != nil && after.Hourly != nil {
+		previous := make(map[int64]int, len(before.Hourly.Hourly))
+		for i, h := range before.Hourly.Hourly {
+			if t, err := parseForecastTime(h.FxTime); err == nil {
+				previous[t.Unix()] = i
+			}
+		}
+		for _, cur := range after.Hourly.Hourly {
+			t, err := parseForecastTime(cur.FxTime)
+			if err != nil {
+				continue
+			}
+			i, ok := previous[t.Unix()]
+			d := t.Format("2006-01-02")
+			if !ok || (date != "" && d != date) {
+				continue
+			}
+			prev := before.Hourly.Hourly[i]
+
+			var changes []string
+			rain, v := rainChange(isWet(prev.Icon, prev.Precip), isWet(cur.Icon, cur.Precip), prev.Text, cur.Text, prev.Precip, cur.Precip)
+			if rain != "" {
+				changes = append(changes, rain)
+			} else if prev.Text != cur.Text {
+				changes = append(changes, fmt.Sprintf("weather changed: %s → %s", prev.Text, cur.Text))
+			}
+			if shift := tempShift("temperature", prev.Temp, cur.Temp, threshold); shift != "" {
+				changes = append(changes, shift)
+			}
+			if len(changes) > 0 {
+				c := day(d)
+				c.hourly = append(c.hourly, fmt.Sprintf("%s %s", t.Format("15:04"), strings.Join(changes, "; ")))
+				verdict(c, v)
+			}
+		}
+	}
+
+	return days
+}
+
+// formatDayChanges renders the changes of one forecast date
+func formatDayChanges(c *dayChanges) string {
+	heading := c.date
+	if t, err := time.Parse("2006-01-02", c.date); err == nil {
+		heading = t.Format("Mon 2006-01-02")
+	}
+	lines := []string{fmt.Sprintf("%s: %s", heading, c.verdict)}
+	for _, change := range c.daily {
+		lines = append(lines, "  - "+change)
+	}
+	if len(c.hourly) > 0 {
+		lines = append(lines, "  Hourly:")
+		for i, change := range c.hourly {
+			if i == maxHourlyChangesShown {
+				lines = append(lines, fmt.Sprintf("  ... and %d more hourly changes", len(c.hourly)-maxHourlyChangesShown))
+				break
+			}
+			lines = append(lines, "  - "+change)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func handleForecastChanges(ctx context.Context, client *api.Client, store snapshot.Store, input ForecastChangesInput) (ForecastChangesOutput, error) {
+	if input.CityName == "" {
+		return ForecastChangesOutput{}, fmt.Errorf("city name cannot be empty")
+	}
+
+	now := time.Now()
+	since := now
+	if input.Since != "" {
+		t, err := time.Parse(time.RFC3339, input.Since)
+		if err != nil {
+			return ForecastChangesOutput{}, fmt.Errorf("invalid since: must be RFC3339 (e.g. 2024-05-01T08:00:00+08:00)")
+		}
+		since = t
+	}
+	if input.Date != "" {
+		if _, err := time.Parse("2006-01-02", input.Date); err != nil {
+			return ForecastChangesOutput{}, fmt.Errorf("invalid date: must be YYYY-MM-DD")
+		}
+	}
+	threshold := defaultTempShift
+	if input.TempThreshold != nil {
+		if *input.TempThreshold <= 0 {
+			return ForecastChangesOutput{}, fmt.Errorf("tempThreshold must be positive")
+		}
+		threshold = *input.TempThreshold
+	}
+
+	location, err := resolveLocation(ctx, client, input.CityName)
+	if err != nil {
+		return ForecastChangesOutput{}, err
+	}
+
+	daily, err := client.GetWeatherForecast(ctx, location.ID, "7d")
+	if err == nil {
+		err = checkCode(daily.Code)
+	}
+	if err != nil {
+		return ForecastChangesOutput{}, fmt.Errorf("failed to get weather forecast data: %w", err)
+	}
+	hourly, err := client.GetHourlyForecast(ctx, location.ID, "168h")
+	if err == nil {
+		err = checkCode(hourly.Code)
+	}
+	if err != nil {
+		return ForecastChangesOutput{}, fmt.Errorf("failed to get hourly weather forecast data: %w", err)
+	}
+
+	previous, err := store.Latest(location.ID, since)
+	if err != nil {
+		return ForecastChangesOutput{}, fmt.Errorf("failed to load forecast snapshot: %w", err)
+	}
+
+	current := &snapshot.Snapshot{LocationID: location.ID, Location: location.Name, TakenAt: now, Daily: daily, Hourly: hourly}
+	changesText := []string{
+		fmt.Sprintf("Forecast Changes - %s (%s %s):", location.Name, location.Adm1, location.Adm2),
+	}
+	if err := store.Save(current); err != nil {
+		changesText = append(changesText, fmt.Sprintf("Note: failed to record the current forecast (%v)", err))
+	}
+
+	if previous == nil {
+		if input.Since != "" {
+			changesText = append(changesText, fmt.Sprintf("No forecast snapshot was taken at or before %s.", since.Format("2006-01-02 15:04 MST")))
+		} else {
+			changesText = append(changesText, "No earlier forecast snapshot exists for this location.")
+		}
+		changesText = append(changesText, "The current forecast has been recorded; call this tool again later to see what changed.")
+		return ForecastChangesOutput{ChangesInfo: strings.Join(changesText, "\n")}, nil
+	}
+
+	changesText = append(changesText,
+		fmt.Sprintf("Compared with the snapshot taken %s:", previous.TakenAt.Format("2006-01-02 15:04 MST")),
+		"")
+
+	days := diffForecasts(previous, current, threshold, input.Date)
+	if len(days) == 0 {
+		if input.Date != "" {
+			changesText = append(changesText, fmt.Sprintf("No significant changes for %s", input.Date))
+		} else {
+			changesText = append(changesText, "No significant changes")
+		}
+		return ForecastChangesOutput{ChangesInfo: strings.Join(changesText, "\n")}, nil
+	}
+	for _, day := range days {
+		changesText = append(changesText, formatDayChanges(day))
+	}
+
+	return ForecastChangesOutput{ChangesInfo: strings.Join(changesText, "\n")}, nil
+}
+
+// RegisterForecastChangeTools Register forecast change detection tools
+func RegisterForecastChangeTools(s *mcp.Server, client *api.Client, store snapshot.Store) {
+	// Forecast change detection tool
+	mcp.AddTool(s, catalogTool[ForecastChangesOutput]("get-forecast-changes"), func(ctx context.Context, req *mcp.CallToolRequest, input ForecastChangesInput) (*mcp.CallToolResult, ForecastChangesOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, ForecastChangesOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleForecastChanges(ctx, client, store, input)
+		if err != nil {
+			return nil, ForecastChangesOutput{}, err
+		}
+		return nil, out, nil
+	})
+}
@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+	"github.com/overstarry/qweather-mcp-go/snapshot"
+)
+
+// Forecasts for Saturday 2024-05-04 before and after the outlook turned rainy
+var (
+	dailyBefore  = `{"code":"200","daily":[{"fxDate":"2024-05-03","tempMax":"24","tempMin":"12","iconDay":"100","textDay":"Sunny","iconNight":"150","textNight":"Clear","precip":"0.0"},{"fxDate":"2024-05-04","tempMax":"25","tempMin":"13","iconDay":"100","textDay":"Sunny","iconNight":"150","textNight":"Clear","precip":"0.0"}]}`
+	dailyAfter   = `{"code":"200","daily":[{"fxDate":"2024-05-03","tempMax":"23","tempMin":"12","iconDay":"100","textDay":"Sunny","iconNight":"150","textNight":"Clear","precip":"0.0"},{"fxDate":"2024-05-04","tempMax":"20","tempMin":"12","iconDay":"305","textDay":"Light Rain","iconNight":"151","textNight":"Cloudy","precip":"5.2"}]}`
+	hourlyBefore = `{"code":"200","hourly":[{"fxTime":"2024-05-04T14:00+08:00","temp":"24","icon":"100","text":"Sunny","precip":"0.0"}]}`
+	hourlyAfter  = `{"code":"200","hourly":[{"fxTime":"2024-05-04T14:00+08:00","temp":"19","icon":"305","text":"Light Rain","precip":"1.2"}]}`
+)
+
+// setupChangesMockServer serves the earlier forecasts until updated is set
+func setupChangesMockServer(updated *atomic.Bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			json.NewEncoder(w).Encode(api.LocationResponse{
+				Code: "200",
+				Location: []api.Location{{
+					Name: "Beijing", ID: "101010100", Lat: "39.90", Lon: "116.41", Adm1: "Beijing", Adm2: "Beijing",
+				}},
+			})
+		case "/v7/weather/7d":
+			if updated.Load() {
+				w.Write([]byte(dailyAfter))
+			} else {
+				w.Write([]byte(dailyBefore))
+			}
+		case "/v7/weather/168h":
+			if updated.Load() {
+				w.Write([]byte(hourlyAfter))
+			} else {
+				w.Write([]byte(hourlyBefore))
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestHandleForecastChanges(t *testing.T) {
+	var updated atomic.Bool
+	server := setupChangesMockServer(&updated)
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "test-key")
+	store := snapshot.NewMemoryStore(0)
+
+	out, err := handleForecastChanges(context.Background(), client, store, ForecastChangesInput{CityName: "Beijing"})
+	if err != nil {
+		t.Fatalf("handleForecastChanges failed: %v", err)
+	}
+	if !strings.Contains(out.ChangesInfo, "No earlier forecast snapshot") {
+		t.Fatalf("ChangesInfo = %q, want a note that the baseline was recorded", out.ChangesInfo)
+	}
+
+	updated.Store(true)
+	out, err = handleForecastChanges(context.Background(), client, store, ForecastChangesInput{CityName: "Beijing", Date: "2024-05-04"})
+	if err != nil {
+		t.Fatalf("handleForecastChanges failed: %v", err)
+	}
+	for _, want := range []string{
+		"Sat 2024-05-04: worse",
+		"rain now forecast: Sunny/Clear → Light Rain/Cloudy, precipitation 0.0mm → 5.2mm",
+		"max temperature 25°C → 20°C (-5°C)",
+		"14:00 rain now forecast: Sunny → Light Rain, precipitation 0.0mm → 1.2mm; temperature 24°C → 19°C (-5°C)",
+	} {
+		if !strings.Contains(out.ChangesInfo, want) {
+			t.Fatalf("ChangesInfo = %q, want to contain %q", out.ChangesInfo, want)
+		}
+	}
+	if strings.Contains(out.ChangesInfo, "2024-05-03") {
+		t.Fatalf("ChangesInfo = %q, want only changes for the requested date", out.ChangesInfo)
+	}
+
+	// The second call recorded the new forecast, so nothing changed since then
+	out, err = handleForecastChanges(context.Background(), client, store, ForecastChangesInput{CityName: "Beijing"})
+	if err != nil {
+		t.Fatalf("handleForecastChanges failed: %v", err)
+	}
+	if !strings.Contains(out.ChangesInfo, "No significant changes") {
+		t.Fatalf("ChangesInfo = %q, want no changes against the latest snapshot", out.ChangesInfo)
+	}
+}
+
+func TestHandleForecastChanges_Validation(t *testing.T) {
+	client := api.NewClient("http://localhost", "test-key")
+	store := snapshot.NewMemoryStore(0)
+	negative := -1.0
+	for _, input := range []ForecastChangesInput{
+		{},
+		{CityName: "Beijing", Since: "yesterday"},
+		{CityName: "Beijing", Date: "Saturday"},
+		{CityName: "Beijing", TempThreshold: &negative},
+	} {
+		if _, err := handleForecastChanges(context.Background(), client, store, input); err == nil {
+			t.Errorf("handleForecastChanges(%+v) succeeded, want error", input)
+		}
+	}
+}
+
+func TestDiffForecasts_Better(t *testing.T) {
+	var before, after api.WeatherDailyResponse
+	json.Unmarshal([]byte(dailyAfter), &before)
+	json.Unmarshal([]byte(dailyBefore), &after)
+
+	days := diffForecasts(&snapshot.Snapshot{Daily: &before, TakenAt: time.Now()}, &snapshot.Snapshot{Daily: &after}, defaultTempShift, "")
+	if len(days) != 1 || days[0].date != "2024-05-04" || days[0].verdict != "better" {
+		t.Fatalf("diffForecasts = %+v, want Saturday to be better", days)
+	}
+}
@@ -0,0 +1,363 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// Limits for the compare-locations tool
+const (
+	maxCompareLocations     = 10 // Maximum number of locations in a single comparison
+	compareFetchParallelism = 4  // Maximum number of locations fetched concurrently
+)
+
+// Metrics supported by the compare-locations tool
+const (
+	compareMetricNow      = "now"
+	compareMetricForecast = "forecast"
+	compareMetricAQI      = "aqi"
+)
+
+// CompareLocationsInput input parameters for compare-locations tool
+type CompareLocationsInput struct {
+	Locations []string `json:"locations" jsonschema:"Names of the cities to compare (2 to 10), e.g. [\"Beijing\", \"Shanghai\", \"Shenzhen\"]"`
+	Metrics   []string `json:"metrics,omitempty" jsonschema:"Metrics to compare: now (current conditions), forecast (daily forecast), aqi (real-time air quality). Defaults to all three."`
+	Days      string   `json:"days,omitempty" jsonschema:"Number of forecast days for the forecast metric. Valid values: 3d or 7d. Defaults to 3d."`
+}
+
+// CompareLocationsOutput output structure for compare-locations tool
+type CompareLocationsOutput struct {
+	ComparisonInfo string `json:"comparisonInfo" jsonschema:"Side-by-side comparison tables with rankings for each requested metric"`
+}
+
+// locationComparison data fetched for one location; each metric keeps its own error so
+// a failure for one location or metric does not fail the whole comparison
+type locationComparison struct {
+	query       string
+	location    *api.Location
+	err         error
+	now         *api.WeatherNowResponse
+	nowErr      error
+	forecast    *api.WeatherDailyResponse
+	forecastErr error
+	aqi         *aqiReading
+	aqiErr      error
+}
+
+// aqiReading the index selected for comparison from a real-time air quality response
+type aqiReading struct {
+	name     string
+	aqi      int
+	category string
+}
+
+// label returns the display name of the compared location
+func (c *locationComparison) label() string {
+	if c.location == nil {
+		return c.query
+	}
+	return fmt.Sprintf("%s (%s)", c.location.Name, c.location.Adm1)
+}
+
+func handleCompareLocations(ctx context.Context, client *api.Client, input CompareLocationsInput, progress *progressReporter) (CompareLocationsOutput, error) {
+	if len(input.Locations) < 2 {
+		return CompareLocationsOutput{}, fmt.Errorf("at least two locations are required for a comparison")
+	}
+	if len(input.Locations) > maxCompareLocations {
+		return CompareLocationsOutput{}, fmt.Errorf("too many locations: at most %d can be compared at once", maxCompareLocations)
+	}
+	for _, name := range input.Locations {
+		if name == "" {
+			return CompareLocationsOutput{}, fmt.Errorf("city name cannot be empty")
+		}
+	}
+
+	if len(input.Metrics) == 0 {
+		input.Metrics = []string{compareMetricNow, compareMetricForecast, compareMetricAQI}
+	}
+	metrics := make(map[string]bool)
+	for _, metric := range input.Metrics {
+		switch metric {
+		case compareMetricNow, compareMetricForecast, compareMetricAQI:
+			metrics[metric] = true
+		default:
+			return CompareLocationsOutput{}, fmt.Errorf("invalid metric %q: must be one of now, forecast, aqi", metric)
+		}
+	}
+
+	if input.Days == "" {
+		input.Days = "3d"
+	}
+	if input.Days != "3d" && input.Days != "7d" {
+		return CompareLocationsOutput{}, fmt.Errorf("invalid days parameter: must be one of 3d, 7d")
+	}
+
+	// One step per location, then formatting
+	progress.setTotal(len(input.Locations) + 1)
+	results := make([]*locationComparison, len(input.Locations))
+	runParallel(len(input.Locations), compareFetchParallelism, func(i int) {
+		results[i] = fetchLocationComparison(ctx, client, input.Locations[i], metrics, input.Days)
+		progress.fetched("locations", len(input.Locations))
+	})
+
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+		}
+	}
+	if failed == len(results) {
+		return CompareLocationsOutput{}, fmt.Errorf("failed to compare locations: none of the locations could be resolved (first error: %v)", results[0].err)
+	}
+
+	progress.step("formatting")
+	comparisonText := []string{
+		fmt.Sprintf("Location Comparison - %d locations:", len(results)),
+	}
+	for _, r := range results {
+		if r.err != nil {
+			comparisonText = append(comparisonText, fmt.Sprintf("- %s: unavailable (%v)", r.query, r.err))
+		}
+	}
+
+	if metrics[compareMetricNow] {
+		comparisonText = append(comparisonText, "", formatNowComparison(results))
+	}
+	if metrics[compareMetricForecast] {
+		comparisonText = append(comparisonText, "", formatForecastComparison(results, input.Days))
+	}
+	if metrics[compareMetricAQI] {
+		comparisonText = append(comparisonText, "", formatAQIComparison(results))
+	}
+
+	return CompareLocationsOutput{ComparisonInfo: strings.Join(comparisonText, "\n")}, nil
+}
+
+// fetchLocationComparison resolves one location and fetches the requested metrics for it
+func fetchLocationComparison(ctx context.Context, client *api.Client, name string, metrics map[string]bool, days string) *locationComparison {
+	result := &locationComparison{query: name}
+
+	result.location, result.err = resolveLocation(ctx, client, name)
+	if result.err != nil {
+		return result
+	}
+
+	if metrics[compareMetricNow] {
+		result.now, result.nowErr = client.GetWeatherNow(ctx, result.location.ID)
+		if result.nowErr == nil {
+			result.nowErr = checkCode(result.now.Code)
+		}
+	}
+
+	if metrics[compareMetricForecast] {
+		result.forecast, result.forecastErr = client.GetWeatherForecast(ctx, result.location.ID, days)
+		if result.forecastErr == nil {
+			result.forecastErr = checkCode(result.forecast.Code)
+		}
+	}
+
+	if metrics[compareMetricAQI] {
+		result.aqi, result.aqiErr = fetchComparableAQI(ctx, client, result.location)
+	}
+
+	return result
+}
+
+// fetchComparableAQI fetches real-time air quality and picks the index used for comparison.
+// The QWeather universal AQI is preferred because local standards are not comparable across countries.
+func fetchComparableAQI(ctx context.Context, client *api.Client, location *api.Location) (*aqiReading, error) {
+	lat, lon := location.Coordinates()
+	airQualityData, err := client.GetAirQuality(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	index, ok := selectAQIIndex(airQualityData.Indexes, "")
+	if !ok {
+		return nil, fmt.Errorf("no air quality indexes found")
+	}
+	return &aqiReading{name: index.Name, aqi: index.Aqi, category: index.Category}, nil
+}
+
+// rankedValue a location label with the numeric value it is ranked by
+type rankedValue struct {
+	label string
+	value float64
+	text  string
+}
+
+// formatRanking renders a numbered ranking; ascending sorts lowest first
+func formatRanking(title string, values []rankedValue, ascending bool) string {
+	if len(values) == 0 {
+		return fmt.Sprintf("%s: not enough data", title)
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		if ascending {
+			return values[i].value < values[j].value
+		}
+		return values[i].value > values[j].value
+	})
+	lines := []string{title + ":"}
+	for i, v := range values {
+		lines = append(lines, fmt.Sprintf("  %d. %s - %s", i+1, v.label, v.text))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatTableRow joins table cells with a column separator
+func formatTableRow(cells ...string) string {
+	return "| " + strings.Join(cells, " | ") + " |"
+}
+
+func formatNowComparison(results []*locationComparison) string {
+	lines := []string{
+		"Current Conditions:",
+		formatTableRow("Location", "Temperature", "Feels Like", "Condition", "Humidity", "Wind"),
+	}
+	var byTemp, byHumidity []rankedValue
+	for _, r := range results {
+		if r.err != nil {
+			continue
+		}
+		if r.nowErr != nil {
+			lines = append(lines, formatTableRow(r.label(), fmt.Sprintf("unavailable (%v)", r.nowErr), "-", "-", "-", "-"))
+			continue
+		}
+		now := r.now.Now
+		lines = append(lines, formatTableRow(
+			r.label(),
+			now.Temp+"°C",
+			now.FeelsLike+"°C",
+			now.Text,
+			now.Humidity+"%",
+			fmt.Sprintf("%s Force %s", now.WindDir, now.WindScale),
+		))
+		if temp, err := strconv.ParseFloat(now.Temp, 64); err == nil {
+			byTemp = append(byTemp, rankedValue{label: r.label(), value: temp, text: now.Temp + "°C"})
+		}
+		if humidity, err := strconv.ParseFloat(now.Humidity, 64); err == nil {
+			byHumidity = append(byHumidity, rankedValue{label: r.label(), value: humidity, text: now.Humidity + "%"})
+		}
+	}
+	lines = append(lines,
+		"",
+		formatRanking("Warmest to coldest", byTemp, false),
+		formatRanking("Driest to most humid", byHumidity, true),
+	)
+	return strings.Join(lines, "\n")
+}
+
+func formatForecastComparison(results []*locationComparison, days string) string {
+	lines := []string{
+		fmt.Sprintf("%s Day Forecast:", strings.TrimSuffix(days, "d")),
+	}
+
+	// Locations in different time zones may start on different dates, so the columns are every
+	// forecast date of any location, and rows are aligned to them by date
+	var dates []string
+	for _, r := range results {
+		if r.err == nil && r.forecastErr == nil {
+			for _, day := range r.forecast.Daily {
+				if !slices.Contains(dates, day.FxDate) {
+					dates = append(dates, day.FxDate)
+				}
+			}
+		}
+	}
+	slices.Sort(dates)
+	header := append([]string{"Location"}, dates...)
+	header = append(header, "Total Precipitation")
+	lines = append(lines, formatTableRow(header...))
+
+	var byPrecip, byHigh []rankedValue
+	for _, r := range results {
+		if r.err != nil {
+			continue
+		}
+		if r.forecastErr != nil {
+			lines = append(lines, formatTableRow(r.label(), fmt.Sprintf("unavailable (%v)", r.forecastErr)))
+			continue
+		}
+		cells := make(map[string]string, len(r.forecast.Daily))
+		var totalPrecip, sumHigh float64
+		highs := 0
+		for _, day := range r.forecast.Daily {
+			cells[day.FxDate] = fmt.Sprintf("%s~%s°C %s", day.TempMin, day.TempMax, day.TextDay)
+			if precip, err := strconv.ParseFloat(day.Precip, 64); err == nil {
+				totalPrecip += precip
+			}
+			if high, err := strconv.ParseFloat(day.TempMax, 64); err == nil {
+				sumHigh += high
+				highs++
+			}
+		}
+		row := []string{r.label()}
+		for _, date := range dates {
+			cell, ok := cells[date]
+			if !ok {
+				cell = "-"
+			}
+			row = append(row, cell)
+		}
+		row = append(row, fmt.Sprintf("%.1fmm", totalPrecip))
+		lines = append(lines, formatTableRow(row...))
+
+		byPrecip = append(byPrecip, rankedValue{label: r.label(), value: totalPrecip, text: fmt.Sprintf("%.1fmm", totalPrecip)})
+		if highs > 0 {
+			avgHigh := sumHigh / float64(highs)
+			byHigh = append(byHigh, rankedValue{label: r.label(), value: avgHigh, text: fmt.Sprintf("average high %.1f°C", avgHigh)})
+		}
+	}
+	lines = append(lines,
+		"",
+		formatRanking("Driest to wettest", byPrecip, true),
+		formatRanking("Warmest to coldest (average high)", byHigh, false),
+	)
+	return strings.Join(lines, "\n")
+}
+
+func formatAQIComparison(results []*locationComparison) string {
+	lines := []string{
+		"Air Quality:",
+		formatTableRow("Location", "Index", "AQI", "Category"),
+	}
+	var byAQI []rankedValue
+	for _, r := range results {
+		if r.err != nil {
+			continue
+		}
+		if r.aqiErr != nil {
+			lines = append(lines, formatTableRow(r.label(), fmt.Sprintf("unavailable (%v)", r.aqiErr), "-", "-"))
+			continue
+		}
+		lines = append(lines, formatTableRow(r.label(), r.aqi.name, strconv.Itoa(r.aqi.aqi), r.aqi.category))
+		byAQI = append(byAQI, rankedValue{label: r.label(), value: float64(r.aqi.aqi), text: fmt.Sprintf("%s %d", r.aqi.name, r.aqi.aqi)})
+	}
+	lines = append(lines, "", formatRanking("Cleanest to most polluted", byAQI, true))
+	return strings.Join(lines, "\n")
+}
+
+// RegisterCompareTools Register multi-location comparison tools
+func RegisterCompareTools(s *mcp.Server, client *api.Client) {
+	// Multi-location comparison tool
+	mcp.AddTool(s, catalogTool[CompareLocationsOutput]("compare-locations"), func(ctx context.Context, req *mcp.CallToolRequest, input CompareLocationsInput) (*mcp.CallToolResult, CompareLocationsOutput, error) {
+		for i, name := range input.Locations {
+			location, err := chooseLocation(ctx, req.Session, client, name)
+			if err != nil {
+				return nil, CompareLocationsOutput{}, err
+			}
+			input.Locations[i] = location
+		}
+		out, err := handleCompareLocations(ctx, client, input, newProgressReporter(ctx, req))
+		if err != nil {
+			return nil, CompareLocationsOutput{}, err
+		}
+		return nil, out, nil
+	})
+}
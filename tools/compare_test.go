@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// setupCompareMockServer creates a mock server knowing two cities; any other city is not found
+func setupCompareMockServer() *httptest.Server {
+	cities := map[string]api.Location{
+		"Beijing":  {Name: "Beijing", ID: "101010100", Lat: "39.90", Lon: "116.41", Adm1: "Beijing"},
+		"Shenzhen": {Name: "Shenzhen", ID: "101280601", Lat: "22.54", Lon: "114.06", Adm1: "Guangdong"},
+	}
+	temps := map[string]string{"101010100": "5", "101280601": "25"}
+	aqis := map[string]string{"39.90": "120", "22.54": "30"}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/geo/v2/city/lookup":
+			resp := api.LocationResponse{Code: "200"}
+			if loc, ok := cities[r.URL.Query().Get("location")]; ok {
+				resp.Location = []api.Location{loc}
+			}
+			json.NewEncoder(w).Encode(resp)
+		case r.URL.Path == "/v7/weather/now":
+			resp := api.WeatherNowResponse{Code: "200"}
+			resp.Now.Temp = temps[r.URL.Query().Get("location")]
+			resp.Now.Humidity = "50"
+			json.NewEncoder(w).Encode(resp)
+		case strings.HasPrefix(r.URL.Path, "/airquality/v1/current/"):
+			lat := strings.Split(strings.TrimPrefix(r.URL.Path, "/airquality/v1/current/"), "/")[0]
+			w.Write([]byte(`{"indexes":[{"code":"cn-mee","name":"AQI (CN)","aqi":99},{"code":"qaqi","name":"QAQI","aqi":` + aqis[lat] + `,"category":"Test"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestHandleCompareLocations_RanksAndToleratesFailures(t *testing.T) {
+	server := setupCompareMockServer()
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "test-key")
+	out, err := handleCompareLocations(context.Background(), client, CompareLocationsInput{
+		Locations: []string{"Beijing", "Shenzhen", "Atlantis"},
+		Metrics:   []string{"now", "aqi"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("handleCompareLocations failed: %v", err)
+	}
+	for _, want := range []string{
+		"- Atlantis: unavailable (no matching city found)",
+		"1. Shenzhen (Guangdong) - 25°C\n  2. Beijing (Beijing) - 5°C",
+		"| Beijing (Beijing) | QAQI | 120 | Test |",
+		"1. Shenzhen (Guangdong) - QAQI 30\n  2. Beijing (Beijing) - QAQI 120",
+	} {
+		if !strings.Contains(out.ComparisonInfo, want) {
+			t.Fatalf("ComparisonInfo = %q, want to contain %q", out.ComparisonInfo, want)
+		}
+	}
+	if strings.Contains(out.ComparisonInfo, "Day Forecast") {
+		t.Fatalf("ComparisonInfo contains forecast section although it was not requested: %q", out.ComparisonInfo)
+	}
+}
+
+func TestHandleCompareLocations_Validation(t *testing.T) {
+	client := api.NewClient("http://example.com", "test-key")
+	tests := []struct {
+		name  string
+		input CompareLocationsInput
+	}{
+		{"single location", CompareLocationsInput{Locations: []string{"Beijing"}}},
+		{"too many locations", CompareLocationsInput{Locations: make([]string, maxCompareLocations+1)}},
+		{"invalid metric", CompareLocationsInput{Locations: []string{"Beijing", "Shanghai"}, Metrics: []string{"uv"}}},
+		{"invalid days", CompareLocationsInput{Locations: []string{"Beijing", "Shanghai"}, Days: "15d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := handleCompareLocations(context.Background(), client, tt.input, nil); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestFormatForecastComparison_AlignsDates(t *testing.T) {
+	forecast := func(data string) *api.WeatherDailyResponse {
+		var resp api.WeatherDailyResponse
+		if err := json.Unmarshal([]byte(data), &resp); err != nil {
+			t.Fatalf("invalid forecast: %v", err)
+		}
+		return &resp
+	}
+	// Auckland is already a day ahead of London
+	results := []*locationComparison{
+		{location: &api.Location{Name: "London", Adm1: "England"}, forecast: forecast(`{"daily":[
+			{"fxDate":"2024-05-01","tempMin":"8","tempMax":"15","textDay":"Rain","precip":"2.0"},
+			{"fxDate":"2024-05-02","tempMin":"9","tempMax":"16","textDay":"Cloudy","precip":"0.0"}]}`)},
+		{location: &api.Location{Name: "Auckland", Adm1: "Auckland"}, forecast: forecast(`{"daily":[
+			{"fxDate":"2024-05-02","tempMin":"12","tempMax":"18","textDay":"Sunny","precip":"0.0"},
+			{"fxDate":"2024-05-03","tempMin":"13","tempMax":"19","textDay":"Showers","precip":"1.5"}]}`)},
+	}
+
+	got := formatForecastComparison(results, "3d")
+	for _, want := range []string{
+		"| Location | 2024-05-01 | 2024-05-02 | 2024-05-03 | Total Precipitation |",
+		"| London (England) | 8~15°C Rain | 9~16°C Cloudy | - | 2.0mm |",
+		"| Auckland (Auckland) | - | 12~18°C Sunny | 13~19°C Showers | 1.5mm |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("forecast comparison = %q, want to contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// DefaultCompletionCacheTTL how long geo lookups made for completion are cached
+const DefaultCompletionCacheTTL = time.Hour
+
+// Completion limits
+const (
+	maxCompletionValues = 100 // Protocol limit of values per completion response
+	minLookupLength     = 2   // Shorter city prefixes only complete from the popular cities
+	completionCacheSize = 512
+)
+
+// popularCity a city offered for completion without a geo lookup
+type popularCity struct {
+	name string
+	id   string
+}
+
+// popularCities cities completed before and in addition to geo lookup results
+var popularCities = []popularCity{
+	{"Beijing", "101010100"},
+	{"Shanghai", "101020100"},
+	{"Guangzhou", "101280101"},
+	{"Shenzhen", "101280601"},
+	{"Hangzhou", "101210101"},
+	{"Chengdu", "101270101"},
+	{"Chongqing", "101040100"},
+	{"Tianjin", "101030100"},
+	{"Wuhan", "101200101"},
+	{"Nanjing", "101190101"},
+	{"Xi'an", "101110101"},
+	{"Hong Kong", "101320101"},
+	{"Tokyo", "1850147"},
+	{"Singapore", "1880252"},
+	{"London", "2643743"},
+	{"Paris", "2988507"},
+	{"New York", "5128581"},
+	{"Sydney", "2147714"},
+}
+
+// Suggested values of free-form prompt arguments
+var (
+	sensitiveGroups = []string{"children", "elderly", "pregnancy", "asthma", "heart disease", "outdoor workers"}
+	activities      = []string{"hiking", "running", "cycling", "picnic", "wedding", "football match", "concert", "barbecue"}
+)
+
+// valueSet returns a lookup set of valid values
+func valueSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// cachedLookup geo lookup results kept for completion
+type cachedLookup struct {
+	locations []api.Location
+	expires   time.Time
+}
+
+// Completer answers completion requests for prompt and resource template arguments.
+// Its Complete method is meant to be used as the completion handler of mcp.ServerOptions.
+type Completer struct {
+	client *api.Client
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedLookup // Geo lookup results by lower-cased query
+}
+
+// NewCompleter creates a completer looking cities up with the given client
+func NewCompleter(client *api.Client) *Completer {
+	return &Completer{client: client, ttl: DefaultCompletionCacheTTL, cache: make(map[string]cachedLookup)}
+}
+
+// SetCacheTTL sets how long geo lookup results are cached
+func (c *Completer) SetCacheTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+}
+
+// CacheState size and lifetime of the geo lookup cache
+type CacheState struct {
+	Entries    int    `json:"entries"`
+	MaxEntries int    `json:"maxEntries"`
+	TTL        string `json:"ttl"`
+}
+
+// CacheState returns the state of the geo lookup cache
+func (c *Completer) CacheState() CacheState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheState{Entries: len(c.cache), MaxEntries: completionCacheSize, TTL: c.ttl.String()}
+}
+
+// Complete returns the values matching the partial argument of a completion request.
+// City arguments of prompts complete to city names, the id of resource templates to location
+// IDs, and enum-like arguments to their valid values.
+func (c *Completer) Complete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	if req.Params.Ref == nil {
+		return completionResult(nil), nil
+	}
+	arg := req.Params.Argument
+
+	var values []string
+	switch {
+	case req.Params.Ref.Type == "ref/prompt" && (arg.Name == "city" || arg.Name == "cityName"):
+		values = c.completeCity(ctx, arg.Value, func(name, _ string) string { return name })
+	case req.Params.Ref.Type == "ref/resource" && arg.Name == "id":
+		values = c.completeCity(ctx, arg.Value, func(_, id string) string { return id })
+	default:
+		values = completeEnum(enumValues(arg.Name, req.Params.Ref.URI), arg.Value)
+	}
+	return completionResult(values), nil
+}
+
+// completeCity matches popular cities and geo lookup results against a partial city name or ID,
+// returning the value picked from each name and ID without duplicates
+func (c *Completer) completeCity(ctx context.Context, value string, pick func(name, id string) string) []string {
+	value = strings.TrimSpace(value)
+	var values []string
+	add := func(name, id string) {
+		if v := pick(name, id); !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+
+	for _, city := range popularCities {
+		if hasFoldPrefix(city.name, value) || strings.HasPrefix(city.id, value) {
+			add(city.name, city.id)
+		}
+	}
+	if len([]rune(value)) >= minLookupLength {
+		for _, location := range c.lookup(ctx, value) {
+			add(location.Name, location.ID)
+		}
+	}
+	return values
+}
+
+// lookup queries the geo API once per query and TTL. Failed lookups are cached as empty
+// so that a client typing an unknown name does not repeat the request.
+func (c *Completer) lookup(ctx context.Context, query string) []api.Location {
+	key := strings.ToLower(query)
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.locations
+	}
+
+	var locations []api.Location
+	if locationData, err := c.client.GetLocationByName(ctx, query); err == nil {
+		locations = locationData.Location
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.cache) >= completionCacheSize {
+		for k, e := range c.cache {
+			if now.After(e.expires) {
+				delete(c.cache, k)
+			}
+		}
+		if len(c.cache) >= completionCacheSize {
+			clear(c.cache)
+		}
+	}
+	c.cache[key] = cachedLookup{locations: locations, expires: now.Add(c.ttl)}
+	return locations
+}
+
+// enumValues returns the valid values of an enum-like argument. The days of a resource depend
+// on its template: indices support 1d and 3d, forecasts the longer ones.
+func enumValues(name, uri string) []string {
+	switch name {
+	case "days":
+		if strings.Contains(uri, "/indices/") {
+			return indicesDays
+		}
+		return forecastDays
+	case "hours":
+		return hourlyHours
+	case "type":
+		return indicesTypes
+	case "format":
+		return []string{resourceFormatJSON, resourceFormatText}
+	case "sensitive_group":
+		return sensitiveGroups
+	case "activity":
+		return activities
+	}
+	return nil
+}
+
+// completeEnum returns the valid values starting with the partial value
+func completeEnum(valid []string, value string) []string {
+	var values []string
+	for _, v := range valid {
+		if hasFoldPrefix(v, value) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// completionResult builds a completion response within the protocol limit
+func completionResult(values []string) *mcp.CompleteResult {
+	result := &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: []string{}, Total: len(values)}}
+	if len(values) > maxCompletionValues {
+		values = values[:maxCompletionValues]
+		result.Completion.HasMore = true
+	}
+	result.Completion.Values = append(result.Completion.Values, values...)
+	return result
+}
+
+// hasFoldPrefix reports whether s starts with prefix, ignoring case
+func hasFoldPrefix(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+func TestCompleter(t *testing.T) {
+	lookups := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/geo/v2/city/lookup" {
+			http.NotFound(w, r)
+			return
+		}
+		lookups++
+		if !strings.EqualFold(r.URL.Query().Get("location"), "bei") {
+			w.Write([]byte(`{"code":"404","location":[]}`))
+			return
+		}
+		w.Write([]byte(`{"code":"200","location":[{"name":"Beijing","id":"101010100"},{"name":"Beihai","id":"101301301"}]}`))
+	}))
+	defer upstream.Close()
+
+	completer := NewCompleter(api.NewClient(upstream.URL, "test-key"))
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, &mcp.ServerOptions{CompletionHandler: completer.Complete})
+	RegisterResources(server, api.NewClient(upstream.URL, "test-key"))
+	RegisterPrompts(server)
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server Connect failed: %v", err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect failed: %v", err)
+	}
+	defer session.Close()
+
+	prompt := &mcp.CompleteReference{Type: "ref/prompt", Name: "daily-briefing"}
+	forecast := &mcp.CompleteReference{Type: "ref/resource", URI: "qweather://location/{id}/forecast/{days}{?format}"}
+	indices := &mcp.CompleteReference{Type: "ref/resource", URI: "qweather://location/{id}/indices/{days}{?type,format}"}
+	tests := []struct {
+		name  string
+		ref   *mcp.CompleteReference
+		arg   string
+		value string
+		want  []string
+	}{
+		{"popular cities only for short prefixes", prompt, "city", "s", []string{"Shanghai", "Shenzhen", "Singapore", "Sydney"}},
+		{"popular and looked up cities", prompt, "city", "bei", []string{"Beijing", "Beihai"}},
+		{"location IDs", forecast, "id", "Bei", []string{"101010100", "101301301"}},
+		{"unknown city", prompt, "city", "Atlantis", nil},
+		{"forecast days", forecast, "days", "1", []string{"10d", "15d"}},
+		{"indices days", indices, "days", "", []string{"1d", "3d"}},
+		{"indices type", indices, "type", "1", []string{"1", "10", "11", "12", "13", "14", "15", "16"}},
+		{"format", forecast, "format", "T", []string{"text"}},
+		{"sensitive group", &mcp.CompleteReference{Type: "ref/prompt", Name: "air-quality-health-advice"}, "sensitive_group", "a", []string{"asthma"}},
+		{"unknown argument", prompt, "mood", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := session.Complete(ctx, &mcp.CompleteParams{Ref: tt.ref, Argument: mcp.CompleteParamsArgument{Name: tt.arg, Value: tt.value}})
+			if err != nil {
+				t.Fatalf("Complete failed: %v", err)
+			}
+			if !slices.Equal(result.Completion.Values, tt.want) && len(result.Completion.Values)+len(tt.want) > 0 {
+				t.Errorf("Complete(%s=%q) = %v, want %v", tt.arg, tt.value, result.Completion.Values, tt.want)
+			}
+		})
+	}
+
+	// "bei" and "Bei" share a cache entry with the "Bei" lookup above
+	if lookups != 2 {
+		t.Errorf("lookups = %d, want geo lookups to be cached", lookups)
+	}
+}
@@ -0,0 +1,332 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// Conditions supported by the check-weather-condition tool
+const (
+	conditionPrecipitation = "precipitation"
+	conditionSnow          = "snow"
+	conditionTempBelow     = "temp-below"
+	conditionTempAbove     = "temp-above"
+	conditionWindAbove     = "wind-above"
+)
+
+// Data sources used to answer a condition, from most to least precise
+const (
+	conditionSourceMinutely = "minutely"
+	conditionSourceHourly   = "hourly"
+	conditionSourceDaily    = "daily"
+)
+
+// Horizons covered by each data source
+const (
+	minutelyHorizon   = 2 * time.Hour
+	hourlyHorizon     = 168 * time.Hour
+	dailyHorizon      = 15 * 24 * time.Hour
+	defaultWithinHour = 24
+)
+
+// WeatherConditionInput input parameters for check-weather-condition tool
+type WeatherConditionInput struct {
+	CityName    string   `json:"cityName" jsonschema:"Name of the city to check the condition for"`
+	Condition   string   `json:"condition" jsonschema:"Condition to check: precipitation (rain or snow), snow, temp-below (temperature below threshold °C), temp-above (temperature above threshold °C), wind-above (wind force above threshold on the Beaufort scale)"`
+	Threshold   *float64 `json:"threshold,omitempty" jsonschema:"Threshold for temp-below, temp-above (°C) and wind-above (Beaufort force). Required for those conditions."`
+	StartTime   string   `json:"startTime,omitempty" jsonschema:"Start of the period to check in RFC3339 format (e.g. 2024-05-01T18:00:00+08:00 for tonight). Defaults to now."`
+	WithinHours int      `json:"withinHours,omitempty" jsonschema:"Length of the period to check in hours, up to 360 (15 days). Defaults to 24. Precipitation within the next 2 hours is answered from the minutely nowcast."`
+}
+
+// WeatherConditionOutput output structure for check-weather-condition tool
+type WeatherConditionOutput struct {
+	Answer          bool   `json:"answer" jsonschema:"Whether the condition is forecast to occur within the period"`
+	FirstOccurrence string `json:"firstOccurrence,omitempty" jsonschema:"Forecast time of the first occurrence, if any"`
+	DurationMinutes int    `json:"durationMinutes,omitempty" jsonschema:"How long the first occurrence is forecast to last, in minutes"`
+	Source          string `json:"source" jsonschema:"Data source used to answer: minutely, hourly or daily"`
+	Confidence      string `json:"confidence" jsonschema:"Confidence derived from the data source precision: high (minutely), medium (hourly) or low (daily)"`
+	Summary         string `json:"summary" jsonschema:"Human-readable answer"`
+}
+
+// conditionSample one forecast step evaluated against the condition
+type conditionSample struct {
+	start time.Time
+	step  time.Duration
+	match bool
+	value string
+}
+
+// conditionSourceFor picks the most precise data source covering the period
+func conditionSourceFor(condition string, end time.Time) string {
+	ahead := time.Until(end)
+	switch {
+	case (condition == conditionPrecipitation || condition == conditionSnow) && ahead <= minutelyHorizon:
+		return conditionSourceMinutely
+	case ahead <= hourlyHorizon:
+		return conditionSourceHourly
+	default:
+		return conditionSourceDaily
+	}
+}
+
+// conditionConfidence maps a data source to a confidence level
+func conditionConfidence(source string) string {
+	switch source {
+	case conditionSourceMinutely:
+		return "high"
+	case conditionSourceHourly:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// fetchConditionSamples fetches the data source and evaluates every step against the condition
+func fetchConditionSamples(ctx context.Context, client *api.Client, location *api.Location, source string, input WeatherConditionInput, end time.Time) ([]conditionSample, error) {
+	threshold := 0.0
+	if input.Threshold != nil {
+		threshold = *input.Threshold
+	}
+	var samples []conditionSample
+
+	switch source {
+	case conditionSourceMinutely:
+		precipData, err := client.GetMinutelyPrecipitation(ctx, fmt.Sprintf("%s,%s", location.Lon, location.Lat))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get minutely precipitation forecast data: %w", err)
+		}
+		if err := checkCode(precipData.Code); err != nil {
+			return nil, fmt.Errorf("failed to get minutely precipitation forecast data: %w", err)
+		}
+		for _, minute := range precipData.Minutely {
+			start, err := parseForecastTime(minute.FxTime)
+			if err != nil {
+				continue
+			}
+			precip, _ := strconv.ParseFloat(minute.Precip, 64)
+			match := precip > 0 && (input.Condition == conditionPrecipitation || minute.Type == "snow")
+			samples = append(samples, conditionSample{start: start, step: 5 * time.Minute, match: match, value: minute.Precip + "mm " + minute.Type})
+		}
+
+	case conditionSourceHourly:
+		hourlyData, err := client.GetHourlyForecast(ctx, location.ID, hourlyRangeFor(end))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hourly weather forecast data: %w", err)
+		}
+		if err := checkCode(hourlyData.Code); err != nil {
+			return nil, fmt.Errorf("failed to get hourly weather forecast data: %w", err)
+		}
+		for _, hour := range hourlyData.Hourly {
+			start, err := parseForecastTime(hour.FxTime)
+			if err != nil {
+				continue
+			}
+			sample := conditionSample{start: start, step: time.Hour}
+			temp, _ := strconv.ParseFloat(hour.Temp, 64)
+			precip, _ := strconv.ParseFloat(hour.Precip, 64)
+			wind, _ := parseWindScale(hour.WindScale)
+			switch input.Condition {
+			case conditionPrecipitation:
+				group := iconGroup(hour.Icon)
+				sample.match = precip > 0 || group == iconGroupRain || group == iconGroupSnow
+				sample.value = fmt.Sprintf("%s, %smm", hour.Text, hour.Precip)
+			case conditionSnow:
+				sample.match = iconGroup(hour.Icon) == iconGroupSnow
+				sample.value = hour.Text
+			case conditionTempBelow:
+				sample.match = temp < threshold
+				sample.value = hour.Temp + "°C"
+			case conditionTempAbove:
+				sample.match = temp > threshold
+				sample.value = hour.Temp + "°C"
+			case conditionWindAbove:
+				sample.match = float64(wind) > threshold
+				sample.value = "force " + hour.WindScale
+			}
+			samples = append(samples, sample)
+		}
+
+	default:
+		days := "3d"
+		switch ahead := time.Until(end); {
+		case ahead > 10*24*time.Hour:
+			days = "15d"
+		case ahead > 7*24*time.Hour:
+			days = "10d"
+		case ahead > 3*24*time.Hour:
+			days = "7d"
+		}
+		weatherData, err := client.GetWeatherForecast(ctx, location.ID, days)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get weather forecast data: %w", err)
+		}
+		if err := checkCode(weatherData.Code); err != nil {
+			return nil, fmt.Errorf("failed to get weather forecast data: %w", err)
+		}
+		// Days start at midnight where the location is, not where the server is
+		zone := locationZone(location, end.Location())
+		for _, day := range weatherData.Daily {
+			start, err := time.ParseInLocation("2006-01-02", day.FxDate, zone)
+			if err != nil {
+				continue
+			}
+			sample := conditionSample{start: start, step: 24 * time.Hour}
+			tempMin, _ := strconv.ParseFloat(day.TempMin, 64)
+			tempMax, _ := strconv.ParseFloat(day.TempMax, 64)
+			precip, _ := strconv.ParseFloat(day.Precip, 64)
+			windDay, _ := parseWindScale(day.WindScaleDay)
+			windNight, _ := parseWindScale(day.WindScaleNight)
+			dayGroup, nightGroup := iconGroup(day.IconDay), iconGroup(day.IconNight)
+			switch input.Condition {
+			case conditionPrecipitation:
+				sample.match = precip > 0 || dayGroup == iconGroupRain || dayGroup == iconGroupSnow || nightGroup == iconGroupRain || nightGroup == iconGroupSnow
+				sample.value = fmt.Sprintf("%s / %s, %smm", day.TextDay, day.TextNight, day.Precip)
+			case conditionSnow:
+				sample.match = dayGroup == iconGroupSnow || nightGroup == iconGroupSnow
+				sample.value = fmt.Sprintf("%s / %s", day.TextDay, day.TextNight)
+			case conditionTempBelow:
+				sample.match = tempMin < threshold
+				sample.value = "low " + day.TempMin + "°C"
+			case conditionTempAbove:
+				sample.match = tempMax > threshold
+				sample.value = "high " + day.TempMax + "°C"
+			case conditionWindAbove:
+				sample.match = float64(max(windDay, windNight)) > threshold
+				sample.value = fmt.Sprintf("force %s by day, %s at night", day.WindScaleDay, day.WindScaleNight)
+			}
+			samples = append(samples, sample)
+		}
+	}
+	return samples, nil
+}
+
+// firstOccurrence finds the first matching sample overlapping [start, end) and how long the match lasts
+func firstOccurrence(samples []conditionSample, start, end time.Time) (first *conditionSample, duration time.Duration) {
+	for i, s := range samples {
+		if first == nil {
+			if s.match && s.start.Add(s.step).After(start) && s.start.Before(end) {
+				first = &samples[i]
+				duration = s.step
+			}
+			continue
+		}
+		if !s.match || !s.start.Equal(samples[i-1].start.Add(samples[i-1].step)) {
+			break
+		}
+		duration += s.step
+	}
+	return first, duration
+}
+
+// formatDuration formats a duration as hours and minutes (e.g. 1h30m, 45m)
+func formatDuration(d time.Duration) string {
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	}
+}
+
+func handleWeatherCondition(ctx context.Context, client *api.Client, input WeatherConditionInput) (WeatherConditionOutput, error) {
+	if input.CityName == "" {
+		return WeatherConditionOutput{}, fmt.Errorf("city name cannot be empty")
+	}
+
+	switch input.Condition {
+	case conditionPrecipitation, conditionSnow:
+	case conditionTempBelow, conditionTempAbove, conditionWindAbove:
+		if input.Threshold == nil {
+			return WeatherConditionOutput{}, fmt.Errorf("threshold is required for condition %s", input.Condition)
+		}
+	default:
+		return WeatherConditionOutput{}, fmt.Errorf("invalid condition: must be one of precipitation, snow, temp-below, temp-above, wind-above")
+	}
+
+	start := time.Now()
+	if input.StartTime != "" {
+		t, err := time.Parse(time.RFC3339, input.StartTime)
+		if err != nil {
+			return WeatherConditionOutput{}, fmt.Errorf("invalid startTime: must be RFC3339 (e.g. 2024-05-01T18:00:00+08:00)")
+		}
+		start = t
+	}
+	if input.WithinHours == 0 {
+		input.WithinHours = defaultWithinHour
+	}
+	end := start.Add(time.Duration(input.WithinHours) * time.Hour)
+	if input.WithinHours < 0 || time.Until(end) > dailyHorizon {
+		return WeatherConditionOutput{}, fmt.Errorf("invalid period: the forecast only covers the next 15 days")
+	}
+
+	location, err := resolveLocation(ctx, client, input.CityName)
+	if err != nil {
+		return WeatherConditionOutput{}, err
+	}
+
+	source := conditionSourceFor(input.Condition, end)
+	samples, err := fetchConditionSamples(ctx, client, location, source, input, end)
+	if err != nil {
+		return WeatherConditionOutput{}, err
+	}
+
+	out := WeatherConditionOutput{Source: source, Confidence: conditionConfidence(source)}
+	question := describeCondition(input)
+	period := fmt.Sprintf("%s to %s", start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04 MST"))
+
+	first, duration := firstOccurrence(samples, start, end)
+	if first == nil {
+		out.Summary = fmt.Sprintf("No: %s is not forecast in %s (%s %s) between %s (source: %s forecast, confidence: %s)",
+			question, location.Name, location.Adm1, location.Adm2, period, source, out.Confidence)
+		return out, nil
+	}
+
+	out.Answer = true
+	out.FirstOccurrence = first.start.Format(time.RFC3339)
+	out.DurationMinutes = int(duration / time.Minute)
+	out.Summary = fmt.Sprintf("Yes: %s is forecast in %s (%s %s) from %s (%s), lasting about %s (source: %s forecast, confidence: %s)",
+		question, location.Name, location.Adm1, location.Adm2, first.start.Format("2006-01-02 15:04"), first.value,
+		formatDuration(duration), source, out.Confidence)
+	return out, nil
+}
+
+// describeCondition returns a readable description of the checked condition
+func describeCondition(input WeatherConditionInput) string {
+	switch input.Condition {
+	case conditionPrecipitation:
+		return "precipitation"
+	case conditionSnow:
+		return "snow"
+	case conditionTempBelow:
+		return fmt.Sprintf("temperature below %g°C", *input.Threshold)
+	case conditionTempAbove:
+		return fmt.Sprintf("temperature above %g°C", *input.Threshold)
+	default:
+		return fmt.Sprintf("wind above force %g", *input.Threshold)
+	}
+}
+
+// RegisterConditionTools Register weather condition check tools
+func RegisterConditionTools(s *mcp.Server, client *api.Client) {
+	// Yes/no weather condition tool
+	mcp.AddTool(s, catalogTool[WeatherConditionOutput]("check-weather-condition"), func(ctx context.Context, req *mcp.CallToolRequest, input WeatherConditionInput) (*mcp.CallToolResult, WeatherConditionOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, WeatherConditionOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleWeatherCondition(ctx, client, input)
+		if err != nil {
+			return nil, WeatherConditionOutput{}, err
+		}
+		return nil, out, nil
+	})
+}
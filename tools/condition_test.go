@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// setupConditionMockServer creates a mock server with minutely and hourly forecasts for Beijing
+func setupConditionMockServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			json.NewEncoder(w).Encode(api.LocationResponse{
+				Code: "200",
+				Location: []api.Location{{
+					Name: "Beijing", ID: "101010100", Lat: "39.90", Lon: "116.41", Adm1: "Beijing", Adm2: "Beijing",
+					TZ: "Asia/Shanghai", UtcOffset: "+08:00",
+				}},
+			})
+		case "/v7/minutely/5m":
+			w.Write([]byte(`{"code":"200","minutely":[
+				{"fxTime":"2024-05-01T12:00+08:00","precip":"0.00","type":"rain"},
+				{"fxTime":"2024-05-01T12:05+08:00","precip":"0.12","type":"rain"},
+				{"fxTime":"2024-05-01T12:10+08:00","precip":"0.30","type":"rain"},
+				{"fxTime":"2024-05-01T12:15+08:00","precip":"0.00","type":"rain"}]}`))
+		case "/v7/weather/24h":
+			w.Write([]byte(`{"code":"200","hourly":[
+				{"fxTime":"2024-05-01T20:00+08:00","temp":"2"},
+				{"fxTime":"2024-05-01T21:00+08:00","temp":"-1"},
+				{"fxTime":"2024-05-01T22:00+08:00","temp":"-2"},
+				{"fxTime":"2024-05-01T23:00+08:00","temp":"0"}]}`))
+		case "/v7/weather/10d":
+			// Snow on the day after the first forecast day, in Beijing time
+			day := dailyConditionDay()
+			fmt.Fprintf(w, `{"code":"200","daily":[
+				{"fxDate":"%s","iconDay":"100","iconNight":"150","textDay":"Sunny","textNight":"Clear"},
+				{"fxDate":"%s","iconDay":"400","iconNight":"400","textDay":"Light Snow","textNight":"Light Snow"}]}`,
+				day.Format("2006-01-02"), day.AddDate(0, 0, 1).Format("2006-01-02"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestHandleWeatherCondition_MinutelyPrecipitation(t *testing.T) {
+	server := setupConditionMockServer()
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "test-key")
+	out, err := handleWeatherCondition(context.Background(), client, WeatherConditionInput{
+		CityName:    "Beijing",
+		Condition:   "precipitation",
+		StartTime:   "2024-05-01T12:00:00+08:00",
+		WithinHours: 2,
+	})
+	if err != nil {
+		t.Fatalf("handleWeatherCondition failed: %v", err)
+	}
+	if !out.Answer || out.Source != "minutely" || out.Confidence != "high" {
+		t.Fatalf("output = %+v, want a minutely answer with high confidence", out)
+	}
+	if out.FirstOccurrence != "2024-05-01T12:05:00+08:00" || out.DurationMinutes != 10 {
+		t.Fatalf("first occurrence = %s lasting %d minutes, want 2024-05-01T12:05:00+08:00 lasting 10", out.FirstOccurrence, out.DurationMinutes)
+	}
+}
+
+// dailyConditionDay returns the first day of the mocked daily forecast, eight days from now
+func dailyConditionDay() time.Time {
+	now := time.Now().UTC().AddDate(0, 0, 8)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func TestHandleWeatherCondition_DailyLocationDays(t *testing.T) {
+	server := setupConditionMockServer()
+	defer server.Close()
+
+	// 18:00 to 23:00 UTC is 02:00 to 07:00 of the next day in Beijing, the snowy day
+	client := api.NewClient(server.URL, "test-key")
+	out, err := handleWeatherCondition(context.Background(), client, WeatherConditionInput{
+		CityName:    "Beijing",
+		Condition:   "snow",
+		StartTime:   dailyConditionDay().Add(18 * time.Hour).Format(time.RFC3339),
+		WithinHours: 5,
+	})
+	if err != nil {
+		t.Fatalf("handleWeatherCondition failed: %v", err)
+	}
+	if !out.Answer || out.Source != "daily" {
+		t.Fatalf("output = %+v, want snow from the daily forecast", out)
+	}
+}
+
+func TestHandleWeatherCondition_HourlyTempBelow(t *testing.T) {
+	server := setupConditionMockServer()
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "test-key")
+	threshold := 0.0
+	out, err := handleWeatherCondition(context.Background(), client, WeatherConditionInput{
+		CityName:    "Beijing",
+		Condition:   "temp-below",
+		Threshold:   &threshold,
+		StartTime:   "2024-05-01T18:00:00+08:00",
+		WithinHours: 12,
+	})
+	if err != nil {
+		t.Fatalf("handleWeatherCondition failed: %v", err)
+	}
+	if !out.Answer || out.Source != "hourly" || out.DurationMinutes != 120 {
+		t.Fatalf("output = %+v, want an hourly answer lasting 120 minutes", out)
+	}
+	if !strings.Contains(out.Summary, "temperature below 0°C is forecast in Beijing") {
+		t.Fatalf("Summary = %q, want to describe the condition", out.Summary)
+	}
+
+	threshold = -5
+	out, err = handleWeatherCondition(context.Background(), client, WeatherConditionInput{
+		CityName:    "Beijing",
+		Condition:   "temp-below",
+		Threshold:   &threshold,
+		StartTime:   "2024-05-01T18:00:00+08:00",
+		WithinHours: 12,
+	})
+	if err != nil {
+		t.Fatalf("handleWeatherCondition failed: %v", err)
+	}
+	if out.Answer || out.FirstOccurrence != "" {
+		t.Fatalf("output = %+v, want a negative answer", out)
+	}
+}
+
+func TestHandleWeatherCondition_Validation(t *testing.T) {
+	client := api.NewClient("http://example.com", "test-key")
+	tests := []struct {
+		name  string
+		input WeatherConditionInput
+	}{
+		{"empty city", WeatherConditionInput{Condition: "snow"}},
+		{"unknown condition", WeatherConditionInput{CityName: "Beijing", Condition: "hail"}},
+		{"missing threshold", WeatherConditionInput{CityName: "Beijing", Condition: "wind-above"}},
+		{"beyond forecast range", WeatherConditionInput{CityName: "Beijing", Condition: "snow", WithinHours: 400}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := handleWeatherCondition(context.Background(), client, tt.input); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// maxLocationChoices candidates offered when a city name is ambiguous
+const maxLocationChoices = 5
+
+// chooseLocation asks the user which location a city name refers to when the geo lookup returns
+// several plausible matches and the client supports elicitation. It returns the ID of the chosen
+// location, which the geo lookup accepts in place of the name, so handlers resolve exactly that
+// location. In every other case the name is returned unchanged and the best match is used.
+func chooseLocation(ctx context.Context, session *mcp.ServerSession, client *api.Client, cityName string) (string, error) {
+	if !supportsElicitation(session) || strings.TrimSpace(cityName) == "" || strings.ContainsAny(cityName, ",0123456789") {
+		// Coordinates and location IDs are never ambiguous
+		return cityName, nil
+	}
+
+	locationData, err := client.GetLocationByName(ctx, cityName)
+	if err != nil {
+		// Let the handler report lookup failures
+		return cityName, nil
+	}
+	candidates := ambiguousLocations(cityName, locationData.Location)
+	if len(candidates) < 2 {
+		return cityName, nil
+	}
+
+	ids := make([]any, len(candidates))
+	labels := make([]any, len(candidates))
+	for i, location := range candidates {
+		ids[i] = location.ID
+		labels[i] = locationLabel(location)
+	}
+	result, err := session.Elicit(ctx, &mcp.ElicitParams{
+		Message: fmt.Sprintf("Several places match %q. Which one do you mean?", cityName),
+		RequestedSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"location": map[string]any{
+					"type":        "string",
+					"title":       "Location",
+					"description": "The place to use for this request",
+					"enum":        ids,
+					"enumNames":   labels,
+				},
+			},
+			"required": []string{"location"},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to ask which location %q refers to: %w", cityName, err)
+	}
+	if result.Action != "accept" {
+		return "", fmt.Errorf("no location chosen for %q", cityName)
+	}
+
+	chosen, _ := result.Content["location"].(string)
+	for _, location := range candidates {
+		if location.ID == chosen {
+			return location.ID, nil
+		}
+	}
+	return "", fmt.Errorf("chosen location %q is not one of the matches for %q", chosen, cityName)
+}
+
+// supportsElicitation reports whether the client of a session declared the elicitation capability
+func supportsElicitation(session *mcp.ServerSession) bool {
+	if session == nil {
+		return false
+	}
+	params := session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Elicitation != nil
+}
+
+// ambiguousLocations returns the matches sharing the name that was asked for, or the name of the
+// best match when none matches exactly, limited to the top candidates in lookup order
+func ambiguousLocations(cityName string, locations []api.Location) []api.Location {
+	if len(locations) < 2 {
+		return nil
+	}
+	name := locations[0].Name
+	for _, location := range locations {
+		if strings.EqualFold(location.Name, strings.TrimSpace(cityName)) {
+			name = location.Name
+			break
+		}
+	}
+
+	var candidates []api.Location
+	for _, location := range locations {
+		if strings.EqualFold(location.Name, name) && len(candidates) < maxLocationChoices {
+			candidates = append(candidates, location)
+		}
+	}
+	return candidates
+}
+
+// locationLabel describes a location by name, administrative areas and country
+func locationLabel(location api.Location) string {
+	parts := []string{location.Name}
+	for _, part := range []string{location.Adm2, location.Adm1, location.Country} {
+		if part != "" && part != parts[len(parts)-1] {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// connectWeatherTools connects a client with the given options to a server with the weather tools
+func connectWeatherTools(t *testing.T, client *api.Client, opts *mcp.ClientOptions) *mcp.ClientSession {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	RegisterWeatherTools(server, client)
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server Connect failed: %v", err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, opts).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect failed: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func TestChooseLocation(t *testing.T) {
+	springfields := `{"code":"200","location":[` +
+		`{"name":"Springfield","id":"4250542","adm2":"Sangamon","adm1":"Illinois","country":"United States"},` +
+		`{"name":"Springfield","id":"4409896","adm2":"Greene","adm1":"Missouri","country":"United States"},` +
+		`{"name":"Springfield Gardens","id":"5139568","adm1":"New York","country":"United States"}]}`
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			switch r.URL.Query().Get("location") {
+			case "Springfield":
+				w.Write([]byte(springfields))
+			case "4409896":
+				w.Write([]byte(`{"code":"200","location":[{"name":"Springfield","id":"4409896","adm2":"Greene","adm1":"Missouri","country":"United States"}]}`))
+			default:
+				w.Write([]byte(`{"code":"404","location":[]}`))
+			}
+		case "/v7/weather/now":
+			w.Write([]byte(`{"code":"200","now":{"temp":"18","text":"Sunny"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer upstream.Close()
+	client := api.NewClient(upstream.URL, "test-key")
+	ctx := context.Background()
+	call := &mcp.CallToolParams{Name: "get-weather-now", Arguments: map[string]any{"cityName": "Springfield"}}
+
+	var offered []any
+	chooser := connectWeatherTools(t, client, &mcp.ClientOptions{
+		ElicitationHandler: func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			schema := req.Params.RequestedSchema.(map[string]any)
+			location := schema["properties"].(map[string]any)["location"].(map[string]any)
+			offered = location["enumNames"].([]any)
+			return &mcp.ElicitResult{Action: "accept", Content: map[string]any{"location": "4409896"}}, nil
+		},
+	})
+	result, err := chooser.CallTool(ctx, call)
+	if err != nil || result.IsError {
+		t.Fatalf("CallTool failed: %v %+v", err, result)
+	}
+	if len(offered) != 2 || offered[0] != "Springfield, Sangamon, Illinois, United States" {
+		t.Errorf("offered %v, want the two Springfields with their areas", offered)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Springfield (Missouri Greene)") {
+		t.Errorf("tool output = %s, want the chosen location", text)
+	}
+
+	// Clients without elicitation keep the best match
+	plain := connectWeatherTools(t, client, nil)
+	result, err = plain.CallTool(ctx, call)
+	if err != nil || result.IsError {
+		t.Fatalf("CallTool failed: %v %+v", err, result)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Springfield (Illinois Sangamon)") {
+		t.Errorf("tool output = %s, want the best match", text)
+	}
+
+	decliner := connectWeatherTools(t, client, &mcp.ClientOptions{
+		ElicitationHandler: func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			return &mcp.ElicitResult{Action: "decline"}, nil
+		},
+	})
+	result, err = decliner.CallTool(ctx, call)
+	if err == nil && !result.IsError {
+		t.Fatal("expected an error when the user declines to choose")
+	}
+}
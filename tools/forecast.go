@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// forecastTimeLayout layout of fxTime values returned by the QWeather v7 API (e.g. 2024-01-01T12:00+08:00)
+const forecastTimeLayout = "2006-01-02T15:04Z07:00"
+
+// parseForecastTime parses an fxTime value, also accepting full RFC3339 timestamps
+func parseForecastTime(s string) (time.Time, error) {
+	if t, err := time.Parse(forecastTimeLayout, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: %w", s, err)
+	}
+	return t, nil
+}
+
+// locationZone returns the time zone of a location from its IANA name, else from its UTC offset,
+// else fallback
+func locationZone(location *api.Location, fallback *time.Location) *time.Location {
+	if location.TZ != "" {
+		if zone, err := time.LoadLocation(location.TZ); err == nil {
+			return zone
+		}
+	}
+	if t, err := time.Parse("-07:00", location.UtcOffset); err == nil {
+		name, offset := t.Zone()
+		return time.FixedZone(name, offset)
+	}
+	return fallback
+}
+
+// hourlyRangeFor returns the shortest hourly forecast range ("24h", "72h" or "168h") covering t
+func hourlyRangeFor(t time.Time) string {
+	switch ahead := time.Until(t); {
+	case ahead <= 23*time.Hour:
+		return "24h"
+	case ahead <= 71*time.Hour:
+		return "72h"
+	default:
+		return "168h"
+	}
+}
+
+// closestHourly returns the index of the hourly entry closest to t and its distance from t,
+// or -1 if the response contains no parseable entries
+func closestHourly(hourly *api.HourlyResponse, t time.Time) (int, time.Duration) {
+	best, bestDiff := -1, time.Duration(0)
+	for i, hour := range hourly.Hourly {
+		fxTime, err := parseForecastTime(hour.FxTime)
+		if err != nil {
+			continue
+		}
+		diff := fxTime.Sub(t)
+		if diff < 0 {
+			diff = -diff
+		}
+		if best == -1 || diff < bestDiff {
+			best, bestDiff = i, diff
+		}
+	}
+	return best, bestDiff
+}
+
+// parseWindScale parses a wind force level, taking the upper bound of ranges such as "3-4"
+func parseWindScale(s string) (int, bool) {
+	if i := strings.LastIndex(s, "-"); i >= 0 {
+		s = s[i+1:]
+	}
+	scale, err := strconv.Atoi(strings.TrimSpace(s))
+	return scale, err == nil
+}
+
+// Weather icon groups used by QWeather: 3xx rain, 4xx snow, 5xx fog, haze, sand and dust
+const (
+	iconGroupRain = 3
+	iconGroupSnow = 4
+	iconGroupHaze = 5
+)
+
+// iconGroup returns the hundreds digit of a QWeather weather icon code
+func iconGroup(icon string) int {
+	code, err := strconv.Atoi(icon)
+	if err != nil {
+		return 0
+	}
+	return code / 100
+}
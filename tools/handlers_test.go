@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -31,6 +32,7 @@ func TestHandleWeatherNow_Success(t *testing.T) {
 			resp.Now.Precip = "0"
 			resp.Now.Pressure = "1013"
 			resp.Now.Vis = "10"
+			resp.Now.Dew = "9"
 			json.NewEncoder(w).Encode(resp)
 		default:
 			http.NotFound(w, r)
@@ -39,7 +41,7 @@ func TestHandleWeatherNow_Success(t *testing.T) {
 	defer server.Close()
 
 	client := api.NewClient(server.URL, "test-key")
-	out, err := handleWeatherNow(client, WeatherNowInput{CityName: "Beijing"})
+	out, err := handleWeatherNow(context.Background(), client, WeatherNowInput{CityName: "Beijing"})
 	if err != nil {
 		t.Fatalf("handleWeatherNow failed: %v", err)
 	}
@@ -49,6 +51,12 @@ func TestHandleWeatherNow_Success(t *testing.T) {
 	if !strings.Contains(out.WeatherInfo, "Temperature: 20°C") {
 		t.Fatalf("WeatherInfo = %q, want to contain %q", out.WeatherInfo, "Temperature: 20°C")
 	}
+	// The reported dew point is used rather than derived
+	for _, want := range []string{"Dew Point: 9°C\n", "Comfort (derived): apparent temperature"} {
+		if !strings.Contains(out.WeatherInfo, want) {
+			t.Fatalf("WeatherInfo = %q, want to contain %q", out.WeatherInfo, want)
+		}
+	}
 }
 
 func TestHandleWeatherForecast_DefaultDays(t *testing.T) {
@@ -103,7 +111,7 @@ func TestHandleWeatherForecast_DefaultDays(t *testing.T) {
 	defer server.Close()
 
 	client := api.NewClient(server.URL, "test-key")
-	out, err := handleWeatherForecast(client, WeatherForecastInput{CityName: "Beijing"})
+	out, err := handleWeatherForecast(context.Background(), client, WeatherForecastInput{CityName: "Beijing"})
 	if err != nil {
 		t.Fatalf("handleWeatherForecast failed: %v", err)
 	}
@@ -114,7 +122,7 @@ func TestHandleWeatherForecast_DefaultDays(t *testing.T) {
 
 func TestHandleWeatherForecast_InvalidDays(t *testing.T) {
 	client := api.NewClient("http://example.com", "test-key")
-	_, err := handleWeatherForecast(client, WeatherForecastInput{CityName: "Beijing", Days: "5d"})
+	_, err := handleWeatherForecast(context.Background(), client, WeatherForecastInput{CityName: "Beijing", Days: "5d"})
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -154,7 +162,7 @@ func TestHandleMinutelyPrecipitation_Success(t *testing.T) {
 	defer server.Close()
 
 	client := api.NewClient(server.URL, "test-key")
-	out, err := handleMinutelyPrecipitation(client, MinutelyPrecipitationInput{CityName: "Beijing"})
+	out, err := handleMinutelyPrecipitation(context.Background(), client, MinutelyPrecipitationInput{CityName: "Beijing"})
 	if err != nil {
 		t.Fatalf("handleMinutelyPrecipitation failed: %v", err)
 	}
@@ -204,7 +212,7 @@ func TestHandleHourlyForecast_DefaultHours(t *testing.T) {
 	defer server.Close()
 
 	client := api.NewClient(server.URL, "test-key")
-	out, err := handleHourlyForecast(client, HourlyForecastInput{CityName: "Beijing"})
+	out, err := handleHourlyForecast(context.Background(), client, HourlyForecastInput{CityName: "Beijing"})
 	if err != nil {
 		t.Fatalf("handleHourlyForecast failed: %v", err)
 	}
@@ -221,7 +229,7 @@ func TestHandleHourlyForecast_DefaultHours(t *testing.T) {
 
 func TestHandleHourlyForecast_InvalidHours(t *testing.T) {
 	client := api.NewClient("http://example.com", "test-key")
-	_, err := handleHourlyForecast(client, HourlyForecastInput{CityName: "Beijing", Hours: "48h"})
+	_, err := handleHourlyForecast(context.Background(), client, HourlyForecastInput{CityName: "Beijing", Hours: "48h"})
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -246,7 +254,7 @@ func TestHandleWeatherWarning_NoWarnings(t *testing.T) {
 	defer server.Close()
 
 	client := api.NewClient(server.URL, "test-key")
-	out, err := handleWeatherWarning(client, WeatherWarningInput{CityName: "Beijing"})
+	out, err := handleWeatherWarning(context.Background(), client, WeatherWarningInput{CityName: "Beijing"})
 	if err != nil {
 		t.Fatalf("handleWeatherWarning failed: %v", err)
 	}
@@ -295,7 +303,7 @@ func TestHandleWeatherWarning_WithWarning(t *testing.T) {
 	defer server.Close()
 
 	client := api.NewClient(server.URL, "test-key")
-	out, err := handleWeatherWarning(client, WeatherWarningInput{CityName: "Beijing"})
+	out, err := handleWeatherWarning(context.Background(), client, WeatherWarningInput{CityName: "Beijing"})
 	if err != nil {
 		t.Fatalf("handleWeatherWarning failed: %v", err)
 	}
@@ -337,7 +345,7 @@ func TestHandleWeatherIndices_Defaults(t *testing.T) {
 	defer server.Close()
 
 	client := api.NewClient(server.URL, "test-key")
-	out, err := handleWeatherIndices(client, WeatherIndicesInput{CityName: "Beijing"})
+	out, err := handleWeatherIndices(context.Background(), client, WeatherIndicesInput{CityName: "Beijing"})
 	if err != nil {
 		t.Fatalf("handleWeatherIndices failed: %v", err)
 	}
@@ -346,6 +354,46 @@ func TestHandleWeatherIndices_Defaults(t *testing.T) {
 	}
 }
 
+func TestHandleWeatherIndices_TypesWithSpaces(t *testing.T) {
+	var gotType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			json.NewEncoder(w).Encode(api.LocationResponse{
+				Code:     "200",
+				Location: []api.Location{{Name: "Beijing", ID: "101010100"}},
+			})
+		case "/v7/indices/1d":
+			gotType = r.URL.Query().Get("type")
+			json.NewEncoder(w).Encode(api.IndicesResponse{Code: "200"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "test-key")
+	if _, err := handleWeatherIndices(context.Background(), client, WeatherIndicesInput{CityName: "Beijing", Type: "1, 3"}); err != nil {
+		t.Fatalf("handleWeatherIndices failed: %v", err)
+	}
+	if gotType != "1,3" {
+		t.Errorf("requested type = %q, want %q", gotType, "1,3")
+	}
+}
+
+func TestHandleWeatherIndices_InvalidParams(t *testing.T) {
+	client := api.NewClient("http://example.com", "test-key")
+	for _, input := range []WeatherIndicesInput{
+		{CityName: "Beijing", Days: "7d"},
+		{CityName: "Beijing", Type: "17"},
+		{CityName: "Beijing", Type: "1,,3"},
+	} {
+		if _, err := handleWeatherIndices(context.Background(), client, input); err == nil {
+			t.Errorf("handleWeatherIndices(%+v) expected error, got nil", input)
+		}
+	}
+}
+
 func TestHandleAirQuality_UnknownCodeWithData(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
@@ -366,7 +414,7 @@ func TestHandleAirQuality_UnknownCodeWithData(t *testing.T) {
 	defer server.Close()
 
 	client := api.NewClient(server.URL, "test-key")
-	out, err := handleAirQuality(client, AirQualityInput{CityName: "Beijing"})
+	out, err := handleAirQuality(context.Background(), client, AirQualityInput{CityName: "Beijing"})
 	if err != nil {
 		t.Fatalf("handleAirQuality failed: %v", err)
 	}
@@ -395,7 +443,7 @@ func TestHandleAirQualityHourly_Success(t *testing.T) {
 	defer server.Close()
 
 	client := api.NewClient(server.URL, "test-key")
-	out, err := handleAirQualityHourly(client, AirQualityHourlyInput{CityName: "Beijing"})
+	out, err := handleAirQualityHourly(context.Background(), client, AirQualityHourlyInput{CityName: "Beijing"})
 	if err != nil {
 		t.Fatalf("handleAirQualityHourly failed: %v", err)
 	}
@@ -424,7 +472,7 @@ func TestHandleAirQualityDaily_Success(t *testing.T) {
 	defer server.Close()
 
 	client := api.NewClient(server.URL, "test-key")
-	out, err := handleAirQualityDaily(client, AirQualityDailyInput{CityName: "Beijing"})
+	out, err := handleAirQualityDaily(context.Background(), client, AirQualityDailyInput{CityName: "Beijing"})
 	if err != nil {
 		t.Fatalf("handleAirQualityDaily failed: %v", err)
 	}
@@ -432,3 +480,163 @@ func TestHandleAirQualityDaily_Success(t *testing.T) {
 		t.Fatalf("DailyInfo = %q, want to contain %q", out.DailyInfo, "3-day Air Quality Forecast - Beijing")
 	}
 }
+
+func TestHandleStationAirQuality_ComparesStations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			json.NewEncoder(w).Encode(api.LocationResponse{
+				Code: "200",
+				Location: []api.Location{{
+					Name: "Beijing", ID: "101010100", Lat: "39.90", Lon: "116.41", Adm1: "Beijing", Adm2: "Beijing",
+				}},
+			})
+		case "/airquality/v1/current/39.90/116.41":
+			w.Write([]byte(`{"indexes":[{"code":"qaqi","name":"QAQI","aqi":50,"aqiDisplay":"50"}],"stations":[{"id":"P1","name":"Dongcheng"},{"id":"P2","name":"Haidian"},{"id":"P3","name":"Offline"}]}`))
+		case "/airquality/v1/station/P1":
+			w.Write([]byte(`{"pollutants":[{"code":"pm2p5","name":"PM 2.5","concentration":{"value":10,"unit":"μg/m3"}}]}`))
+		case "/airquality/v1/station/P2":
+			w.Write([]byte(`{"pollutants":[{"code":"pm2p5","name":"PM 2.5","concentration":{"value":30,"unit":"μg/m3"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "test-key")
+	out, err := handleStationAirQuality(context.Background(), client, StationAirQualityInput{CityName: "Beijing"})
+	if err != nil {
+		t.Fatalf("handleStationAirQuality failed: %v", err)
+	}
+	for _, want := range []string{
+		"Station: Dongcheng (ID: P1)",
+		"Station: Offline (ID: P3)\nData unavailable",
+		"PM 2.5: lowest 10.0μg/m3 (Dongcheng), highest 30.0μg/m3 (Haidian), average 20.0μg/m3 across 2 stations",
+	} {
+		if !strings.Contains(out.StationInfo, want) {
+			t.Fatalf("StationInfo = %q, want to contain %q", out.StationInfo, want)
+		}
+	}
+}
+
+func TestHandleHourlyForecast_DerivedFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			json.NewEncoder(w).Encode(api.LocationResponse{
+				Code: "200",
+				Location: []api.Location{{
+					Name: "Beijing", ID: "101010100", Lat: "39.90", Lon: "116.41", Adm1: "Beijing", Adm2: "Beijing",
+				}},
+			})
+		case "/v7/weather/24h":
+			w.Write([]byte(`{"code":"200","hourly":[{"fxTime":"2024-01-01T13:00+08:00","temp":"-10","humidity":"50","windSpeed":"20"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "test-key")
+	out, err := handleHourlyForecast(context.Background(), client, HourlyForecastInput{CityName: "Beijing"})
+	if err != nil {
+		t.Fatalf("handleHourlyForecast failed: %v", err)
+	}
+	for _, want := range []string{"Feels Like: -18°C (derived)", "Dew Point: -18°C (derived)"} {
+		if !strings.Contains(out.HourlyInfo, want) {
+			t.Fatalf("HourlyInfo = %q, want to contain %q", out.HourlyInfo, want)
+		}
+	}
+}
+
+func TestHandleHourlyForecast_WindDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			json.NewEncoder(w).Encode(api.LocationResponse{
+				Code: "200",
+				Location: []api.Location{{
+					Name: "Beijing", ID: "101010100", Lat: "39.90", Lon: "116.41", Adm1: "Beijing", Adm2: "Beijing",
+				}},
+			})
+		case "/v7/weather/24h":
+			w.Write([]byte(`{"code":"200","hourly":[{"fxTime":"2024-01-01T13:00+08:00","temp":"5","humidity":"50","windDir":"North","wind360":"350","windScale":"3","windSpeed":"18"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "test-key")
+	out, err := handleHourlyForecast(context.Background(), client, HourlyForecastInput{CityName: "Beijing", WindUnit: "m/s", WindDetails: true})
+	if err != nil {
+		t.Fatalf("handleHourlyForecast failed: %v", err)
+	}
+	for _, want := range []string{"Wind Direction: North (Force 3, 5.0m/s)", "Wind: North 350° (N), Force 3 Gentle breeze, 5.0m/s"} {
+		if !strings.Contains(out.HourlyInfo, want) {
+			t.Fatalf("HourlyInfo = %q, want to contain %q", out.HourlyInfo, want)
+		}
+	}
+
+	if _, err := handleHourlyForecast(context.Background(), client, HourlyForecastInput{CityName: "Beijing", WindUnit: "furlongs"}); err == nil {
+		t.Fatal("expected error for invalid wind unit")
+	}
+}
+
+func TestHandleAirQuality_Standard(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			json.NewEncoder(w).Encode(api.LocationResponse{
+				Code: "200",
+				Location: []api.Location{{
+					Name: "Beijing", ID: "101010100", Lat: "39.90", Lon: "116.41", Adm1: "Beijing", Adm2: "Beijing",
+				}},
+			})
+		case "/airquality/v1/current/39.90/116.41":
+			w.Write([]byte(`{"indexes":[{"code":"qaqi","name":"QAQI","aqi":2,"aqiDisplay":"2"},{"code":"cn-mee","name":"AQI (CN)","aqi":107,"aqiDisplay":"107"}],` +
+				`"pollutants":[{"code":"pm2p5","name":"PM 2.5","concentration":{"value":80,"unit":"μg/m3"}},{"code":"pm10","name":"PM 10","concentration":{"value":100,"unit":"μg/m3"}},` +
+				`{"code":"o3","name":"O3","concentration":{"value":60,"unit":"ppb"}},{"code":"co","name":"CO","concentration":{"value":5000,"unit":"μg/m3"}},` +
+				`{"code":"so2","name":"SO2","concentration":{"value":1,"unit":"ppt"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "test-key")
+
+	out, err := handleAirQuality(context.Background(), client, AirQualityInput{CityName: "Beijing"})
+	if err != nil {
+		t.Fatalf("handleAirQuality failed: %v", err)
+	}
+	if !strings.Contains(out.AirQualityInfo, "several standards (qaqi, cn-mee)") {
+		t.Fatalf("AirQualityInfo = %q, want an explanation of the reported standards", out.AirQualityInfo)
+	}
+
+	out, err = handleAirQuality(context.Background(), client, AirQualityInput{CityName: "Beijing", Standard: "cn-mee"})
+	if err != nil {
+		t.Fatalf("handleAirQuality failed: %v", err)
+	}
+	if !strings.Contains(out.AirQualityInfo, "AQI (CN) (cn-mee): 107") || strings.Contains(out.AirQualityInfo, "QAQI") {
+		t.Fatalf("AirQualityInfo = %q, want only the cn-mee index", out.AirQualityInfo)
+	}
+
+	out, err = handleAirQuality(context.Background(), client, AirQualityInput{CityName: "Beijing", Standard: "us-epa"})
+	if err != nil {
+		t.Fatalf("handleAirQuality failed: %v", err)
+	}
+	for _, want := range []string{"US EPA AQI (us-epa): 168 - computed locally", "Main Pollutant: PM 2.5", "Sub-indexes: PM 2.5 168, PM 10 73, O3 67, CO 50"} {
+		if !strings.Contains(out.AirQualityInfo, want) {
+			t.Fatalf("AirQualityInfo = %q, want to contain %q", out.AirQualityInfo, want)
+		}
+	}
+	// Units are converted for each pollutant, and pollutants in unknown units are left out
+	if !strings.Contains(out.AirQualityInfo, "CO 50\n---") {
+		t.Fatalf("AirQualityInfo = %q, want SO2 in an unknown unit left out", out.AirQualityInfo)
+	}
+
+	if _, err := handleAirQuality(context.Background(), client, AirQualityInput{CityName: "Beijing", Standard: "in-cpcb"}); err == nil {
+		t.Fatal("expected error for a standard that is neither reported nor computable")
+	}
+}
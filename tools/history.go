@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+	"github.com/overstarry/qweather-mcp-go/recorder"
+)
+
+// Limits and defaults for the query-recorded-history tool
+const (
+	defaultHistoryRange = 7 * 24 * time.Hour
+	maxHistoryRange     = 366 * 24 * time.Hour
+	historyGroupDay     = "day"
+)
+
+// RecordedHistoryInput input parameters for query-recorded-history tool
+type RecordedHistoryInput struct {
+	CityName  string `json:"cityName" jsonschema:"Name of a recorded city to query history for"`
+	StartTime string `json:"startTime,omitempty" jsonschema:"Start of the range in RFC3339 format (e.g. 2024-05-01T00:00:00+08:00). Defaults to 7 days before endTime."`
+	EndTime   string `json:"endTime,omitempty" jsonschema:"End of the range (exclusive) in RFC3339 format. Defaults to now."`
+	GroupBy   string `json:"groupBy,omitempty" jsonschema:"Set to day to aggregate each day separately, in the time zone of startTime. Defaults to a single aggregate over the whole range."`
+	Standard  string `json:"standard,omitempty" jsonschema:"Air quality standard used for AQI statistics, e.g. qaqi, cn-mee or us-epa. Defaults to qaqi."`
+}
+
+// RecordedHistoryOutput output structure for query-recorded-history tool
+type RecordedHistoryOutput struct {
+	HistoryInfo string `json:"historyInfo" jsonschema:"Aggregated recorded conditions: temperature, humidity, wind and pressure min/max/mean, precipitation totals and AQI statistics"`
+}
+
+// formatHistorySummary renders the aggregates of a period, one quantity per line
+func formatHistorySummary(s recorder.Summary, standard, indent string) []string {
+	lines := []string{
+		fmt.Sprintf("%sSamples: %d (first %s, last %s)", indent, s.Samples, s.First.Format("2006-01-02 15:04"), s.Last.Format("2006-01-02 15:04")),
+	}
+	stat := func(label string, st recorder.Stat, unit string) {
+		if st.Count == 0 {
+			lines = append(lines, fmt.Sprintf("%s%s: no data", indent, label))
+			return
+		}
+		lines = append(lines, fmt.Sprintf("%s%s: min %.1f%s, max %.1f%s, mean %.1f%s", indent, label, st.Min, unit, st.Max, unit, st.Mean, unit))
+	}
+	stat("Temperature", s.Temp, "°C")
+	stat("Humidity", s.Humidity, "%")
+	stat("Wind Speed", s.WindSpeed, "km/h")
+	stat("Pressure", s.Pressure, "hPa")
+	lines = append(lines, fmt.Sprintf("%sPrecipitation: total %.1fmm, %d hour(s) with precipitation", indent, s.PrecipTotal, s.PrecipHours))
+	stat(fmt.Sprintf("AQI (%s)", standard), s.AQI, "")
+	return lines
+}
+
+func handleRecordedHistory(ctx context.Context, client *api.Client, store recorder.Store, input RecordedHistoryInput) (RecordedHistoryOutput, error) {
+	if input.CityName == "" {
+		return RecordedHistoryOutput{}, fmt.Errorf("city name cannot be empty")
+	}
+	if input.GroupBy != "" && input.GroupBy != historyGroupDay {
+		return RecordedHistoryOutput{}, fmt.Errorf("invalid groupBy parameter: must be day or empty")
+	}
+	if input.Standard == "" {
+		input.Standard = universalAQICode
+	}
+
+	end := time.Now()
+	if input.EndTime != "" {
+		t, err := time.Parse(time.RFC3339, input.EndTime)
+		if err != nil {
+			return RecordedHistoryOutput{}, fmt.Errorf("invalid endTime: must be RFC3339 (e.g. 2024-05-08T00:00:00+08:00)")
+		}
+		end = t
+	}
+	start := end.Add(-defaultHistoryRange)
+	if input.StartTime != "" {
+		t, err := time.Parse(time.RFC3339, input.StartTime)
+		if err != nil {
+			return RecordedHistoryOutput{}, fmt.Errorf("invalid startTime: must be RFC3339 (e.g. 2024-05-01T00:00:00+08:00)")
+		}
+		start = t
+	}
+	if !start.Before(end) {
+		return RecordedHistoryOutput{}, fmt.Errorf("startTime must be before endTime")
+	}
+	if end.Sub(start) > maxHistoryRange {
+		return RecordedHistoryOutput{}, fmt.Errorf("range cannot exceed 366 days")
+	}
+
+	location, err := resolveLocation(ctx, client, input.CityName)
+	if err != nil {
+		return RecordedHistoryOutput{}, err
+	}
+
+	observations, err := store.Query(location.ID, start, end)
+	if err != nil {
+		return RecordedHistoryOutput{}, fmt.Errorf("failed to query recorded history: %w", err)
+	}
+
+	historyText := []string{
+		fmt.Sprintf("Recorded History - %s (%s %s):", location.Name, location.Adm1, location.Adm2),
+		fmt.Sprintf("Range: %s to %s", start.Format("2006-01-02 15:04 MST"), end.Format("2006-01-02 15:04 MST")),
+		"",
+	}
+	if len(observations) == 0 {
+		historyText = append(historyText, "No observations were recorded for this location in the range. Only locations configured for the recorder are recorded.")
+		return RecordedHistoryOutput{HistoryInfo: strings.Join(historyText, "\n")}, nil
+	}
+
+	if input.GroupBy != historyGroupDay {
+		historyText = append(historyText, formatHistorySummary(recorder.Summarize(observations, input.Standard), input.Standard, "")...)
+		return RecordedHistoryOutput{HistoryInfo: strings.Join(historyText, "\n")}, nil
+	}
+
+	// Observations are ordered by time, so days come out in order
+	var days []string
+	byDay := make(map[string][]recorder.Observation)
+	for _, obs := range observations {
+		day := obs.Time.In(start.Location()).Format("2006-01-02")
+		if _, ok := byDay[day]; !ok {
+			days = append(days, day)
+		}
+		byDay[day] = append(byDay[day], obs)
+	}
+	for _, day := range days {
+		historyText = append(historyText, day+":")
+		historyText = append(historyText, formatHistorySummary(recorder.Summarize(byDay[day], input.Standard), input.Standard, "  ")...)
+		historyText = append(historyText, "---")
+	}
+
+	return RecordedHistoryOutput{HistoryInfo: strings.Join(historyText, "\n")}, nil
+}
+
+// RegisterHistoryTools Register recorded history tools
+func RegisterHistoryTools(s *mcp.Server, client *api.Client, store recorder.Store) {
+	// Recorded history query tool
+	mcp.AddTool(s, catalogTool[RecordedHistoryOutput]("query-recorded-history"), func(ctx context.Context, req *mcp.CallToolRequest, input RecordedHistoryInput) (*mcp.CallToolResult, RecordedHistoryOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, RecordedHistoryOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleRecordedHistory(ctx, client, store, input)
+		if err != nil {
+			return nil, RecordedHistoryOutput{}, err
+		}
+		return nil, out, nil
+	})
+}
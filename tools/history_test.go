@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+	"github.com/overstarry/qweather-mcp-go/recorder"
+)
+
+func TestHandleRecordedHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(api.LocationResponse{
+			Code: "200",
+			Location: []api.Location{{
+				Name: "Beijing", ID: "101010100", Lat: "39.90", Lon: "116.41", Adm1: "Beijing", Adm2: "Beijing",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	store, err := recorder.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	cst := time.FixedZone("CST", 8*3600)
+	for i, temp := range []float64{10, 20, 30} {
+		temp := temp
+		precip := 1.5
+		store.Append(recorder.Observation{
+			Time:       time.Date(2024, 5, 1, 22, 0, 0, 0, cst).Add(time.Duration(i) * time.Hour),
+			LocationID: "101010100",
+			Temp:       &temp,
+			Precip:     &precip,
+			AQI:        map[string]int{"qaqi": 2 * (i + 1)},
+		})
+	}
+
+	client := api.NewClient(server.URL, "test-key")
+	input := RecordedHistoryInput{CityName: "Beijing", StartTime: "2024-05-01T00:00:00+08:00", EndTime: "2024-05-03T00:00:00+08:00"}
+	out, err := handleRecordedHistory(context.Background(), client, store, input)
+	if err != nil {
+		t.Fatalf("handleRecordedHistory failed: %v", err)
+	}
+	for _, want := range []string{
+		"Samples: 3",
+		"Temperature: min 10.0°C, max 30.0°C, mean 20.0°C",
+		"Precipitation: total 4.5mm, 3 hour(s) with precipitation",
+		"AQI (qaqi): min 2.0, max 6.0, mean 4.0",
+		"Humidity: no data",
+	} {
+		if !strings.Contains(out.HistoryInfo, want) {
+			t.Fatalf("HistoryInfo = %q, want to contain %q", out.HistoryInfo, want)
+		}
+	}
+
+	input.GroupBy = "day"
+	out, err = handleRecordedHistory(context.Background(), client, store, input)
+	if err != nil {
+		t.Fatalf("handleRecordedHistory failed: %v", err)
+	}
+	for _, want := range []string{"2024-05-01:\n  Samples: 2", "2024-05-02:\n  Samples: 1"} {
+		if !strings.Contains(out.HistoryInfo, want) {
+			t.Fatalf("HistoryInfo = %q, want to contain %q", out.HistoryInfo, want)
+		}
+	}
+
+	input.StartTime = "2024-04-01T00:00:00+08:00"
+	input.EndTime = "2024-04-02T00:00:00+08:00"
+	out, err = handleRecordedHistory(context.Background(), client, store, input)
+	if err != nil {
+		t.Fatalf("handleRecordedHistory failed: %v", err)
+	}
+	if !strings.Contains(out.HistoryInfo, "No observations were recorded") {
+		t.Fatalf("HistoryInfo = %q, want a note that nothing was recorded", out.HistoryInfo)
+	}
+
+	for _, input := range []RecordedHistoryInput{
+		{},
+		{CityName: "Beijing", GroupBy: "week"},
+		{CityName: "Beijing", StartTime: "2024-05-02T00:00:00Z", EndTime: "2024-05-01T00:00:00Z"},
+		{CityName: "Beijing", StartTime: "2023-01-01T00:00:00Z", EndTime: "2024-05-01T00:00:00Z"},
+	} {
+		if _, err := handleRecordedHistory(context.Background(), client, store, input); err == nil {
+			t.Errorf("handleRecordedHistory(%+v) succeeded, want error", input)
+		}
+	}
+}
@@ -16,12 +16,20 @@ type WeatherIndicesInput struct {
 	Days     string `json:"days,omitempty" jsonschema:"Forecast duration: 1d (today) or 3d (3 days). Defaults to 1d if not specified."`
 }
 
+// Days and index types supported by the indices API, shared by validation and completion
+var (
+	indicesDays      = []string{"1d", "3d"}
+	validIndicesDays = valueSet(indicesDays)
+	indicesTypes     = []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13", "14", "15", "16"}
+	validIndicesType = valueSet(indicesTypes)
+)
+
 // WeatherIndicesOutput output structure for get-weather-indices tool
 type WeatherIndicesOutput struct {
 	IndicesInfo string `json:"indicesInfo" jsonschema:"Formatted weather life indices including UV, comfort, clothing suggestions, etc."`
 }
 
-func handleWeatherIndices(client *api.Client, input WeatherIndicesInput) (WeatherIndicesOutput, error) {
+func handleWeatherIndices(ctx context.Context, client *api.Client, input WeatherIndicesInput) (WeatherIndicesOutput, error) {
 	if input.CityName == "" {
 		return WeatherIndicesOutput{}, fmt.Errorf("city name cannot be empty")
 	}
@@ -34,7 +42,21 @@ func handleWeatherIndices(client *api.Client, input WeatherIndicesInput) (Weathe
 		input.Days = "1d"
 	}
 
-	locationData, err := client.GetLocationByName(input.CityName)
+	if !validIndicesDays[input.Days] {
+		return WeatherIndicesOutput{}, fmt.Errorf("invalid days parameter: must be one of 1d, 3d")
+	}
+
+	// Several types can be requested at once, separated by commas
+	indexTypes := strings.Split(input.Type, ",")
+	for i, indexType := range indexTypes {
+		indexTypes[i] = strings.TrimSpace(indexType)
+		if !validIndicesType[indexTypes[i]] {
+			return WeatherIndicesOutput{}, fmt.Errorf("invalid type parameter: must be index types 0 to 16, separated by commas")
+		}
+	}
+	input.Type = strings.Join(indexTypes, ",")
+
+	locationData, err := client.GetLocationByName(ctx, input.CityName)
 	if err != nil {
 		return WeatherIndicesOutput{}, fmt.Errorf("failed to query city: %w", err)
 	}
@@ -51,7 +73,7 @@ func handleWeatherIndices(client *api.Client, input WeatherIndicesInput) (Weathe
 	cityID := locationData.Location[0].ID
 	cityInfo := locationData.Location[0]
 
-	indicesData, err := client.GetWeatherIndices(cityID, input.Days, input.Type)
+	indicesData, err := client.GetWeatherIndices(ctx, cityID, input.Days, input.Type)
 	if err != nil {
 		return WeatherIndicesOutput{}, fmt.Errorf("failed to get weather indices data: %w", err)
 	}
@@ -89,29 +111,13 @@ func handleWeatherIndices(client *api.Client, input WeatherIndicesInput) (Weathe
 // RegisterIndicesTools Register weather indices related tools
 func RegisterIndicesTools(s *mcp.Server, client *api.Client) {
 	// Weather indices tool
-	mcp.AddTool(s, &mcp.Tool{
-		Name: "get-weather-indices",
-		Description: "Weather life indices forecast API provides various life indices for cities worldwide. Supports 1-day and 3-day forecasts. Available index types:\n\n" +
-			"- Type 0: All index types\n" +
-			"- Type 1: Sports (indicates suitability for outdoor sports activities)\n" +
-			"- Type 2: Car Washing (suggests whether it's suitable to wash cars)\n" +
-			"- Type 3: Dressing (provides clothing suggestions based on weather)\n" +
-			"- Type 4: Fishing (shows suitability of fishing conditions)\n" +
-			"- Type 5: UV (ultraviolet radiation intensity level)\n" +
-			"- Type 6: Travel (indicates suitability for travel and sightseeing)\n" +
-			"- Type 7: Allergy (allergy and pollen risk level)\n" +
-			"- Type 8: Cold (cold risk level)\n" +
-			"- Type 9: Comfort (overall comfort level of weather)\n" +
-			"- Type 10: Wind (wind conditions and their effects)\n" +
-			"- Type 11: Sunglasses (need for wearing sunglasses)\n" +
-			"- Type 12: Makeup (weather effects on makeup)\n" +
-			"- Type 13: Sunscreen (sunscreen needs)\n" +
-			"- Type 14: Traffic (weather effects on traffic conditions)\n" +
-			"- Type 15: Sports Watching (suitability for watching outdoor sports)\n" +
-			"- Type 16: Air Pollution Diffusion Conditions (air pollution diffusion conditions)\n\n" +
-			"Note: Not all cities provide all indices. International cities mainly support types 1, 2, 4, and 5.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input WeatherIndicesInput) (*mcp.CallToolResult, WeatherIndicesOutput, error) {
-		out, err := handleWeatherIndices(client, input)
+	mcp.AddTool(s, catalogTool[WeatherIndicesOutput]("get-weather-indices"), func(ctx context.Context, req *mcp.CallToolRequest, input WeatherIndicesInput) (*mcp.CallToolResult, WeatherIndicesOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, WeatherIndicesOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleWeatherIndices(ctx, client, input)
 		if err != nil {
 			return nil, WeatherIndicesOutput{}, err
 		}
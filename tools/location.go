@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// resolveLocation looks up a city by name and returns the best match
+func resolveLocation(ctx context.Context, client *api.Client, cityName string) (*api.Location, error) {
+	if cityName == "" {
+		return nil, fmt.Errorf("city name cannot be empty")
+	}
+
+	locationData, err := client.GetLocationByName(ctx, cityName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query city: %w", err)
+	}
+
+	if len(locationData.Location) == 0 {
+		return nil, fmt.Errorf("no matching city found")
+	}
+
+	return &locationData.Location[0], nil
+}
+
+// checkCode converts a non-success API code into an error
+func checkCode(code string) error {
+	if code != api.APICodeSuccess {
+		return fmt.Errorf("API returned error code: %s", code)
+	}
+	return nil
+}
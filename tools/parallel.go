@@ -0,0 +1,19 @@
+package tools
+
+import "sync"
+
+// runParallel calls fn for every index in [0, count) with at most limit calls running at once
+func runParallel(count, limit int, fn func(i int)) {
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// progressReporter sends MCP progress notifications for a tool call. Handlers receive a nil
+// reporter when the caller did not ask for progress; all methods are no-ops on nil.
+type progressReporter struct {
+	ctx     context.Context
+	session *mcp.ServerSession
+	token   any
+
+	mu      sync.Mutex
+	done    int
+	total   int
+	counts  map[string]int                    // Completed items by kind, for "fetched n/m" messages
+	queue   []*mcp.ProgressNotificationParams // Notifications waiting to be sent, in order
+	sending bool                              // A caller is sending the queue
+}
+
+// newProgressReporter returns a reporter for a tool call, or nil if the call has no progress token
+func newProgressReporter(ctx context.Context, req *mcp.CallToolRequest) *progressReporter {
+	if req == nil || req.Session == nil || req.Params == nil || req.Params.GetProgressToken() == nil {
+		return nil
+	}
+	return &progressReporter{ctx: ctx, session: req.Session, token: req.Params.GetProgressToken(), counts: make(map[string]int)}
+}
+
+// setTotal sets the number of steps the call is expected to take
+func (p *progressReporter) setTotal(total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total = total
+}
+
+// step reports a completed step
+func (p *progressReporter) step(format string, args ...any) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.advanceLocked(fmt.Sprintf(format, args...))
+	p.flushUnlock()
+}
+
+// fetched reports one more item of a kind completed out of of, e.g. "fetched 3/5 sources".
+// It is safe for concurrent use by parallel fetches.
+func (p *progressReporter) fetched(kind string, of int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.counts[kind]++
+	p.advanceLocked(fmt.Sprintf("fetched %d/%d %s", p.counts[kind], of, kind))
+	p.flushUnlock()
+}
+
+// advanceLocked advances the progress by one step and queues the notification reporting it.
+// Callers hold p.mu.
+func (p *progressReporter) advanceLocked(message string) {
+	p.done++
+	params := &mcp.ProgressNotificationParams{ProgressToken: p.token, Progress: float64(p.done), Message: message}
+	if p.total >= p.done {
+		params.Total = float64(p.total)
+	}
+	p.queue = append(p.queue, params)
+}
+
+// flushUnlock releases p.mu, then sends the queued notifications unless another caller already
+// is. Notifications are sent without holding the lock, so a slow client does not hold up
+// parallel fetches, and by one caller at a time, so they arrive in order. Delivery failures are
+// ignored since progress is advisory.
+func (p *progressReporter) flushUnlock() {
+	if p.sending {
+		p.mu.Unlock()
+		return
+	}
+	p.sending = true
+	for len(p.queue) > 0 {
+		params := p.queue[0]
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+		p.session.NotifyProgress(p.ctx, params)
+		p.mu.Lock()
+	}
+	p.sending = false
+	p.mu.Unlock()
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+func TestProgressNotifications(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/geo/v2/city/lookup" {
+			w.Write([]byte(`{"code":"200","location":[{"name":"Beijing","id":"101010100","lat":"39.90","lon":"116.41"}]}`))
+			return
+		}
+		w.Write([]byte(`{"code":"200"}`))
+	}))
+	defer upstream.Close()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	RegisterBriefingTools(server, api.NewClient(upstream.URL, "test-key"))
+
+	var mu sync.Mutex
+	var notes []*mcp.ProgressNotificationParams
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server Connect failed: %v", err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			notes = append(notes, req.Params)
+		},
+	}).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect failed: %v", err)
+	}
+	defer session.Close()
+
+	// SetProgressToken stores the token in an existing Meta map
+	params := &mcp.CallToolParams{Meta: mcp.Meta{}, Name: "get-weather-briefing", Arguments: map[string]any{"cityName": "Beijing"}}
+	params.SetProgressToken("briefing-1")
+	if _, err := session.CallTool(ctx, params); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	// Notifications are handled asynchronously by the client
+	want := briefingSources + 2
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(notes)
+		mu.Unlock()
+		if n >= want || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(notes) != want {
+		t.Fatalf("received %d progress notifications, want %d", len(notes), want)
+	}
+	var messages []string
+	for _, note := range notes {
+		if note.ProgressToken != "briefing-1" || note.Total != float64(want) {
+			t.Errorf("notification %+v, want token briefing-1 and total %d", note, want)
+		}
+		messages = append(messages, note.Message)
+	}
+	for _, message := range []string{"resolved location Beijing", "fetched 3/5 sources", "formatting"} {
+		if !slices.Contains(messages, message) {
+			t.Errorf("messages %q, want %q", messages, message)
+		}
+	}
+}
+
+func TestProgressReporter_Nil(t *testing.T) {
+	var progress *progressReporter
+	progress.setTotal(3)
+	progress.step("resolved location %s", "Beijing")
+	progress.fetched("sources", 3)
+	if newProgressReporter(context.Background(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{}}) != nil {
+		t.Fatal("expected no reporter without a progress token")
+	}
+}
@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// weatherPrompt a prompt template and the function rendering its instructions from the arguments
+type weatherPrompt struct {
+	prompt *mcp.Prompt
+	render func(args map[string]string) string
+}
+
+// weatherPrompts prompts registered by RegisterPrompts. Each one names the tools to call and
+// how to present their results, so every client gets the same workflow.
+var weatherPrompts = []weatherPrompt{
+	{
+		prompt: &mcp.Prompt{
+			Name:        "daily-briefing",
+			Title:       "Daily weather briefing",
+			Description: "Morning briefing of today's weather, warnings and air quality for a city",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "city", Description: "City to brief on (e.g. Beijing, London)", Required: true},
+			},
+		},
+		render: func(args map[string]string) string {
+			return strings.Join([]string{
+				fmt.Sprintf("Prepare a daily weather briefing for %s.", args["city"]),
+				"",
+				"Steps:",
+				fmt.Sprintf("1. Call get-weather-briefing with cityName %q. It returns current conditions, today's and tomorrow's forecast, active warnings, air quality and key life indices.", args["city"]),
+				fmt.Sprintf("2. If the briefing mentions rain today, call get-minutely-precipitation with cityName %q for the next two hours.", args["city"]),
+				"",
+				"Presentation:",
+				"- Start with one sentence summarising the day.",
+				"- Then list: temperature range, conditions, precipitation chance, wind, and air quality.",
+				"- Put any active weather warning first, in bold, with its validity period.",
+				"- End with one or two practical tips (umbrella, sunscreen, layers) drawn from the life indices.",
+				"- Keep it under 150 words and do not invent data the tools did not return.",
+			}, "\n")
+		},
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "travel-packing",
+			Title:       "Travel packing list",
+			Description: "Packing list for a trip based on the forecast at the destination",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "city", Description: "Destination city", Required: true},
+				{Name: "dates", Description: "Travel dates, e.g. 2024-05-01 to 2024-05-05", Required: true},
+			},
+		},
+		render: func(args map[string]string) string {
+			return strings.Join([]string{
+				fmt.Sprintf("Help me pack for a trip to %s on %s.", args["city"], args["dates"]),
+				"",
+				"Steps:",
+				fmt.Sprintf("1. Call get-weather-forecast with cityName %q and the shortest days value (3d, 7d, 10d, 15d or 30d) that covers the travel dates.", args["city"]),
+				fmt.Sprintf("2. Call get-weather-indices with cityName %q, type 3 (clothing) and days 3d.", args["city"]),
+				fmt.Sprintf("3. Call get-weather-warning with cityName %q.", args["city"]),
+				"",
+				"Presentation:",
+				"- Summarise the expected weather for each travel day in one line: date, temperature range, conditions.",
+				"- If some travel dates are beyond the forecast range, say so and base the advice on the last forecast days.",
+				"- Give a packing list grouped into clothing, rain and sun protection, and other items, each with a short reason tied to the forecast.",
+				"- Mention active warnings and how they might affect the trip.",
+			}, "\n")
+		},
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "outdoor-event-check",
+			Title:       "Outdoor event check",
+			Description: "Go/no-go assessment of the weather for an outdoor activity on a given date",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "city", Description: "City where the event takes place", Required: true},
+				{Name: "date", Description: "Date of the event, e.g. 2024-05-01", Required: true},
+				{Name: "activity", Description: "Planned activity, e.g. wedding, football match, hiking"},
+			},
+		},
+		render: func(args map[string]string) string {
+			activity := args["activity"]
+			if activity == "" {
+				activity = "an outdoor event"
+			}
+			return strings.Join([]string{
+				fmt.Sprintf("Assess whether the weather suits %s in %s on %s.", activity, args["city"], args["date"]),
+				"",
+				"Steps:",
+				fmt.Sprintf("1. Call get-hourly-forecast with cityName %q and hours 168h, and keep the hours of %s. If the date is further than 7 days away, call get-weather-forecast with days 15d or 30d instead.", args["city"], args["date"]),
+				fmt.Sprintf("2. Call find-activity-windows with cityName %q, noPrecipitation true and constraints suited to the activity, to find the best hours.", args["city"]),
+				fmt.Sprintf("3. Call get-weather-warning and get-air-quality with cityName %q.", args["city"]),
+				"",
+				"Presentation:",
+				"- Open with a verdict: Go, Go with precautions, or Reconsider.",
+				"- Justify it with the precipitation, temperature, wind and air quality expected during the event.",
+				"- Suggest the best time window on that date, and a backup plan if the verdict is not Go.",
+				"- Say how reliable the forecast is given how far away the date is.",
+			}, "\n")
+		},
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "air-quality-health-advice",
+			Title:       "Air quality health advice",
+			Description: "Health advice on current and upcoming air quality, optionally for a sensitive group",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "city", Description: "City to check air quality for", Required: true},
+				{Name: "sensitive_group", Description: "Optional group to tailor the advice to, e.g. children, elderly, asthma, pregnancy"},
+			},
+		},
+		render: func(args map[string]string) string {
+			audience := "the general population"
+			if group := args["sensitive_group"]; group != "" {
+				audience = group
+			}
+			return strings.Join([]string{
+				fmt.Sprintf("Give air quality health advice for %s, tailored to %s.", args["city"], audience),
+				"",
+				"Steps:",
+				fmt.Sprintf("1. Call get-air-quality with cityName %q for the current AQI, the primary pollutant and the health recommendations.", args["city"]),
+				fmt.Sprintf("2. Call get-air-quality-hourly with cityName %q to see how the AQI develops over the next 24 hours.", args["city"]),
+				"",
+				"Presentation:",
+				"- State the current AQI, its category and the primary pollutant.",
+				fmt.Sprintf("- Give concrete advice for %s: outdoor exercise, windows and ventilation, masks, and medication if relevant.", audience),
+				"- Point out the best and worst hours of the next day for outdoor time.",
+				"- Use the health recommendations returned by the tool rather than generic advice, and do not give a medical diagnosis.",
+			}, "\n")
+		},
+	},
+}
+
+// handlePrompt checks the required arguments of a prompt and renders it as a user message
+func handlePrompt(prompt weatherPrompt, args map[string]string) (*mcp.GetPromptResult, error) {
+	trimmed := make(map[string]string, len(args))
+	for name, value := range args {
+		trimmed[name] = strings.TrimSpace(value)
+	}
+	for _, arg := range prompt.prompt.Arguments {
+		if arg.Required && trimmed[arg.Name] == "" {
+			return nil, fmt.Errorf("%s argument cannot be empty", arg.Name)
+		}
+	}
+
+	return &mcp.GetPromptResult{
+		Description: prompt.prompt.Description,
+		Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: prompt.render(trimmed)}},
+		},
+	}, nil
+}
+
+// RegisterPrompts Register prompts for common weather workflows
+func RegisterPrompts(s *mcp.Server) {
+	for _, prompt := range weatherPrompts {
+		s.AddPrompt(prompt.prompt, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return handlePrompt(prompt, req.Params.Arguments)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+	"github.com/overstarry/qweather-mcp-go/snapshot"
+)
+
+func TestRegisterPrompts(t *testing.T) {
+	client := api.NewClient("http://localhost", "test-key")
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	RegisterWeatherTools(server, client)
+	RegisterAirQualityTools(server, client)
+	RegisterIndicesTools(server, client)
+	RegisterBriefingTools(server, client)
+	RegisterActivityTools(server, client)
+	RegisterForecastChangeTools(server, client, snapshot.NewMemoryStore(0))
+	RegisterPrompts(server)
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server Connect failed: %v", err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect failed: %v", err)
+	}
+	defer session.Close()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	registered := make(map[string]bool)
+	for _, tool := range tools.Tools {
+		registered[tool.Name] = true
+	}
+
+	prompts, err := session.ListPrompts(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+	if len(prompts.Prompts) != len(weatherPrompts) {
+		t.Fatalf("listed %d prompts, want %d", len(prompts.Prompts), len(weatherPrompts))
+	}
+
+	// Every tool a prompt tells the model to call must exist
+	toolName := regexp.MustCompile(`[Cc]all ([a-z-]+)(?: and ([a-z-]+))?`)
+	for _, prompt := range prompts.Prompts {
+		args := map[string]string{}
+		for _, arg := range prompt.Arguments {
+			args[arg.Name] = "Beijing"
+		}
+		result, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: prompt.Name, Arguments: args})
+		if err != nil {
+			t.Fatalf("GetPrompt(%s) failed: %v", prompt.Name, err)
+		}
+		text := result.Messages[0].Content.(*mcp.TextContent).Text
+		if !strings.Contains(text, "Beijing") {
+			t.Errorf("%s prompt does not mention the city:\n%s", prompt.Name, text)
+		}
+		for _, match := range toolName.FindAllStringSubmatch(text, -1) {
+			for _, name := range match[1:] {
+				if name != "" && !registered[name] {
+					t.Errorf("%s prompt refers to unknown tool %s", prompt.Name, name)
+				}
+			}
+		}
+	}
+}
+
+func TestHandlePrompt_MissingArgument(t *testing.T) {
+	for _, prompt := range weatherPrompts {
+		if prompt.prompt.Name != "travel-packing" {
+			continue
+		}
+		if _, err := handlePrompt(prompt, map[string]string{"city": "Beijing", "dates": "  "}); err == nil {
+			t.Fatal("expected error for a blank required argument")
+		}
+		result, err := handlePrompt(prompt, map[string]string{"city": " Beijing ", "dates": "2024-05-01 to 2024-05-05"})
+		if err != nil {
+			t.Fatalf("handlePrompt failed: %v", err)
+		}
+		if text := result.Messages[0].Content.(*mcp.TextContent).Text; !strings.Contains(text, `cityName "Beijing"`) {
+			t.Errorf("prompt text = %s, want the trimmed city", text)
+		}
+	}
+}
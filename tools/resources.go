@@ -0,0 +1,226 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+	"github.com/overstarry/qweather-mcp-go/watcher"
+	"github.com/yosida95/uritemplate/v3"
+)
+
+// Resource representations, selected with the format query parameter
+const (
+	resourceFormatJSON = "json"
+	resourceFormatText = "text"
+)
+
+// locationResource weather data of a location exposed as a resource template. The JSON
+// representation is the raw API response; the text one is the output of the matching tool.
+type locationResource struct {
+	name        string
+	uriTemplate string   // Must contain {id}; a query expression with format and query is appended
+	query       []string // Optional query parameters besides format
+	description string
+	json        func(ctx context.Context, client *api.Client, id string, values uritemplate.Values) (any, error)
+	text        func(ctx context.Context, client *api.Client, id string, values uritemplate.Values) (string, error)
+}
+
+// locationResources resources registered by RegisterResources. Text representations look the
+// location up by ID, which the QWeather GeoAPI accepts in place of a city name.
+var locationResources = []locationResource{
+	{
+		name:        "location-now",
+		uriTemplate: "qweather://location/{id}/now",
+		description: "Current weather of a location.",
+		json: func(ctx context.Context, client *api.Client, id string, _ uritemplate.Values) (any, error) {
+			weatherData, err := client.GetWeatherNow(ctx, id)
+			if err == nil {
+				err = checkCode(weatherData.Code)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get real-time weather data: %w", err)
+			}
+			return weatherData, nil
+		},
+		text: func(ctx context.Context, client *api.Client, id string, _ uritemplate.Values) (string, error) {
+			out, err := handleWeatherNow(ctx, client, WeatherNowInput{CityName: id})
+			return out.WeatherInfo, err
+		},
+	},
+	{
+		name:        "location-forecast",
+		uriTemplate: "qweather://location/{id}/forecast/{days}",
+		description: "Daily weather forecast of a location; days is one of 3d, 7d, 10d, 15d or 30d.",
+		json: func(ctx context.Context, client *api.Client, id string, values uritemplate.Values) (any, error) {
+			days := values.Get("days").String()
+			if !validForecastDays[days] {
+				return nil, fmt.Errorf("invalid days parameter: must be one of 3d, 7d, 10d, 15d, 30d")
+			}
+			weatherData, err := client.GetWeatherForecast(ctx, id, days)
+			if err == nil {
+				err = checkCode(weatherData.Code)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get weather forecast data: %w", err)
+			}
+			return weatherData, nil
+		},
+		text: func(ctx context.Context, client *api.Client, id string, values uritemplate.Values) (string, error) {
+			out, err := handleWeatherForecast(ctx, client, WeatherForecastInput{CityName: id, Days: values.Get("days").String()})
+			return out.ForecastInfo, err
+		},
+	},
+	{
+		name:        "location-hourly",
+		uriTemplate: "qweather://location/{id}/hourly/{hours}",
+		description: "Hourly weather forecast of a location; hours is one of 24h, 72h or 168h.",
+		json: func(ctx context.Context, client *api.Client, id string, values uritemplate.Values) (any, error) {
+			hours := values.Get("hours").String()
+			if !validHourlyHours[hours] {
+				return nil, fmt.Errorf("invalid hours parameter: must be one of 24h, 72h, 168h")
+			}
+			hourlyData, err := client.GetHourlyForecast(ctx, id, hours)
+			if err == nil {
+				err = checkCode(hourlyData.Code)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get hourly weather forecast data: %w", err)
+			}
+			return hourlyData, nil
+		},
+		text: func(ctx context.Context, client *api.Client, id string, values uritemplate.Values) (string, error) {
+			out, err := handleHourlyForecast(ctx, client, HourlyForecastInput{CityName: id, Hours: values.Get("hours").String()})
+			return out.HourlyInfo, err
+		},
+	},
+	{
+		name:        "location-warnings",
+		uriTemplate: watcher.WarningsURITemplate,
+		description: "Active weather warnings of a location. Subscribe to be notified when warnings are issued, updated or cancelled.",
+		json: func(ctx context.Context, client *api.Client, id string, _ uritemplate.Values) (any, error) {
+			warningData, err := client.GetWeatherWarning(ctx, id)
+			if err == nil {
+				err = checkCode(warningData.Code)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get weather warning data: %w", err)
+			}
+			return warningData, nil
+		},
+		text: func(ctx context.Context, client *api.Client, id string, _ uritemplate.Values) (string, error) {
+			out, err := handleWeatherWarning(ctx, client, WeatherWarningInput{CityName: id})
+			return out.WarningInfo, err
+		},
+	},
+	{
+		name:        "location-indices",
+		uriTemplate: "qweather://location/{id}/indices/{days}",
+		query:       []string{"type"},
+		description: "Weather life indices of a location; days is 1d or 3d, and the optional type query selects index types 0 to 16 (0 for all, the default).",
+		json: func(ctx context.Context, client *api.Client, id string, values uritemplate.Values) (any, error) {
+			days, indexType := values.Get("days").String(), values.Get("type").String()
+			if indexType == "" {
+				indexType = "0"
+			}
+			if !validIndicesDays[days] || !validIndicesType[indexType] {
+				return nil, fmt.Errorf("invalid indices parameters: days must be 1d or 3d and type an index type from 0 to 16")
+			}
+			indicesData, err := client.GetWeatherIndices(ctx, id, days, indexType)
+			if err == nil {
+				err = checkCode(indicesData.Code)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get weather indices data: %w", err)
+			}
+			return indicesData, nil
+		},
+		text: func(ctx context.Context, client *api.Client, id string, values uritemplate.Values) (string, error) {
+			out, err := handleWeatherIndices(ctx, client, WeatherIndicesInput{CityName: id, Days: values.Get("days").String(), Type: values.Get("type").String()})
+			return out.IndicesInfo, err
+		},
+	},
+	{
+		name:        "location-air",
+		uriTemplate: "qweather://location/{id}/air",
+		description: "Real-time air quality of a location.",
+		json: func(ctx context.Context, client *api.Client, id string, _ uritemplate.Values) (any, error) {
+			location, err := resolveLocation(ctx, client, id)
+			if err != nil {
+				return nil, err
+			}
+			lat, lon := location.Coordinates()
+			airQualityData, err := client.GetAirQuality(ctx, lat, lon)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get air quality data: %w", err)
+			}
+			return airQualityData, nil
+		},
+		text: func(ctx context.Context, client *api.Client, id string, _ uritemplate.Values) (string, error) {
+			out, err := handleAirQuality(ctx, client, AirQualityInput{CityName: id})
+			return out.AirQualityInfo, err
+		},
+	},
+}
+
+// template returns the URI template of the resource including its query parameters
+func (r locationResource) template() string {
+	return r.uriTemplate + "{?" + strings.Join(append(slices.Clone(r.query), "format"), ",") + "}"
+}
+
+// handleLocationResource reads a location resource in the format requested by the URI
+func handleLocationResource(ctx context.Context, client *api.Client, resource locationResource, uri string) (*mcp.ReadResourceResult, error) {
+	tmpl, err := uritemplate.New(resource.template())
+	if err != nil {
+		return nil, err
+	}
+	values := tmpl.Match(uri)
+	id := values.Get("id").String()
+	if id == "" {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	format := values.Get("format").String()
+	switch format {
+	case "", resourceFormatJSON:
+		data, err := resource.json(ctx, client, id, values)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s resource: %w", resource.name, err)
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: string(encoded)}},
+		}, nil
+	case resourceFormatText:
+		text, err := resource.text(ctx, client, id, values)
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "text/plain", Text: text}},
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid format %q: must be %s or %s", format, resourceFormatJSON, resourceFormatText)
+	}
+}
+
+// RegisterResources Register location resources
+func RegisterResources(s *mcp.Server, client *api.Client) {
+	for _, resource := range locationResources {
+		s.AddResourceTemplate(&mcp.ResourceTemplate{
+			Name:        resource.name,
+			URITemplate: resource.template(),
+			Description: resource.description + " The id is a QWeather location ID (e.g. 101010100). Returned as the JSON API response by default, or as readable text with ?format=text.",
+			MIMEType:    "application/json",
+		}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return handleLocationResource(ctx, client, resource, req.Params.URI)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+func newResourceTestServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			if r.URL.Query().Get("location") != "101010100" {
+				w.Write([]byte(`{"code":"404","location":[]}`))
+				return
+			}
+			w.Write([]byte(`{"code":"200","location":[{"name":"Beijing","id":"101010100","lat":"39.90","lon":"116.41","adm1":"Beijing","adm2":"Beijing"}]}`))
+		case "/v7/warning/now":
+			json.NewEncoder(w).Encode(api.WarningResponse{Code: "200", Warning: []api.Warning{{ID: "w1", Title: "Rainstorm Blue Warning"}}})
+		case "/v7/weather/now":
+			w.Write([]byte(`{"code":"200","updateTime":"2024-05-01T12:00+08:00","now":{"temp":"21","feelsLike":"20","text":"Cloudy","humidity":"40"}}`))
+		case "/v7/weather/7d":
+			w.Write([]byte(`{"code":"200","daily":[{"fxDate":"2024-05-01","tempMax":"25","tempMin":"14"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// findLocationResource returns the registered location resource with the given name
+func findLocationResource(t *testing.T, name string) locationResource {
+	for _, resource := range locationResources {
+		if resource.name == name {
+			return resource
+		}
+	}
+	t.Fatalf("no location resource named %s", name)
+	return locationResource{}
+}
+
+func TestHandleLocationResource_JSON(t *testing.T) {
+	client := api.NewClient(newResourceTestServer(t).URL, "test-key")
+	uri := "qweather://location/101010100/warnings"
+	result, err := handleLocationResource(context.Background(), client, findLocationResource(t, "location-warnings"), uri)
+	if err != nil {
+		t.Fatalf("handleLocationResource failed: %v", err)
+	}
+	if len(result.Contents) != 1 || result.Contents[0].URI != uri || result.Contents[0].MIMEType != "application/json" {
+		t.Fatalf("Contents = %+v, want one JSON document for %s", result.Contents, uri)
+	}
+	var warnings api.WarningResponse
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &warnings); err != nil || len(warnings.Warning) != 1 {
+		t.Fatalf("resource text = %s, want the warning response", result.Contents[0].Text)
+	}
+
+	if _, err := handleLocationResource(context.Background(), client, findLocationResource(t, "location-warnings"), "qweather://location/101010100/unknown"); err == nil {
+		t.Fatal("expected error for an unknown resource")
+	}
+	if _, err := handleLocationResource(context.Background(), client, findLocationResource(t, "location-forecast"), "qweather://location/101010100/forecast/5d"); err == nil {
+		t.Fatal("expected error for unsupported forecast days")
+	}
+	if _, err := handleLocationResource(context.Background(), client, findLocationResource(t, "location-now"), "qweather://location/101010100/now?format=xml"); err == nil {
+		t.Fatal("expected error for an unknown format")
+	}
+}
+
+func TestHandleLocationResource_Text(t *testing.T) {
+	client := api.NewClient(newResourceTestServer(t).URL, "test-key")
+	uri := "qweather://location/101010100/forecast/7d?format=text"
+	result, err := handleLocationResource(context.Background(), client, findLocationResource(t, "location-forecast"), uri)
+	if err != nil {
+		t.Fatalf("handleLocationResource failed: %v", err)
+	}
+	if len(result.Contents) != 1 || result.Contents[0].MIMEType != "text/plain" {
+		t.Fatalf("Contents = %+v, want one text document", result.Contents)
+	}
+	if text := result.Contents[0].Text; !strings.Contains(text, "7 Day Weather Forecast - Beijing") || !strings.Contains(text, "Temperature: 14°C ~ 25°C") {
+		t.Errorf("resource text = %s, want the formatted forecast", text)
+	}
+}
+
+func TestRegisterResources(t *testing.T) {
+	client := api.NewClient(newResourceTestServer(t).URL, "test-key")
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	RegisterResources(server, client)
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server Connect failed: %v", err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect failed: %v", err)
+	}
+	defer session.Close()
+
+	templates, err := session.ListResourceTemplates(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResourceTemplates failed: %v", err)
+	}
+	if len(templates.ResourceTemplates) != len(locationResources) {
+		t.Fatalf("listed %d resource templates, want %d", len(templates.ResourceTemplates), len(locationResources))
+	}
+
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "qweather://location/101010100/now?format=text"})
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if text := result.Contents[0].Text; !strings.Contains(text, "Current Weather - Beijing") || !strings.Contains(text, "Temperature: 21°C") {
+		t.Errorf("resource text = %s, want the formatted current weather", text)
+	}
+}
@@ -0,0 +1,241 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// Limits and thresholds for the get-route-weather tool
+const (
+	maxRouteWaypoints     = 20               // Maximum number of waypoints in a single route
+	routeFetchParallelism = 4                // Maximum number of waypoints fetched concurrently
+	strongWindScale       = 6                // Wind force level from which a leg is flagged (strong breeze)
+	maxForecastDistance   = 90 * time.Minute // Maximum gap between arrival time and the matched hourly entry
+)
+
+// RouteWaypoint a stop along the route
+type RouteWaypoint struct {
+	Location    string `json:"location" jsonschema:"City name or coordinates as longitude,latitude (e.g. Tianjin or 117.20,39.13)"`
+	ArrivalTime string `json:"arrivalTime,omitempty" jsonschema:"Expected arrival time at this waypoint in RFC3339 format (e.g. 2024-05-01T14:30:00+08:00). Takes precedence over legMinutes."`
+	LegMinutes  int    `json:"legMinutes,omitempty" jsonschema:"Travel time in minutes from the previous waypoint. Used when arrivalTime is not given; ignored for the first waypoint."`
+}
+
+// RouteWeatherInput input parameters for get-route-weather tool
+type RouteWeatherInput struct {
+	Waypoints     []RouteWaypoint `json:"waypoints" jsonschema:"Ordered waypoints of the route, starting with the departure point (2 to 20)"`
+	DepartureTime string          `json:"departureTime,omitempty" jsonschema:"Departure time from the first waypoint in RFC3339 format. Defaults to now."`
+}
+
+// RouteWeatherOutput output structure for get-route-weather tool
+type RouteWeatherOutput struct {
+	RouteInfo string `json:"routeInfo" jsonschema:"Forecast at each waypoint for the expected arrival time, with hazardous legs flagged"`
+}
+
+// routeStop weather fetched for one waypoint at its arrival time
+type routeStop struct {
+	query    string
+	arrival  time.Time
+	location *api.Location
+	err      error
+	hourly   *api.HourlyResponse
+	hour     int // index into hourly.Hourly, -1 if no entry matches the arrival time
+	warning  *api.WarningResponse
+}
+
+// label returns the display name of the waypoint
+func (r *routeStop) label() string {
+	if r.location == nil {
+		return r.query
+	}
+	return r.location.Name
+}
+
+// routeArrivalTimes computes the arrival time at every waypoint
+func routeArrivalTimes(input RouteWeatherInput) ([]time.Time, error) {
+	departure := time.Now()
+	if input.DepartureTime != "" {
+		t, err := time.Parse(time.RFC3339, input.DepartureTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid departureTime: must be RFC3339 (e.g. 2024-05-01T08:00:00+08:00)")
+		}
+		departure = t
+	}
+
+	arrivals := make([]time.Time, len(input.Waypoints))
+	for i, wp := range input.Waypoints {
+		switch {
+		case wp.ArrivalTime != "":
+			t, err := time.Parse(time.RFC3339, wp.ArrivalTime)
+			if err != nil {
+				return nil, fmt.Errorf("invalid arrivalTime for waypoint %d: must be RFC3339", i+1)
+			}
+			arrivals[i] = t
+		case i == 0:
+			arrivals[i] = departure
+		case wp.LegMinutes > 0:
+			arrivals[i] = arrivals[i-1].Add(time.Duration(wp.LegMinutes) * time.Minute)
+		default:
+			return nil, fmt.Errorf("waypoint %d needs either arrivalTime or a positive legMinutes", i+1)
+		}
+		if i > 0 && arrivals[i].Before(arrivals[i-1]) {
+			return nil, fmt.Errorf("waypoint %d arrives before waypoint %d", i+1, i)
+		}
+	}
+	return arrivals, nil
+}
+
+// fetchRouteStop resolves a waypoint and fetches its hourly forecast and active warnings
+func fetchRouteStop(ctx context.Context, client *api.Client, query string, arrival time.Time) *routeStop {
+	stop := &routeStop{query: query, arrival: arrival, hour: -1}
+
+	stop.location, stop.err = resolveLocation(ctx, client, query)
+	if stop.err != nil {
+		return stop
+	}
+
+	stop.hourly, stop.err = client.GetHourlyForecast(ctx, stop.location.ID, hourlyRangeFor(arrival))
+	if stop.err == nil {
+		stop.err = checkCode(stop.hourly.Code)
+	}
+	if stop.err != nil {
+		stop.err = fmt.Errorf("failed to get hourly weather forecast data: %w", stop.err)
+		return stop
+	}
+	if i, diff := closestHourly(stop.hourly, arrival); i >= 0 && diff <= maxForecastDistance {
+		stop.hour = i
+	}
+
+	// Warnings are best effort: a failure only means no warning flags for this stop
+	if warning, err := client.GetWeatherWarning(ctx, stop.location.ID); err == nil && warning.Code == api.APICodeSuccess {
+		stop.warning = warning
+	}
+	return stop
+}
+
+// routeHazards returns the hazards expected when arriving at a stop
+func routeHazards(stop *routeStop) []string {
+	var hazards []string
+	if stop.hour >= 0 {
+		hour := stop.hourly.Hourly[stop.hour]
+		precip, _ := strconv.ParseFloat(hour.Precip, 64)
+		switch iconGroup(hour.Icon) {
+		case iconGroupSnow:
+			hazards = append(hazards, "snow")
+		case iconGroupRain:
+			hazards = append(hazards, "rain")
+		case iconGroupHaze:
+			hazards = append(hazards, fmt.Sprintf("low visibility (%s)", hour.Text))
+		default:
+			if precip > 0 {
+				hazards = append(hazards, "rain")
+			}
+		}
+		if scale, ok := parseWindScale(hour.WindScale); ok && scale >= strongWindScale {
+			hazards = append(hazards, fmt.Sprintf("strong wind (force %s)", hour.WindScale))
+		}
+	}
+	if stop.warning != nil {
+		for _, warning := range stop.warning.Warning {
+			hazards = append(hazards, fmt.Sprintf("active warning: %s", warning.Title))
+		}
+	}
+	return hazards
+}
+
+func handleRouteWeather(ctx context.Context, client *api.Client, input RouteWeatherInput, progress *progressReporter) (RouteWeatherOutput, error) {
+	if len(input.Waypoints) < 2 {
+		return RouteWeatherOutput{}, fmt.Errorf("at least two waypoints are required")
+	}
+	if len(input.Waypoints) > maxRouteWaypoints {
+		return RouteWeatherOutput{}, fmt.Errorf("too many waypoints: at most %d are supported", maxRouteWaypoints)
+	}
+	for i, wp := range input.Waypoints {
+		if wp.Location == "" {
+			return RouteWeatherOutput{}, fmt.Errorf("location of waypoint %d cannot be empty", i+1)
+		}
+	}
+
+	arrivals, err := routeArrivalTimes(input)
+	if err != nil {
+		return RouteWeatherOutput{}, err
+	}
+
+	// One step per waypoint, then formatting
+	progress.setTotal(len(input.Waypoints) + 1)
+	stops := make([]*routeStop, len(input.Waypoints))
+	runParallel(len(stops), routeFetchParallelism, func(i int) {
+		stops[i] = fetchRouteStop(ctx, client, input.Waypoints[i].Location, arrivals[i])
+		progress.fetched("waypoints", len(stops))
+	})
+
+	progress.step("formatting")
+
+	routeText := []string{
+		fmt.Sprintf("Route Weather - %d waypoints, departing %s:", len(stops), arrivals[0].Format("2006-01-02 15:04 MST")),
+		"",
+	}
+
+	var hazardLegs []string
+	for i, stop := range stops {
+		leg := "Departure"
+		if i > 0 {
+			leg = fmt.Sprintf("Leg %d (%s → %s)", i, stops[i-1].label(), stop.label())
+		}
+
+		stopInfo := []string{fmt.Sprintf("%d. %s - arrival %s", i+1, stop.label(), stop.arrival.Format("2006-01-02 15:04 MST"))}
+		switch {
+		case stop.err != nil:
+			stopInfo = append(stopInfo, fmt.Sprintf("  Weather unavailable: %v", stop.err))
+		case stop.hour < 0:
+			stopInfo = append(stopInfo, "  No hourly forecast available for the arrival time")
+		default:
+			hour := stop.hourly.Hourly[stop.hour]
+			stopInfo = append(stopInfo,
+				fmt.Sprintf("  Forecast for %s: %s°C, %s", hour.FxTime, hour.Temp, hour.Text),
+				fmt.Sprintf("  Precipitation: %smm  Wind: %s Force %s (%skm/h)", hour.Precip, hour.WindDir, hour.WindScale, hour.WindSpeed),
+			)
+		}
+
+		hazards := routeHazards(stop)
+		if len(hazards) > 0 {
+			stopInfo = append(stopInfo, fmt.Sprintf("  Hazards: %s", strings.Join(hazards, ", ")))
+			hazardLegs = append(hazardLegs, fmt.Sprintf("- %s: %s", leg, strings.Join(hazards, ", ")))
+		}
+		routeText = append(routeText, strings.Join(stopInfo, "\n"))
+	}
+
+	routeText = append(routeText, "")
+	if len(hazardLegs) == 0 {
+		routeText = append(routeText, "No weather hazards detected along the route")
+	} else {
+		routeText = append(routeText, "Legs with weather hazards:")
+		routeText = append(routeText, hazardLegs...)
+	}
+
+	return RouteWeatherOutput{RouteInfo: strings.Join(routeText, "\n")}, nil
+}
+
+// RegisterRouteTools Register route weather tools
+func RegisterRouteTools(s *mcp.Server, client *api.Client) {
+	// Route weather tool
+	mcp.AddTool(s, catalogTool[RouteWeatherOutput]("get-route-weather"), func(ctx context.Context, req *mcp.CallToolRequest, input RouteWeatherInput) (*mcp.CallToolResult, RouteWeatherOutput, error) {
+		for i, waypoint := range input.Waypoints {
+			location, err := chooseLocation(ctx, req.Session, client, waypoint.Location)
+			if err != nil {
+				return nil, RouteWeatherOutput{}, err
+			}
+			input.Waypoints[i].Location = location
+		}
+		out, err := handleRouteWeather(ctx, client, input, newProgressReporter(ctx, req))
+		if err != nil {
+			return nil, RouteWeatherOutput{}, err
+		}
+		return nil, out, nil
+	})
+}
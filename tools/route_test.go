@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+func TestRouteArrivalTimes(t *testing.T) {
+	arrivals, err := routeArrivalTimes(RouteWeatherInput{
+		DepartureTime: "2024-05-01T08:00:00+08:00",
+		Waypoints: []RouteWaypoint{
+			{Location: "Beijing"},
+			{Location: "Tianjin", LegMinutes: 90},
+			{Location: "Jinan", ArrivalTime: "2024-05-01T14:00:00+08:00"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("routeArrivalTimes failed: %v", err)
+	}
+	want := []string{"08:00", "09:30", "14:00"}
+	for i, arrival := range arrivals {
+		if got := arrival.Format("15:04"); got != want[i] {
+			t.Errorf("arrival %d = %s, want %s", i, got, want[i])
+		}
+	}
+
+	_, err = routeArrivalTimes(RouteWeatherInput{
+		Waypoints: []RouteWaypoint{{Location: "Beijing"}, {Location: "Tianjin"}},
+	})
+	if err == nil {
+		t.Fatal("expected error for waypoint without arrival time or leg duration, got nil")
+	}
+}
+
+func TestHandleRouteWeather_FlagsHazards(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/geo/v2/city/lookup":
+			name := r.URL.Query().Get("location")
+			json.NewEncoder(w).Encode(api.LocationResponse{
+				Code:     "200",
+				Location: []api.Location{{Name: name, ID: name}},
+			})
+		case strings.HasPrefix(r.URL.Path, "/v7/weather/"):
+			if r.URL.Query().Get("location") == "Tianjin" {
+				w.Write([]byte(`{"code":"200","hourly":[
+					{"fxTime":"2024-05-01T09:00+08:00","temp":"15","icon":"305","text":"Light Rain","windScale":"3","precip":"0.5"},
+					{"fxTime":"2024-05-01T10:00+08:00","temp":"14","icon":"101","text":"Cloudy","windScale":"6-7","precip":"0.0"}]}`))
+				return
+			}
+			w.Write([]byte(`{"code":"200","hourly":[{"fxTime":"2024-05-01T08:00+08:00","temp":"18","icon":"100","text":"Sunny","windScale":"2","precip":"0.0"}]}`))
+		case r.URL.Path == "/v7/warning/now":
+			w.Write([]byte(`{"code":"200","warning":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "test-key")
+	out, err := handleRouteWeather(context.Background(), client, RouteWeatherInput{
+		DepartureTime: "2024-05-01T08:00:00+08:00",
+		Waypoints: []RouteWaypoint{
+			{Location: "Beijing"},
+			{Location: "Tianjin", LegMinutes: 100},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("handleRouteWeather failed: %v", err)
+	}
+	for _, want := range []string{
+		"Forecast for 2024-05-01T08:00+08:00: 18°C, Sunny",
+		"Forecast for 2024-05-01T10:00+08:00: 14°C, Cloudy",
+		"- Leg 1 (Beijing → Tianjin): strong wind (force 6-7)",
+	} {
+		if !strings.Contains(out.RouteInfo, want) {
+			t.Fatalf("RouteInfo = %q, want to contain %q", out.RouteInfo, want)
+		}
+	}
+	if strings.Contains(out.RouteInfo, "- Departure") {
+		t.Fatalf("RouteInfo flags the departure point without hazards: %q", out.RouteInfo)
+	}
+}
+
+func TestClosestHourly(t *testing.T) {
+	hourly := &api.HourlyResponse{}
+	json.Unmarshal([]byte(`{"hourly":[{"fxTime":"2024-05-01T09:00+08:00"},{"fxTime":"2024-05-01T10:00+08:00"}]}`), hourly)
+
+	arrival := time.Date(2024, 5, 1, 1, 40, 0, 0, time.UTC) // 09:40 +08:00
+	i, diff := closestHourly(hourly, arrival)
+	if i != 1 || diff != 20*time.Minute {
+		t.Fatalf("closestHourly = (%d, %v), want (1, 20m)", i, diff)
+	}
+}
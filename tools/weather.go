@@ -3,15 +3,19 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/overstarry/qweather-mcp-go/api"
+	"github.com/overstarry/qweather-mcp-go/meteo"
 )
 
 // WeatherNowInput input parameters for get-weather-now tool
 type WeatherNowInput struct {
-	CityName string `json:"cityName" jsonschema:"Name of the city to query current weather for. Can be in any language (e.g. Beijing, 北京, New York, London)"`
+	CityName    string `json:"cityName" jsonschema:"Name of the city to query current weather for. Can be in any language (e.g. Beijing, 北京, New York, London)"`
+	WindUnit    string `json:"windUnit,omitempty" jsonschema:"Unit for wind speed: km/h (default), m/s, mph or knots"`
+	WindDetails bool   `json:"windDetails,omitempty" jsonschema:"Include wind direction in degrees and on the 16-point compass, and the Beaufort force with its description"`
 }
 
 // WeatherNowOutput output structure for get-weather-now tool
@@ -21,8 +25,10 @@ type WeatherNowOutput struct {
 
 // WeatherForecastInput input parameters for get-weather-forecast tool
 type WeatherForecastInput struct {
-	CityName string `json:"cityName" jsonschema:"Name of the city to query weather forecast for (e.g. Beijing, New York, Tokyo)"`
-	Days     string `json:"days" jsonschema:"Number of forecast days. Valid values: 3d (3 days), 7d (7 days), 10d (10 days), 15d (15 days), or 30d (30 days)"`
+	CityName    string `json:"cityName" jsonschema:"Name of the city to query weather forecast for (e.g. Beijing, New York, Tokyo)"`
+	Days        string `json:"days" jsonschema:"Number of forecast days. Valid values: 3d (3 days), 7d (7 days), 10d (10 days), 15d (15 days), or 30d (30 days)"`
+	WindUnit    string `json:"windUnit,omitempty" jsonschema:"Unit for wind speed: km/h (default), m/s, mph or knots"`
+	WindDetails bool   `json:"windDetails,omitempty" jsonschema:"Include wind direction in degrees and on the 16-point compass, and the Beaufort force with its description"`
 }
 
 // WeatherForecastOutput output structure for get-weather-forecast tool
@@ -42,8 +48,10 @@ type MinutelyPrecipitationOutput struct {
 
 // HourlyForecastInput input parameters for get-hourly-forecast tool
 type HourlyForecastInput struct {
-	CityName string `json:"cityName" jsonschema:"Name of the city to query hourly weather forecast for"`
-	Hours    string `json:"hours,omitempty" jsonschema:"Number of hours to forecast. Valid values: 24h (1 day), 72h (3 days), or 168h (7 days). Defaults to 24h if not specified."`
+	CityName    string `json:"cityName" jsonschema:"Name of the city to query hourly weather forecast for"`
+	Hours       string `json:"hours,omitempty" jsonschema:"Number of hours to forecast. Valid values: 24h (1 day), 72h (3 days), or 168h (7 days). Defaults to 24h if not specified."`
+	WindUnit    string `json:"windUnit,omitempty" jsonschema:"Unit for wind speed: km/h (default), m/s, mph or knots"`
+	WindDetails bool   `json:"windDetails,omitempty" jsonschema:"Include wind direction in degrees and on the 16-point compass, and the Beaufort force with its description"`
 }
 
 // HourlyForecastOutput output structure for get-hourly-forecast tool
@@ -61,11 +69,25 @@ type WeatherWarningOutput struct {
 	WarningInfo string `json:"warningInfo" jsonschema:"Formatted weather warning information"`
 }
 
-func handleWeatherNow(client *api.Client, input WeatherNowInput) (WeatherNowOutput, error) {
+// Forecast lengths supported by the daily and hourly forecast APIs, shared by validation and completion
+var (
+	forecastDays      = []string{"3d", "7d", "10d", "15d", "30d"}
+	validForecastDays = valueSet(forecastDays)
+	hourlyHours       = []string{"24h", "72h", "168h"}
+	validHourlyHours  = valueSet(hourlyHours)
+)
+
+func handleWeatherNow(ctx context.Context, client *api.Client, input WeatherNowInput) (WeatherNowOutput, error) {
 	if input.CityName == "" {
 		return WeatherNowOutput{}, fmt.Errorf("city name cannot be empty")
 	}
-	locationData, err := client.GetLocationByName(input.CityName)
+
+	windOpts, err := newWindOptions(input.WindUnit, input.WindDetails)
+	if err != nil {
+		return WeatherNowOutput{}, err
+	}
+
+	locationData, err := client.GetLocationByName(ctx, input.CityName)
 	if err != nil {
 		return WeatherNowOutput{}, fmt.Errorf("failed to query city: %w", err)
 	}
@@ -81,7 +103,7 @@ func handleWeatherNow(client *api.Client, input WeatherNowInput) (WeatherNowOutp
 	cityID := locationData.Location[0].ID
 	cityInfo := locationData.Location[0]
 
-	weatherData, err := client.GetWeatherNow(cityID)
+	weatherData, err := client.GetWeatherNow(ctx, cityID)
 	if err != nil {
 		return WeatherNowOutput{}, fmt.Errorf("failed to get real-time weather data: %w", err)
 	}
@@ -91,23 +113,33 @@ func handleWeatherNow(client *api.Client, input WeatherNowInput) (WeatherNowOutp
 	}
 
 	now := weatherData.Now
-	weatherText := make([]string, 0, 10)
-	weatherText = []string{
+	weatherText := []string{
 		fmt.Sprintf("Current Weather - %s (%s %s):", cityInfo.Name, cityInfo.Adm1, cityInfo.Adm2),
 		fmt.Sprintf("Temperature: %s°C (Feels like: %s°C)", now.Temp, now.FeelsLike),
 		fmt.Sprintf("Weather Condition: %s", now.Text),
 		fmt.Sprintf("Wind Direction: %s Wind Force: %s", now.WindDir, now.WindScale),
+	}
+	if now.WindSpeed != "" {
+		weatherText = append(weatherText, fmt.Sprintf("Wind Speed: %s", formatWindSpeed(now.WindSpeed, windOpts)))
+	}
+	if windOpts.detailed {
+		weatherText = append(weatherText, fmt.Sprintf("Wind: %s", formatWindDetails(now.WindDir, now.Wind360, now.WindSpeed, windOpts)))
+	}
+	weatherText = append(weatherText,
 		fmt.Sprintf("Humidity: %s%%", now.Humidity),
 		fmt.Sprintf("Precipitation: %smm", now.Precip),
 		fmt.Sprintf("Pressure: %shPa", now.Pressure),
 		fmt.Sprintf("Visibility: %skm", now.Vis),
-		fmt.Sprintf("Last Updated: %s", weatherData.UpdateTime),
-	}
+	)
+
+	weatherText = append(weatherText, derivedMetrics(now.Temp, now.Humidity, now.WindSpeed, now.Dew)...)
+
+	weatherText = append(weatherText, fmt.Sprintf("Last Updated: %s", weatherData.UpdateTime))
 
 	return WeatherNowOutput{WeatherInfo: strings.Join(weatherText, "\n")}, nil
 }
 
-func handleWeatherForecast(client *api.Client, input WeatherForecastInput) (WeatherForecastOutput, error) {
+func handleWeatherForecast(ctx context.Context, client *api.Client, input WeatherForecastInput) (WeatherForecastOutput, error) {
 	if input.CityName == "" {
 		return WeatherForecastOutput{}, fmt.Errorf("city name cannot be empty")
 	}
@@ -116,12 +148,16 @@ func handleWeatherForecast(client *api.Client, input WeatherForecastInput) (Weat
 		input.Days = "3d"
 	}
 
-	validDays := map[string]bool{"3d": true, "7d": true, "10d": true, "15d": true, "30d": true}
-	if !validDays[input.Days] {
+	if !validForecastDays[input.Days] {
 		return WeatherForecastOutput{}, fmt.Errorf("invalid days parameter: must be one of 3d, 7d, 10d, 15d, 30d")
 	}
 
-	locationData, err := client.GetLocationByName(input.CityName)
+	windOpts, err := newWindOptions(input.WindUnit, input.WindDetails)
+	if err != nil {
+		return WeatherForecastOutput{}, err
+	}
+
+	locationData, err := client.GetLocationByName(ctx, input.CityName)
 	if err != nil {
 		return WeatherForecastOutput{}, fmt.Errorf("failed to query city: %w", err)
 	}
@@ -137,7 +173,7 @@ func handleWeatherForecast(client *api.Client, input WeatherForecastInput) (Weat
 	cityID := locationData.Location[0].ID
 	cityInfo := locationData.Location[0]
 
-	weatherData, err := client.GetWeatherForecast(cityID, input.Days)
+	weatherData, err := client.GetWeatherForecast(ctx, cityID, input.Days)
 	if err != nil {
 		return WeatherForecastOutput{}, fmt.Errorf("failed to get weather forecast data: %w", err)
 	}
@@ -163,21 +199,31 @@ func handleWeatherForecast(client *api.Client, input WeatherForecastInput) (Weat
 			fmt.Sprintf("Precipitation: %smm", day.Precip),
 			fmt.Sprintf("Humidity: %s%%", day.Humidity),
 			fmt.Sprintf("Wind: Day-%s(Force %s), Night-%s(Force %s)", day.WindDirDay, day.WindScaleDay, day.WindDirNight, day.WindScaleNight),
+		}
+		if windOpts.detailed {
+			dayForecast = append(dayForecast,
+				fmt.Sprintf("Wind (Day): %s", formatWindDetails(day.WindDirDay, day.Wind360Day, day.WindSpeedDay, windOpts)),
+				fmt.Sprintf("Wind (Night): %s", formatWindDetails(day.WindDirNight, day.Wind360Night, day.WindSpeedNight, windOpts)),
+			)
+		} else if input.WindUnit != "" {
+			dayForecast = append(dayForecast, fmt.Sprintf("Wind Speed: Day-%s, Night-%s", formatWindSpeed(day.WindSpeedDay, windOpts), formatWindSpeed(day.WindSpeedNight, windOpts)))
+		}
+		dayForecast = append(dayForecast,
 			fmt.Sprintf("UV Index: %s", day.UvIndex),
 			"---",
-		}
+		)
 		forecastText = append(forecastText, strings.Join(dayForecast, "\n"))
 	}
 
 	return WeatherForecastOutput{ForecastInfo: strings.Join(forecastText, "\n")}, nil
 }
 
-func handleMinutelyPrecipitation(client *api.Client, input MinutelyPrecipitationInput) (MinutelyPrecipitationOutput, error) {
+func handleMinutelyPrecipitation(ctx context.Context, client *api.Client, input MinutelyPrecipitationInput) (MinutelyPrecipitationOutput, error) {
 	if input.CityName == "" {
 		return MinutelyPrecipitationOutput{}, fmt.Errorf("city name cannot be empty")
 	}
 
-	locationData, err := client.GetLocationByName(input.CityName)
+	locationData, err := client.GetLocationByName(ctx, input.CityName)
 	if err != nil {
 		return MinutelyPrecipitationOutput{}, fmt.Errorf("failed to query city: %w", err)
 	}
@@ -193,7 +239,7 @@ func handleMinutelyPrecipitation(client *api.Client, input MinutelyPrecipitation
 	cityInfo := locationData.Location[0]
 	location := fmt.Sprintf("%s,%s", cityInfo.Lon, cityInfo.Lat)
 
-	precipData, err := client.GetMinutelyPrecipitation(location)
+	precipData, err := client.GetMinutelyPrecipitation(ctx, location)
 	if err != nil {
 		return MinutelyPrecipitationOutput{}, fmt.Errorf("failed to get minutely precipitation forecast data: %w", err)
 	}
@@ -224,7 +270,7 @@ func handleMinutelyPrecipitation(client *api.Client, input MinutelyPrecipitation
 	return MinutelyPrecipitationOutput{PrecipitationInfo: strings.Join(precipText, "\n")}, nil
 }
 
-func handleHourlyForecast(client *api.Client, input HourlyForecastInput) (HourlyForecastOutput, error) {
+func handleHourlyForecast(ctx context.Context, client *api.Client, input HourlyForecastInput) (HourlyForecastOutput, error) {
 	if input.CityName == "" {
 		return HourlyForecastOutput{}, fmt.Errorf("city name cannot be empty")
 	}
@@ -233,12 +279,16 @@ func handleHourlyForecast(client *api.Client, input HourlyForecastInput) (Hourly
 		input.Hours = "24h"
 	}
 
-	validHours := map[string]bool{"24h": true, "72h": true, "168h": true}
-	if !validHours[input.Hours] {
+	if !validHourlyHours[input.Hours] {
 		return HourlyForecastOutput{}, fmt.Errorf("invalid hours parameter: must be one of 24h, 72h, 168h")
 	}
 
-	locationData, err := client.GetLocationByName(input.CityName)
+	windOpts, err := newWindOptions(input.WindUnit, input.WindDetails)
+	if err != nil {
+		return HourlyForecastOutput{}, err
+	}
+
+	locationData, err := client.GetLocationByName(ctx, input.CityName)
 	if err != nil {
 		return HourlyForecastOutput{}, fmt.Errorf("failed to query city: %w", err)
 	}
@@ -254,7 +304,7 @@ func handleHourlyForecast(client *api.Client, input HourlyForecastInput) (Hourly
 	cityID := locationData.Location[0].ID
 	cityInfo := locationData.Location[0]
 
-	hourlyData, err := client.GetHourlyForecast(cityID, input.Hours)
+	hourlyData, err := client.GetHourlyForecast(ctx, cityID, input.Hours)
 	if err != nil {
 		return HourlyForecastOutput{}, fmt.Errorf("failed to get hourly weather forecast data: %w", err)
 	}
@@ -275,19 +325,29 @@ func handleHourlyForecast(client *api.Client, input HourlyForecastInput) (Hourly
 			fmt.Sprintf("Time: %s", timeStr),
 			fmt.Sprintf("Temperature: %s°C", hour.Temp),
 			fmt.Sprintf("Weather: %s", hour.Text),
-			fmt.Sprintf("Wind Direction: %s (Force %s, %skm/h)", hour.WindDir, hour.WindScale, hour.WindSpeed),
+			fmt.Sprintf("Wind Direction: %s (Force %s, %s)", hour.WindDir, hour.WindScale, formatWindSpeed(hour.WindSpeed, windOpts)),
+		}
+		if windOpts.detailed {
+			hourForecast = append(hourForecast, fmt.Sprintf("Wind: %s", formatWindDetails(hour.WindDir, hour.Wind360, hour.WindSpeed, windOpts)))
+		}
+		hourForecast = append(hourForecast,
 			fmt.Sprintf("Humidity: %s%%", hour.Humidity),
 			fmt.Sprintf("Precipitation: %smm", hour.Precip),
 			fmt.Sprintf("Pressure: %shPa", hour.Pressure),
-		}
+		)
 
 		if hour.Cloud != "" {
 			hourForecast = append(hourForecast, fmt.Sprintf("Cloud Cover: %s%%", hour.Cloud))
 		}
 
-		if hour.Dew != "" {
-			hourForecast = append(hourForecast, fmt.Sprintf("Dew Point: %s°C", hour.Dew))
+		// The hourly forecast has no feels-like temperature
+		temp, tempErr := strconv.ParseFloat(hour.Temp, 64)
+		humidity, humidityErr := strconv.ParseFloat(hour.Humidity, 64)
+		windSpeed, _ := strconv.ParseFloat(hour.WindSpeed, 64)
+		if tempErr == nil && humidityErr == nil {
+			hourForecast = append(hourForecast, fmt.Sprintf("Feels Like: %.0f°C (derived)", meteo.FeelsLike(temp, humidity, windSpeed)))
 		}
+		hourForecast = append(hourForecast, derivedMetrics(hour.Temp, hour.Humidity, hour.WindSpeed, hour.Dew)...)
 
 		hourForecast = append(hourForecast, "---")
 		hourlyText = append(hourlyText, strings.Join(hourForecast, "\n"))
@@ -296,12 +356,12 @@ func handleHourlyForecast(client *api.Client, input HourlyForecastInput) (Hourly
 	return HourlyForecastOutput{HourlyInfo: strings.Join(hourlyText, "\n")}, nil
 }
 
-func handleWeatherWarning(client *api.Client, input WeatherWarningInput) (WeatherWarningOutput, error) {
+func handleWeatherWarning(ctx context.Context, client *api.Client, input WeatherWarningInput) (WeatherWarningOutput, error) {
 	if input.CityName == "" {
 		return WeatherWarningOutput{}, fmt.Errorf("city name cannot be empty")
 	}
 
-	locationData, err := client.GetLocationByName(input.CityName)
+	locationData, err := client.GetLocationByName(ctx, input.CityName)
 	if err != nil {
 		return WeatherWarningOutput{}, fmt.Errorf("failed to query city: %w", err)
 	}
@@ -317,7 +377,7 @@ func handleWeatherWarning(client *api.Client, input WeatherWarningInput) (Weathe
 	cityID := locationData.Location[0].ID
 	cityInfo := locationData.Location[0]
 
-	warningData, err := client.GetWeatherWarning(cityID)
+	warningData, err := client.GetWeatherWarning(ctx, cityID)
 	if err != nil {
 		return WeatherWarningOutput{}, fmt.Errorf("failed to get weather warning data: %w", err)
 	}
@@ -364,14 +424,57 @@ func handleWeatherWarning(client *api.Client, input WeatherWarningInput) (Weathe
 	return WeatherWarningOutput{WarningInfo: strings.Join(warningText, "\n")}, nil
 }
 
+// derivedMetrics returns the dew point, as reported or else derived, and the comfort metrics
+// QWeather does not provide, from the temperature, relative humidity, wind speed in km/h and
+// dew point of a weather response. The heat index, wind chill, humidex and wet-bulb temperature
+// are only included under the conditions they are defined or meaningful for.
+func derivedMetrics(tempText, humidityText, windSpeedText, dewText string) []string {
+	temp, tempErr := strconv.ParseFloat(tempText, 64)
+	humidity, humidityErr := strconv.ParseFloat(humidityText, 64)
+	known := tempErr == nil && humidityErr == nil && humidity > 0
+
+	var lines []string
+	dewPoint, dewErr := strconv.ParseFloat(dewText, 64)
+	switch {
+	case dewErr == nil:
+		lines = append(lines, fmt.Sprintf("Dew Point: %s°C", dewText))
+	case known:
+		dewPoint = meteo.DewPoint(temp, humidity)
+		lines = append(lines, fmt.Sprintf("Dew Point: %.0f°C (derived)", dewPoint))
+	}
+	if !known {
+		return lines
+	}
+
+	windSpeed, _ := strconv.ParseFloat(windSpeedText, 64)
+	windMs, _ := meteo.ConvertWindSpeed(windSpeed, meteo.SpeedMs)
+	metrics := []string{fmt.Sprintf("apparent temperature %.0f°C", meteo.ApparentTemperature(temp, humidity, windMs))}
+	if temp >= 27 {
+		metrics = append(metrics, fmt.Sprintf("heat index %.0f°C", meteo.HeatIndex(temp, humidity)))
+	}
+	if temp <= 10 && windSpeed > 4.8 {
+		metrics = append(metrics, fmt.Sprintf("wind chill %.0f°C", meteo.WindChill(temp, windSpeed)))
+	}
+	if temp >= 20 {
+		metrics = append(metrics, fmt.Sprintf("humidex %.0f", meteo.Humidex(temp, dewPoint)))
+	}
+	if temp >= -20 && temp <= 50 && humidity >= 5 && humidity <= 99 {
+		metrics = append(metrics, fmt.Sprintf("wet-bulb %.0f°C", meteo.WetBulb(temp, humidity)))
+	}
+	metrics = append(metrics, fmt.Sprintf("absolute humidity %.1f g/m³", meteo.AbsoluteHumidity(temp, humidity)))
+	return append(lines, "Comfort (derived): "+strings.Join(metrics, ", "))
+}
+
 // RegisterWeatherTools Register weather-related tools
 func RegisterWeatherTools(s *mcp.Server, client *api.Client) {
 	// Real-time weather tool
-	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get-weather-now",
-		Description: "Real-time weather API provides current weather conditions for cities worldwide. Available data includes: temperature, feels-like temperature, weather conditions, wind direction, wind force level, relative humidity, precipitation, atmospheric pressure, and visibility. Data is updated in real-time, providing the most accurate current weather information.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input WeatherNowInput) (*mcp.CallToolResult, WeatherNowOutput, error) {
-		out, err := handleWeatherNow(client, input)
+	mcp.AddTool(s, catalogTool[WeatherNowOutput]("get-weather-now"), func(ctx context.Context, req *mcp.CallToolRequest, input WeatherNowInput) (*mcp.CallToolResult, WeatherNowOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, WeatherNowOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleWeatherNow(ctx, client, input)
 		if err != nil {
 			return nil, WeatherNowOutput{}, err
 		}
@@ -379,11 +482,13 @@ func RegisterWeatherTools(s *mcp.Server, client *api.Client) {
 	})
 
 	// Weather forecast tool
-	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get-weather-forecast",
-		Description: "Weather forecast API provides detailed weather predictions for cities worldwide, supporting forecasts from 3 to 30 days. Available data includes: sunrise/sunset times, moonrise/moonset times, temperature range, weather conditions, wind direction and speed, relative humidity, precipitation, atmospheric pressure, cloud cover, and UV index. Forecasts are updated daily to ensure accuracy.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input WeatherForecastInput) (*mcp.CallToolResult, WeatherForecastOutput, error) {
-		out, err := handleWeatherForecast(client, input)
+	mcp.AddTool(s, catalogTool[WeatherForecastOutput]("get-weather-forecast"), func(ctx context.Context, req *mcp.CallToolRequest, input WeatherForecastInput) (*mcp.CallToolResult, WeatherForecastOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, WeatherForecastOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleWeatherForecast(ctx, client, input)
 		if err != nil {
 			return nil, WeatherForecastOutput{}, err
 		}
@@ -391,11 +496,13 @@ func RegisterWeatherTools(s *mcp.Server, client *api.Client) {
 	})
 
 	// Minutely precipitation forecast tool
-	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get-minutely-precipitation",
-		Description: "Minutely precipitation forecast API provides accurate precipitation predictions for the next 2 hours for cities worldwide. Available data includes precipitation type (rain/snow) and amount for each minute. This high-precision forecast is particularly useful for outdoor activity planning and real-time weather monitoring.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input MinutelyPrecipitationInput) (*mcp.CallToolResult, MinutelyPrecipitationOutput, error) {
-		out, err := handleMinutelyPrecipitation(client, input)
+	mcp.AddTool(s, catalogTool[MinutelyPrecipitationOutput]("get-minutely-precipitation"), func(ctx context.Context, req *mcp.CallToolRequest, input MinutelyPrecipitationInput) (*mcp.CallToolResult, MinutelyPrecipitationOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, MinutelyPrecipitationOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleMinutelyPrecipitation(ctx, client, input)
 		if err != nil {
 			return nil, MinutelyPrecipitationOutput{}, err
 		}
@@ -403,11 +510,13 @@ func RegisterWeatherTools(s *mcp.Server, client *api.Client) {
 	})
 
 	// Hourly weather forecast tool
-	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get-hourly-forecast",
-		Description: "Hourly weather forecast API provides detailed weather information for the next 24-168 hours for cities worldwide. Available data includes: temperature, weather conditions, wind force, wind speed, wind direction, relative humidity, atmospheric pressure, precipitation probability, dew point temperature, and cloud cover. Forecast data is updated hourly to ensure accuracy.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input HourlyForecastInput) (*mcp.CallToolResult, HourlyForecastOutput, error) {
-		out, err := handleHourlyForecast(client, input)
+	mcp.AddTool(s, catalogTool[HourlyForecastOutput]("get-hourly-forecast"), func(ctx context.Context, req *mcp.CallToolRequest, input HourlyForecastInput) (*mcp.CallToolResult, HourlyForecastOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, HourlyForecastOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleHourlyForecast(ctx, client, input)
 		if err != nil {
 			return nil, HourlyForecastOutput{}, err
 		}
@@ -415,11 +524,13 @@ func RegisterWeatherTools(s *mcp.Server, client *api.Client) {
 	})
 
 	// Weather warning tool
-	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get-weather-warning",
-		Description: "Weather warning API provides real-time weather warning data issued by official agencies in China and multiple countries/regions worldwide. Data includes warning issuing agency, publication time, warning title, detailed warning information, warning level, warning type, and other relevant information.",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input WeatherWarningInput) (*mcp.CallToolResult, WeatherWarningOutput, error) {
-		out, err := handleWeatherWarning(client, input)
+	mcp.AddTool(s, catalogTool[WeatherWarningOutput]("get-weather-warning"), func(ctx context.Context, req *mcp.CallToolRequest, input WeatherWarningInput) (*mcp.CallToolResult, WeatherWarningOutput, error) {
+		cityName, err := chooseLocation(ctx, req.Session, client, input.CityName)
+		if err != nil {
+			return nil, WeatherWarningOutput{}, err
+		}
+		input.CityName = cityName
+		out, err := handleWeatherWarning(ctx, client, input)
 		if err != nil {
 			return nil, WeatherWarningOutput{}, err
 		}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -228,3 +229,33 @@ func TestWeatherWarningInput_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestDerivedMetrics(t *testing.T) {
+	tests := []struct {
+		name                      string
+		temp, humidity, wind, dew string
+		want                      []string
+	}{
+		{"reported dew point", "20", "50", "10", "12", []string{
+			"Dew Point: 12°C",
+			"Comfort (derived): apparent temperature 18°C, humidex 22, wet-bulb 14°C, absolute humidity 8.6 g/m³",
+		}},
+		{"hot and humid", "32", "60", "10", "", []string{
+			"Dew Point: 23°C (derived)",
+			"Comfort (derived): apparent temperature 35°C, heat index 37°C, humidex 43, wet-bulb 26°C, absolute humidity 20.2 g/m³",
+		}},
+		{"cold and windy", "-10", "50", "20", "", []string{
+			"Dew Point: -18°C (derived)",
+			"Comfort (derived): apparent temperature -17°C, wind chill -18°C, wet-bulb -12°C, absolute humidity 1.2 g/m³",
+		}},
+		{"missing humidity", "20", "", "10", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := derivedMetrics(tt.temp, tt.humidity, tt.wind, tt.dew)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("derivedMetrics = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/overstarry/qweather-mcp-go/meteo"
+)
+
+// windOptions how wind is rendered by the weather formatters
+type windOptions struct {
+	unit     string
+	detailed bool
+}
+
+// newWindOptions validates the wind output options of a tool input
+func newWindOptions(unit string, detailed bool) (windOptions, error) {
+	if unit == "" {
+		unit = meteo.SpeedKmh
+	}
+	if _, ok := meteo.ConvertWindSpeed(0, unit); !ok {
+		return windOptions{}, fmt.Errorf("invalid windUnit parameter: must be one of km/h, m/s, mph, knots")
+	}
+	return windOptions{unit: unit, detailed: detailed}, nil
+}
+
+// formatWindSpeed converts a km/h speed reported by QWeather to the requested unit
+func formatWindSpeed(speedKmh string, opts windOptions) string {
+	speed, err := strconv.ParseFloat(speedKmh, 64)
+	if err != nil {
+		return speedKmh + "km/h"
+	}
+	converted, _ := meteo.ConvertWindSpeed(speed, opts.unit)
+	if opts.unit == meteo.SpeedKmh {
+		return fmt.Sprintf("%.0f%s", converted, opts.unit)
+	}
+	return fmt.Sprintf("%.1f%s", converted, opts.unit)
+}
+
+// formatWindDetails renders direction in degrees and on the 16-point compass, the Beaufort
+// force with its description and the speed in the requested unit, e.g.
+// "North 350° (N), Force 3 Gentle breeze, 15km/h"
+func formatWindDetails(dirText, degrees, speedKmh string, opts windOptions) string {
+	direction := dirText
+	if deg, err := strconv.ParseFloat(degrees, 64); err == nil {
+		direction = fmt.Sprintf("%s %s° (%s)", dirText, degrees, meteo.CompassPoint(deg))
+	}
+	speed, err := strconv.ParseFloat(speedKmh, 64)
+	if err != nil {
+		return direction
+	}
+	force := meteo.Beaufort(speed)
+	return fmt.Sprintf("%s, Force %d %s, %s", direction, force, meteo.BeaufortDescription(force), formatWindSpeed(speedKmh, opts))
+}
@@ -0,0 +1,284 @@
+// Package watcher polls weather warnings for the locations MCP sessions have subscribed to
+// and pushes new, updated and cancelled warnings to those sessions.
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+// WarningsURITemplate URI template of the subscribable warnings resource of a location
+const WarningsURITemplate = "qweather://location/{id}/warnings"
+
+// DefaultInterval time between two polls of a Watcher created with a non-positive interval
+const DefaultInterval = 5 * time.Minute
+
+// Logger name used for warning log messages sent to sessions
+const loggerName = "qweather-warnings"
+
+// Event types
+const (
+	EventNew       = "new"
+	EventUpdated   = "updated"
+	EventCancelled = "cancelled"
+)
+
+// warningCancelStatus status of a warning that has been withdrawn by its issuer
+const warningCancelStatus = "cancel"
+
+// Event a change to the warnings of a location
+type Event struct {
+	Type       string      `json:"type"`
+	LocationID string      `json:"locationId"`
+	URI        string      `json:"uri"`
+	Warning    api.Warning `json:"warning"`
+}
+
+// WarningsURI returns the resource URI of the warnings of a location
+func WarningsURI(locationID string) string {
+	return strings.Replace(WarningsURITemplate, "{id}", locationID, 1)
+}
+
+// ParseWarningsURI returns the location ID of a warnings resource URI. A query, such as the
+// format selector of the resource, is ignored.
+func ParseWarningsURI(uri string) (string, bool) {
+	uri, _, _ = strings.Cut(uri, "?")
+	prefix, suffix, _ := strings.Cut(WarningsURITemplate, "{id}")
+	id, ok := strings.CutPrefix(uri, prefix)
+	if !ok {
+		return "", false
+	}
+	id, ok = strings.CutSuffix(id, suffix)
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return "", false
+	}
+	return id, true
+}
+
+// Watcher tracks warning subscriptions and notifies subscribed sessions of changes.
+// Its Subscribe and Unsubscribe methods are meant to be used as the handlers of mcp.ServerOptions.
+type Watcher struct {
+	client   *api.Client
+	interval time.Duration
+
+	mu          sync.Mutex
+	subscribers map[string]map[*mcp.ServerSession]map[string]bool // Subscribed URIs by location ID and session
+	known       map[string]map[string]api.Warning                 // Last seen warnings by location ID and warning ID
+}
+
+// New creates a watcher polling on the given interval
+func New(client *api.Client, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Watcher{
+		client:      client,
+		interval:    interval,
+		subscribers: make(map[string]map[*mcp.ServerSession]map[string]bool),
+		known:       make(map[string]map[string]api.Warning),
+	}
+}
+
+// Subscribe starts watching the location of a warnings resource for the requesting session.
+// The URI is kept as sent, since updates are only delivered for the exact URIs subscribed to,
+// which may select a format. The warnings of a newly watched location are fetched right away, so
+// that the first poll reports those issued since; if they cannot be, the first poll reports every
+// warning in force as new.
+func (w *Watcher) Subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	locationID, ok := ParseWarningsURI(req.Params.URI)
+	if !ok {
+		return fmt.Errorf("subscriptions are only supported for %s resources", WarningsURITemplate)
+	}
+
+	w.mu.Lock()
+	_, known := w.known[locationID]
+	w.mu.Unlock()
+	var baseline map[string]api.Warning
+	if !known {
+		current, err := w.warnings(ctx, locationID)
+		if err != nil {
+			slog.WarnContext(ctx, "Warning watcher: failed to get weather warnings", "location", locationID, "error", err)
+		}
+		baseline = warningsByID(current)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, known := w.known[locationID]; !known && baseline != nil {
+		w.known[locationID] = baseline
+	}
+	if w.subscribers[locationID] == nil {
+		w.subscribers[locationID] = make(map[*mcp.ServerSession]map[string]bool)
+	}
+	if w.subscribers[locationID][req.Session] == nil {
+		w.subscribers[locationID][req.Session] = make(map[string]bool)
+	}
+	w.subscribers[locationID][req.Session][req.Params.URI] = true
+	return nil
+}
+
+// Unsubscribe stops watching a warnings resource for the requesting session, and its location
+// once the session has no subscriptions to it left
+func (w *Watcher) Unsubscribe(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	locationID, ok := ParseWarningsURI(req.Params.URI)
+	if !ok {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.subscribers[locationID][req.Session], req.Params.URI)
+	if len(w.subscribers[locationID][req.Session]) == 0 {
+		w.removeSession(locationID, req.Session)
+	}
+	return nil
+}
+
+// removeSession drops a subscription, forgetting the location once nobody watches it. Callers hold w.mu.
+func (w *Watcher) removeSession(locationID string, session *mcp.ServerSession) {
+	delete(w.subscribers[locationID], session)
+	if len(w.subscribers[locationID]) == 0 {
+		delete(w.subscribers, locationID)
+		delete(w.known, locationID)
+	}
+}
+
+// Run polls on every interval until the context is cancelled
+func (w *Watcher) Run(ctx context.Context, server *mcp.Server) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Poll(ctx, server)
+		}
+	}
+}
+
+// Poll fetches the warnings of every watched location once and notifies subscribers of changes
+func (w *Watcher) Poll(ctx context.Context, server *mcp.Server) {
+	// Sessions that disconnected without unsubscribing are dropped
+	live := make(map[*mcp.ServerSession]bool)
+	for session := range server.Sessions() {
+		live[session] = true
+	}
+
+	w.mu.Lock()
+	var locations []string
+	for locationID, sessions := range w.subscribers {
+		for session := range sessions {
+			if !live[session] {
+				w.removeSession(locationID, session)
+			}
+		}
+		if len(w.subscribers[locationID]) > 0 {
+			locations = append(locations, locationID)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, locationID := range locations {
+		current, err := w.warnings(ctx, locationID)
+		if err != nil {
+			slog.Warn("Warning watcher: failed to get weather warnings", "location", locationID, "error", err)
+			continue
+		}
+
+		w.mu.Lock()
+		var events []Event
+		if previous, seen := w.known[locationID]; seen {
+			events = DiffWarnings(locationID, previous, current)
+		}
+		var sessions []*mcp.ServerSession
+		uris := make(map[string]bool)
+		if _, watched := w.subscribers[locationID]; watched {
+			w.known[locationID] = warningsByID(current)
+			for session, subscribed := range w.subscribers[locationID] {
+				sessions = append(sessions, session)
+				for uri := range subscribed {
+					uris[uri] = true
+				}
+			}
+		}
+		w.mu.Unlock()
+
+		if len(events) == 0 || len(sessions) == 0 {
+			continue
+		}
+		for uri := range uris {
+			server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri})
+		}
+		for _, event := range events {
+			for _, session := range sessions {
+				session.Log(ctx, &mcp.LoggingMessageParams{Level: "warning", Logger: loggerName, Data: event})
+			}
+		}
+	}
+}
+
+// warnings fetches the warnings of a location by ID
+func (w *Watcher) warnings(ctx context.Context, locationID string) ([]api.Warning, error) {
+	warningData, err := w.client.GetWeatherWarning(ctx, locationID)
+	if err == nil && warningData.Code != api.APICodeSuccess {
+		err = fmt.Errorf("API returned error code: %s", warningData.Code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return warningData.Warning, nil
+}
+
+// warningsByID indexes warnings by their ID
+func warningsByID(warnings []api.Warning) map[string]api.Warning {
+	byID := make(map[string]api.Warning, len(warnings))
+	for _, warning := range warnings {
+		byID[warning.ID] = warning
+	}
+	return byID
+}
+
+// DiffWarnings compares the current warnings of a location with the previously seen ones.
+// A warning is updated when its status or publication time changes, and cancelled when its
+// status becomes cancel or it is no longer listed.
+func DiffWarnings(locationID string, previous map[string]api.Warning, current []api.Warning) []Event {
+	uri := WarningsURI(locationID)
+	var events []Event
+	listed := make(map[string]bool, len(current))
+	for _, warning := range current {
+		listed[warning.ID] = true
+		prev, ok := previous[warning.ID]
+		switch {
+		case ok && prev.Status == warning.Status && prev.PubTime == warning.PubTime:
+			continue
+		case warning.Status == warningCancelStatus:
+			if !ok || prev.Status != warningCancelStatus {
+				events = append(events, Event{Type: EventCancelled, LocationID: locationID, URI: uri, Warning: warning})
+			}
+		case !ok:
+			events = append(events, Event{Type: EventNew, LocationID: locationID, URI: uri, Warning: warning})
+		default:
+			events = append(events, Event{Type: EventUpdated, LocationID: locationID, URI: uri, Warning: warning})
+		}
+	}
+	var withdrawn []string
+	for id, warning := range previous {
+		if !listed[id] && warning.Status != warningCancelStatus {
+			withdrawn = append(withdrawn, id)
+		}
+	}
+	sort.Strings(withdrawn)
+	for _, id := range withdrawn {
+		events = append(events, Event{Type: EventCancelled, LocationID: locationID, URI: uri, Warning: previous[id]})
+	}
+	return events
+}
@@ -0,0 +1,163 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+func TestParseWarningsURI(t *testing.T) {
+	tests := []struct {
+		uri  string
+		id   string
+		want bool
+	}{
+		{WarningsURI("101010100"), "101010100", true},
+		{WarningsURI("101010100") + "?format=text", "101010100", true},
+		{"qweather://location/101010100/now", "", false},
+		{"qweather://location//warnings", "", false},
+		{"qweather://location/a/b/warnings", "", false},
+		{"file:///101010100/warnings", "", false},
+	}
+	for _, tt := range tests {
+		id, ok := ParseWarningsURI(tt.uri)
+		if id != tt.id || ok != tt.want {
+			t.Errorf("ParseWarningsURI(%q) = %q, %v; want %q, %v", tt.uri, id, ok, tt.id, tt.want)
+		}
+	}
+}
+
+func TestDiffWarnings(t *testing.T) {
+	warning := func(id, status, pubTime string) api.Warning {
+		return api.Warning{ID: id, Status: status, PubTime: pubTime, Title: "Warning " + id}
+	}
+	previous := map[string]api.Warning{
+		"unchanged": warning("unchanged", "active", "08:00"),
+		"reissued":  warning("reissued", "active", "08:00"),
+		"cancelled": warning("cancelled", "active", "08:00"),
+		"expired":   warning("expired", "update", "08:00"),
+		"gone":      warning("gone", "cancel", "08:00"),
+	}
+	current := []api.Warning{
+		warning("unchanged", "active", "08:00"),
+		warning("reissued", "update", "09:00"),
+		warning("cancelled", "cancel", "09:00"),
+		warning("fresh", "active", "09:00"),
+	}
+
+	got := DiffWarnings("101010100", previous, current)
+	want := []struct{ typ, id string }{
+		{EventUpdated, "reissued"},
+		{EventCancelled, "cancelled"},
+		{EventNew, "fresh"},
+		{EventCancelled, "expired"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("DiffWarnings returned %d events (%+v), want %d", len(got), got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Type != w.typ || got[i].Warning.ID != w.id || got[i].URI != WarningsURI("101010100") {
+			t.Errorf("event %d = %s %s, want %s %s", i, got[i].Type, got[i].Warning.ID, w.typ, w.id)
+		}
+	}
+}
+
+func TestPollNotifiesSubscribers(t *testing.T) {
+	var issued atomic.Bool
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := api.WarningResponse{Code: "200"}
+		if issued.Load() {
+			resp.Warning = []api.Warning{{ID: "w1", Status: "active", Title: "Rainstorm Blue Warning"}}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer upstream.Close()
+
+	w := New(api.NewClient(upstream.URL, "test-key"), time.Hour)
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, &mcp.ServerOptions{
+		SubscribeHandler:   w.Subscribe,
+		UnsubscribeHandler: w.Unsubscribe,
+	})
+
+	var mu sync.Mutex
+	var updated []string
+	var logged []*mcp.LoggingMessageParams
+	done := make(chan struct{}, 3)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(ctx context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			mu.Lock()
+			updated = append(updated, req.Params.URI)
+			mu.Unlock()
+			done <- struct{}{}
+		},
+		LoggingMessageHandler: func(ctx context.Context, req *mcp.LoggingMessageRequest) {
+			mu.Lock()
+			logged = append(logged, req.Params)
+			mu.Unlock()
+			done <- struct{}{}
+		},
+	})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server Connect failed: %v", err)
+	}
+	defer serverSession.Close()
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect failed: %v", err)
+	}
+	defer session.Close()
+
+	if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: "qweather://location/101010100/now"}); err == nil {
+		t.Fatal("expected subscribing to a non-warnings resource to fail")
+	}
+	if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "info"}); err != nil {
+		t.Fatalf("SetLoggingLevel failed: %v", err)
+	}
+	// Updates are sent for every subscribed format of the resource
+	uri := WarningsURI("101010100")
+	textURI := uri + "?format=text"
+	for _, u := range []string{uri, textURI} {
+		if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: u}); err != nil {
+			t.Fatalf("Subscribe %s failed: %v", u, err)
+		}
+	}
+
+	// Warnings issued between subscribing and the first poll are reported
+	issued.Store(true)
+	w.Poll(ctx, server)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for notifications")
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	slices.Sort(updated)
+	if !slices.Equal(updated, []string{uri, textURI}) {
+		t.Errorf("resource updated notifications = %v, want one for %s and %s", updated, uri, textURI)
+	}
+	if len(logged) != 1 || logged[0].Logger != loggerName || logged[0].Level != "warning" {
+		t.Fatalf("log messages = %+v, want one warning message", logged)
+	}
+	data, _ := json.Marshal(logged[0].Data)
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil || event.Type != EventNew || event.Warning.ID != "w1" {
+		t.Errorf("logged event = %s, want a new event for w1", data)
+	}
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Defaults applied to an unset Config value
+const (
+	DefaultInterval    = 5 * time.Minute
+	DefaultRainLead    = 30 * time.Minute
+	DefaultAQIStandard = "qaqi"
+)
+
+// Config endpoints and monitored locations, read from a JSON file
+type Config struct {
+	Endpoints       []Endpoint `json:"endpoints"`
+	Locations       []string   `json:"locations"`                 // City names or longitude,latitude coordinates
+	Interval        string     `json:"interval,omitempty"`        // Time between two checks, e.g. "5m"
+	AQIThreshold    int        `json:"aqiThreshold,omitempty"`    // AQI above which an aqi.threshold event is sent; 0 disables AQI checks
+	AQIStandard     string     `json:"aqiStandard,omitempty"`     // Index code the threshold applies to
+	RainLeadMinutes int        `json:"rainLeadMinutes,omitempty"` // How far ahead rain onset is reported
+	DeadLetterFile  string     `json:"deadLetterFile,omitempty"`
+	MaxAttempts     int        `json:"maxAttempts,omitempty"`
+
+	interval time.Duration
+}
+
+// LoadConfig reads and validates a webhook configuration file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook config: %w", err)
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook config: %w", err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid webhook config: %w", err)
+	}
+	return &config, nil
+}
+
+// validate checks the configuration and applies defaults
+func (c *Config) validate() error {
+	if len(c.Endpoints) == 0 {
+		return fmt.Errorf("at least one endpoint is required")
+	}
+	for i, endpoint := range c.Endpoints {
+		u, err := url.Parse(endpoint.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("endpoint %d: url must be an absolute http or https URL", i+1)
+		}
+		for _, eventType := range endpoint.Events {
+			if !validEventTypes[eventType] {
+				return fmt.Errorf("endpoint %d: unknown event type %q", i+1, eventType)
+			}
+		}
+	}
+	if len(c.Locations) == 0 {
+		return fmt.Errorf("at least one location is required")
+	}
+
+	c.interval = DefaultInterval
+	if c.Interval != "" {
+		interval, err := time.ParseDuration(c.Interval)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("interval must be a positive duration such as 5m")
+		}
+		c.interval = interval
+	}
+	if c.AQIThreshold < 0 {
+		return fmt.Errorf("aqiThreshold cannot be negative")
+	}
+	if c.AQIStandard == "" {
+		c.AQIStandard = DefaultAQIStandard
+	}
+	if c.RainLeadMinutes < 0 || c.RainLeadMinutes > 120 {
+		return fmt.Errorf("rainLeadMinutes must be between 0 and 120")
+	}
+	if c.RainLeadMinutes == 0 {
+		c.RainLeadMinutes = int(DefaultRainLead / time.Minute)
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+// Package webhook delivers weather events to HTTP endpoints as signed JSON requests,
+// retrying failed deliveries and keeping the ones that never succeed in a dead-letter file.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Request headers set on every delivery
+const (
+	HeaderEvent     = "X-QWeather-Event"
+	HeaderDelivery  = "X-QWeather-Delivery"
+	HeaderTimestamp = "X-QWeather-Timestamp"
+	HeaderSignature = "X-QWeather-Signature"
+)
+
+// Defaults applied to zero Options
+const (
+	DefaultMaxAttempts    = 5
+	DefaultInitialBackoff = time.Second
+	DefaultMaxBackoff     = time.Minute
+	defaultTimeout        = 10 * time.Second
+)
+
+// queueSize events waiting for delivery to an endpoint; further events are dead-lettered
+const queueSize = 100
+
+// DefaultTolerance how far the timestamp of a received request may be from the current time
+// for Verify to accept it
+const DefaultTolerance = 5 * time.Minute
+
+// Event a weather event delivered to endpoints. Data holds the api model the event was built from.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	LocationID string    `json:"locationId"`
+	Location   string    `json:"location,omitempty"`
+	Data       any       `json:"data"`
+}
+
+// Endpoint an HTTP receiver of events
+type Endpoint struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"` // HMAC-SHA256 key; requests are unsigned if empty
+	Events []string `json:"events,omitempty"` // Event types to deliver; all if empty
+}
+
+// accepts reports whether the endpoint wants events of the given type
+func (e Endpoint) accepts(eventType string) bool {
+	return len(e.Events) == 0 || slices.Contains(e.Events, eventType)
+}
+
+// Options delivery behaviour of a Dispatcher
+type Options struct {
+	MaxAttempts    int           // Attempts per endpoint before an event is dead-lettered
+	InitialBackoff time.Duration // Wait before the first retry, doubled on every further retry
+	MaxBackoff     time.Duration // Upper bound of the wait between retries
+	DeadLetterPath string        // JSON lines file receiving undeliverable events; disabled if empty
+	HTTPClient     *http.Client
+}
+
+// Dispatcher delivers events to its endpoints
+type Dispatcher struct {
+	endpoints []Endpoint
+	opts      Options
+	queues    []chan queuedEvent // Events queued by Enqueue, by endpoint
+
+	mu sync.Mutex // Serializes dead-letter writes
+}
+
+// queuedEvent an event waiting for delivery, with its encoded body
+type queuedEvent struct {
+	event Event
+	body  []byte
+}
+
+// NewDispatcher creates a dispatcher, applying defaults to unset options
+func NewDispatcher(endpoints []Endpoint, opts Options) *Dispatcher {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = DefaultInitialBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: defaultTimeout}
+	}
+	queues := make([]chan queuedEvent, len(endpoints))
+	for i := range queues {
+		queues[i] = make(chan queuedEvent, queueSize)
+	}
+	return &Dispatcher{endpoints: endpoints, opts: opts, queues: queues}
+}
+
+// Sign returns the signature of a request body: the hex HMAC-SHA256 of "timestamp.body", prefixed with "sha256="
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers of a received request body in constant time. Requests
+// whose timestamp is further than the tolerance from the current time are rejected, so that a
+// captured delivery cannot be replayed later; a non-positive tolerance means DefaultTolerance.
+func Verify(secret, timestamp, signature string, body []byte, tolerance time.Duration) bool {
+	return verify(secret, timestamp, signature, body, tolerance, time.Now())
+}
+
+// verify is Verify at a given time
+func verify(secret, timestamp, signature string, body []byte, tolerance time.Duration, now time.Time) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > tolerance || age < -tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature))
+}
+
+// NewEventID returns a random event ID. rand.Text never returns predictable output: it crashes
+// the program if the system random source fails.
+func NewEventID() string {
+	return rand.Text()
+}
+
+// Dispatch delivers an event to every endpoint accepting its type. Endpoints are tried
+// independently; the returned error joins the failures of all endpoints.
+func (d *Dispatcher) Dispatch(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	var errs []error
+	for _, endpoint := range d.endpoints {
+		if endpoint.accepts(event.Type) {
+			errs = append(errs, d.send(ctx, endpoint, event, body))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Enqueue queues an event for the endpoints accepting its type, to be delivered by Run, so that
+// a slow or failing endpoint delays neither the caller nor the other endpoints. An event that
+// does not fit in the queue of an endpoint is dead-lettered.
+func (d *Dispatcher) Enqueue(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	var errs []error
+	for i, endpoint := range d.endpoints {
+		if !endpoint.accepts(event.Type) {
+			continue
+		}
+		select {
+		case d.queues[i] <- queuedEvent{event: event, body: body}:
+		default:
+			err := fmt.Errorf("failed to queue %s event for %s: %d events are waiting", event.Type, endpoint.URL, queueSize)
+			if dlErr := d.deadLetter(endpoint, event, 0, err); dlErr != nil {
+				err = errors.Join(err, dlErr)
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run delivers queued events until the context is cancelled, in order for each endpoint and
+// independently across endpoints
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i, endpoint := range d.endpoints {
+		wg.Go(func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case queued := <-d.queues[i]:
+					if err := d.send(ctx, endpoint, queued.event, queued.body); err != nil {
+						slog.Error("Webhook delivery failed", "error", err)
+					}
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// send delivers an event to an endpoint, dead-lettering it if every attempt fails
+func (d *Dispatcher) send(ctx context.Context, endpoint Endpoint, event Event, body []byte) error {
+	attempts, err := d.deliver(ctx, endpoint, event, body)
+	if err == nil {
+		return nil
+	}
+	err = fmt.Errorf("failed to deliver %s event to %s after %d attempt(s): %w", event.Type, endpoint.URL, attempts, err)
+	if dlErr := d.deadLetter(endpoint, event, attempts, err); dlErr != nil {
+		err = errors.Join(err, dlErr)
+	}
+	return err
+}
+
+// permanentError a delivery failure that retrying cannot fix
+type permanentError struct{ error }
+
+// deliver posts the event until it is accepted, the attempts are exhausted or the failure is permanent
+func (d *Dispatcher) deliver(ctx context.Context, endpoint Endpoint, event Event, body []byte) (int, error) {
+	backoff := d.opts.InitialBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = d.post(ctx, endpoint, event, body)
+		var permanent permanentError
+		if err == nil || errors.As(err, &permanent) || attempt == d.opts.MaxAttempts {
+			return attempt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempt, errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, d.opts.MaxBackoff)
+	}
+}
+
+// post sends a single delivery attempt. Client errors other than 408 and 429 are permanent.
+func (d *Dispatcher) post(ctx context.Context, endpoint Endpoint, event Event, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{fmt.Errorf("failed to create request: %w", err)}
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderDelivery, event.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if endpoint.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, body))
+	}
+
+	resp, err := d.opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	default:
+		return permanentError{fmt.Errorf("endpoint returned status %d", resp.StatusCode)}
+	}
+}
+
+// deadLetterEntry a line of the dead-letter file
+type deadLetterEntry struct {
+	Time     time.Time `json:"time"`
+	Endpoint string    `json:"endpoint"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	Event    Event     `json:"event"`
+}
+
+// deadLetter appends an undeliverable event to the dead-letter file
+func (d *Dispatcher) deadLetter(endpoint Endpoint, event Event, attempts int, cause error) error {
+	if d.opts.DeadLetterPath == "" {
+		return nil
+	}
+	line, err := json.Marshal(deadLetterEntry{Time: time.Now(), Endpoint: endpoint.URL, Attempts: attempts, Error: cause.Error(), Event: event})
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	file, err := os.OpenFile(d.opts.DeadLetterPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"type":"rain.soon"}`)
+	signature := Sign("secret", 1714560000, body)
+	now := time.Unix(1714560000, 0).Add(time.Minute)
+	if !verify("secret", "1714560000", signature, body, 0, now) {
+		t.Fatal("Verify rejected a valid signature")
+	}
+	if verify("other", "1714560000", signature, body, 0, now) {
+		t.Error("Verify accepted a signature made with another secret")
+	}
+	if verify("secret", "1714560001", signature, body, 0, now) {
+		t.Error("Verify accepted a signature for another timestamp")
+	}
+	if verify("secret", "1714560000", signature, []byte(`{"type":"aqi.threshold"}`), 0, now) {
+		t.Error("Verify accepted a signature for another body")
+	}
+
+	// Replays of old deliveries are rejected
+	if verify("secret", "1714560000", signature, body, 0, now.Add(DefaultTolerance)) {
+		t.Error("Verify accepted a request older than the default tolerance")
+	}
+	if !verify("secret", "1714560000", signature, body, time.Hour, now.Add(DefaultTolerance)) {
+		t.Error("Verify rejected a request within the tolerance")
+	}
+	if verify("secret", "1714560000", signature, body, time.Minute, now.Add(-2*time.Minute-time.Second)) {
+		t.Error("Verify accepted a request timestamped in the future")
+	}
+}
+
+func TestDispatch_RetriesUntilAccepted(t *testing.T) {
+	var attempts atomic.Int32
+	var received Event
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !Verify("secret", r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body, 0) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Header.Get(HeaderEvent) != EventWarningNew || r.Header.Get(HeaderDelivery) != "evt-1" {
+			t.Errorf("headers = %v, want the event type and ID", r.Header)
+		}
+		json.Unmarshal(body, &received)
+	}))
+	defer receiver.Close()
+
+	dispatcher := NewDispatcher([]Endpoint{{URL: receiver.URL, Secret: "secret"}}, Options{InitialBackoff: time.Millisecond})
+	event := Event{ID: "evt-1", Type: EventWarningNew, LocationID: "101010100", Data: api.Warning{ID: "w1", Title: "Rainstorm Blue Warning"}}
+	if err := dispatcher.Dispatch(context.Background(), event); err != nil {
+		t.Fatalf("Dispatch failed: %v", err)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("attempts = %d, want 3", attempts.Load())
+	}
+	data, _ := received.Data.(map[string]any)
+	if received.ID != "evt-1" || data["title"] != "Rainstorm Blue Warning" {
+		t.Errorf("received %+v, want the dispatched event", received)
+	}
+}
+
+func TestDispatch_DeadLetters(t *testing.T) {
+	var attempts atomic.Int32
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	var filtered atomic.Int32
+	filtering := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filtered.Add(1)
+	}))
+	defer filtering.Close()
+
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	dispatcher := NewDispatcher([]Endpoint{
+		{URL: rejecting.URL},
+		{URL: failing.URL},
+		{URL: filtering.URL, Events: []string{EventRainSoon}},
+	}, Options{MaxAttempts: 2, InitialBackoff: time.Millisecond, DeadLetterPath: path})
+
+	if err := dispatcher.Dispatch(context.Background(), Event{ID: "evt-2", Type: EventAQIThreshold}); err == nil {
+		t.Fatal("expected an error for the failed deliveries")
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("attempts = %d, want client errors not to be retried", attempts.Load())
+	}
+	if filtered.Load() != 0 {
+		t.Error("event delivered to an endpoint not subscribed to its type")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open dead-letter file: %v", err)
+	}
+	defer file.Close()
+	var entries []deadLetterEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry deadLetterEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid dead-letter line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("dead-lettered %d events, want 2", len(entries))
+	}
+	if entries[0].Endpoint != rejecting.URL || entries[0].Attempts != 1 || entries[0].Event.ID != "evt-2" {
+		t.Errorf("entry 0 = %+v, want the rejected delivery", entries[0])
+	}
+	if entries[1].Endpoint != failing.URL || entries[1].Attempts != 2 {
+		t.Errorf("entry 1 = %+v, want the failed delivery after 2 attempts", entries[1])
+	}
+}
+
+func TestEnqueue_EndpointsDeliverIndependently(t *testing.T) {
+	release := make(chan struct{})
+	stuck := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer stuck.Close()
+	defer close(release)
+	received := make(chan string, 2)
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(HeaderDelivery)
+	}))
+	defer healthy.Close()
+
+	dispatcher := NewDispatcher([]Endpoint{{URL: stuck.URL}, {URL: healthy.URL}}, Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dispatcher.Run(ctx)
+
+	// Enqueueing does not wait for deliveries, and the stuck endpoint does not hold up the other
+	start := time.Now()
+	for _, id := range []string{"evt-1", "evt-2"} {
+		if err := dispatcher.Enqueue(Event{ID: id, Type: EventRainSoon}); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Enqueue took %v", elapsed)
+	}
+	for _, want := range []string{"evt-1", "evt-2"} {
+		select {
+		case id := <-received:
+			if id != want {
+				t.Fatalf("delivered %s, want %s", id, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s not delivered to the healthy endpoint", want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+	"github.com/overstarry/qweather-mcp-go/watcher"
+)
+
+// Event types
+const (
+	EventWarningNew       = "warning.new"
+	EventWarningUpdated   = "warning.updated"
+	EventWarningCancelled = "warning.cancelled"
+	EventAQIThreshold     = "aqi.threshold"
+	EventRainSoon         = "rain.soon"
+)
+
+var validEventTypes = map[string]bool{
+	EventWarningNew:       true,
+	EventWarningUpdated:   true,
+	EventWarningCancelled: true,
+	EventAQIThreshold:     true,
+	EventRainSoon:         true,
+}
+
+// AQIThresholdData payload of aqi.threshold events
+type AQIThresholdData struct {
+	Threshold int                 `json:"threshold"`
+	Previous  int                 `json:"previous"`
+	Index     api.AirQualityIndex `json:"index"`
+}
+
+// RainSoonData payload of rain.soon events
+type RainSoonData struct {
+	StartsAt string                `json:"startsAt"`
+	Forecast *api.MinutelyResponse `json:"forecast"`
+}
+
+// locationState what the monitor last saw at a location
+type locationState struct {
+	warnings map[string]api.Warning // nil until the first check
+	aqi      int
+	aqiKnown bool
+	rainSoon bool // A rain.soon event was sent and rain has not yet stopped
+}
+
+// Monitor checks the configured locations on a schedule and dispatches events for changes.
+// The first check of a location only records its state.
+type Monitor struct {
+	client     *api.Client
+	dispatcher *Dispatcher
+	config     *Config
+
+	mu       sync.Mutex
+	resolved map[string]*api.Location
+	state    map[string]*locationState
+}
+
+// NewMonitor creates a monitor for a validated configuration
+func NewMonitor(client *api.Client, dispatcher *Dispatcher, config *Config) *Monitor {
+	return &Monitor{
+		client:     client,
+		dispatcher: dispatcher,
+		config:     config,
+		resolved:   make(map[string]*api.Location),
+		state:      make(map[string]*locationState),
+	}
+}
+
+// Run checks immediately and then on every interval until the context is cancelled, delivering
+// events in the background so that endpoints cannot hold up the checks
+func (m *Monitor) Run(ctx context.Context) {
+	go m.dispatcher.Run(ctx)
+	ticker := time.NewTicker(m.config.interval)
+	defer ticker.Stop()
+	for {
+		if err := m.Poll(ctx, time.Now()); err != nil {
+			slog.Error("Webhook monitor: poll failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll checks every location once and queues the resulting events for delivery
+func (m *Monitor) Poll(ctx context.Context, now time.Time) error {
+	var errs []error
+	for _, name := range m.config.Locations {
+		location, err := m.location(ctx, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to resolve %s: %w", name, err))
+			continue
+		}
+		events, err := m.check(ctx, location, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to check %s: %w", name, err))
+		}
+		for _, event := range events {
+			if err := m.dispatcher.Enqueue(event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// location resolves a configured location once and caches the result
+func (m *Monitor) location(ctx context.Context, name string) (*api.Location, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if location, ok := m.resolved[name]; ok {
+		return location, nil
+	}
+	locationData, err := m.client.GetLocationByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if locationData.Code != api.APICodeSuccess || len(locationData.Location) == 0 {
+		return nil, fmt.Errorf("no matching city found")
+	}
+	location := &locationData.Location[0]
+	m.resolved[name] = location
+	return location, nil
+}
+
+// check fetches warnings, air quality and the precipitation nowcast of a location and returns
+// the events they imply. Each source is checked independently; failures are joined.
+func (m *Monitor) check(ctx context.Context, location *api.Location, now time.Time) ([]Event, error) {
+	m.mu.Lock()
+	state, ok := m.state[location.ID]
+	if !ok {
+		state = &locationState{}
+		m.state[location.ID] = state
+	}
+	m.mu.Unlock()
+
+	newEvent := func(eventType string, data any) Event {
+		return Event{ID: NewEventID(), Type: eventType, Time: now, LocationID: location.ID, Location: location.Name, Data: data}
+	}
+	var events []Event
+	var errs []error
+
+	if warningData, err := m.client.GetWeatherWarning(ctx, location.ID); err != nil {
+		errs = append(errs, fmt.Errorf("failed to get weather warnings: %w", err))
+	} else if warningData.Code != api.APICodeSuccess {
+		errs = append(errs, fmt.Errorf("failed to get weather warnings: API returned error code: %s", warningData.Code))
+	} else {
+		if state.warnings != nil {
+			for _, change := range watcher.DiffWarnings(location.ID, state.warnings, warningData.Warning) {
+				events = append(events, newEvent("warning."+change.Type, change.Warning))
+			}
+		}
+		state.warnings = make(map[string]api.Warning, len(warningData.Warning))
+		for _, warning := range warningData.Warning {
+			state.warnings[warning.ID] = warning
+		}
+	}
+
+	lat, lon := location.Coordinates()
+	if m.config.AQIThreshold > 0 {
+		if airQuality, err := m.client.GetAirQuality(ctx, lat, lon); err != nil {
+			errs = append(errs, fmt.Errorf("failed to get air quality: %w", err))
+		} else {
+			for _, index := range airQuality.Indexes {
+				if index.Code != m.config.AQIStandard {
+					continue
+				}
+				// Only upward crossings are reported
+				if state.aqiKnown && state.aqi <= m.config.AQIThreshold && index.Aqi > m.config.AQIThreshold {
+					events = append(events, newEvent(EventAQIThreshold, AQIThresholdData{Threshold: m.config.AQIThreshold, Previous: state.aqi, Index: index}))
+				}
+				state.aqi, state.aqiKnown = index.Aqi, true
+			}
+		}
+	}
+
+	if minutely, err := m.client.GetMinutelyPrecipitation(ctx, lon+","+lat); err != nil {
+		errs = append(errs, fmt.Errorf("failed to get minutely precipitation: %w", err))
+	} else if minutely.Code == api.APICodeSuccess {
+		startsAt, raining := rainOnset(minutely, now, time.Duration(m.config.RainLeadMinutes)*time.Minute)
+		switch {
+		case raining:
+			// Already raining: nothing to announce, but a later dry spell re-arms the event
+			state.rainSoon = true
+		case startsAt != "" && !state.rainSoon:
+			events = append(events, newEvent(EventRainSoon, RainSoonData{StartsAt: startsAt, Forecast: minutely}))
+			state.rainSoon = true
+		case startsAt == "":
+			state.rainSoon = false
+		}
+	}
+
+	return events, errors.Join(errs...)
+}
+
+// rainOnset finds the first minutely step with precipitation within lead of now. It reports
+// raining if precipitation is already falling at the first step that has not yet ended.
+func rainOnset(minutely *api.MinutelyResponse, now time.Time, lead time.Duration) (string, bool) {
+	first := true
+	for _, step := range minutely.Minutely {
+		t, err := time.Parse("2006-01-02T15:04Z07:00", step.FxTime)
+		if err != nil {
+			continue
+		}
+		// Steps are 5 minutes long; skip those that have already ended
+		if t.Add(5 * time.Minute).Before(now) {
+			continue
+		}
+		if t.After(now.Add(lead)) {
+			break
+		}
+		precip, _ := strconv.ParseFloat(step.Precip, 64)
+		if precip > 0 {
+			return step.FxTime, first
+		}
+		first = false
+	}
+	return "", false
+}
@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/overstarry/qweather-mcp-go/api"
+)
+
+func TestMonitorPoll(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	warnings := `[]`
+	aqi := 80
+	minutely := `[{"fxTime":"2024-05-01T20:00+08:00","precip":"0.00"},{"fxTime":"2024-05-01T20:05+08:00","precip":"0.00"}]`
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/geo/v2/city/lookup":
+			w.Write([]byte(`{"code":"200","location":[{"name":"Beijing","id":"101010100","lat":"39.90","lon":"116.41"}]}`))
+		case "/v7/warning/now":
+			w.Write([]byte(`{"code":"200","warning":` + warnings + `}`))
+		case "/airquality/v1/current/39.90/116.41":
+			json.NewEncoder(w).Encode(map[string]any{"code": "200", "indexes": []map[string]any{{"code": "qaqi", "aqi": 2}, {"code": "us-epa", "aqi": aqi}}})
+		case "/v7/minutely/5m":
+			if r.URL.Query().Get("location") != "116.41,39.90" {
+				t.Errorf("minutely location = %q, want longitude,latitude", r.URL.Query().Get("location"))
+			}
+			w.Write([]byte(`{"code":"200","summary":"Rain in 5 minutes","minutely":` + minutely + `}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer upstream.Close()
+
+	var received []Event
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		received = append(received, event)
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	config := &Config{
+		Endpoints:    []Endpoint{{URL: receiver.URL}},
+		Locations:    []string{"Beijing"},
+		AQIThreshold: 100,
+		AQIStandard:  "us-epa",
+	}
+	if err := config.validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	dispatcher := NewDispatcher(config.Endpoints, Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dispatcher.Run(ctx)
+	monitor := NewMonitor(api.NewClient(upstream.URL, "test-key"), dispatcher, config)
+	// delivered waits until the receiver has n events
+	delivered := func(n int) []Event {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			mu.Lock()
+			events := slices.Clone(received)
+			mu.Unlock()
+			if len(events) >= n || time.Now().After(deadline) {
+				return events
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// The first poll records the baseline
+	if err := monitor.Poll(context.Background(), now); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if events := delivered(0); len(events) != 0 {
+		t.Fatalf("first poll sent %d events, want none", len(events))
+	}
+
+	mu.Lock()
+	warnings = `[{"id":"w1","status":"active","title":"Rainstorm Blue Warning"}]`
+	aqi = 150
+	minutely = `[{"fxTime":"2024-05-01T20:00+08:00","precip":"0.00"},{"fxTime":"2024-05-01T20:05+08:00","precip":"0.12"}]`
+	mu.Unlock()
+	if err := monitor.Poll(context.Background(), now); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	want := []string{EventWarningNew, EventAQIThreshold, EventRainSoon}
+	got := delivered(len(want))
+	if len(got) != len(want) {
+		t.Fatalf("second poll sent %+v, want %v", got, want)
+	}
+	for i, eventType := range want {
+		if got[i].Type != eventType || got[i].LocationID != "101010100" || got[i].Location != "Beijing" {
+			t.Errorf("event %d = %+v, want %s for Beijing", i, got[i], eventType)
+		}
+	}
+	if data, _ := got[1].Data.(map[string]any); data["previous"] != float64(80) {
+		t.Errorf("aqi.threshold data = %v, want the previous AQI", got[1].Data)
+	}
+	if data, _ := got[2].Data.(map[string]any); data["startsAt"] != "2024-05-01T20:05+08:00" {
+		t.Errorf("rain.soon data = %v, want the onset time", got[2].Data)
+	}
+
+	// Unchanged conditions send nothing new
+	if err := monitor.Poll(context.Background(), now.Add(time.Minute)); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := delivered(0); len(got) != len(want) {
+		t.Errorf("third poll sent %+v, want no further events", got[len(want):])
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []Config{
+		{Locations: []string{"Beijing"}},
+		{Endpoints: []Endpoint{{URL: "ftp://example.com"}}, Locations: []string{"Beijing"}},
+		{Endpoints: []Endpoint{{URL: "https://example.com", Events: []string{"snow"}}}, Locations: []string{"Beijing"}},
+		{Endpoints: []Endpoint{{URL: "https://example.com"}}},
+		{Endpoints: []Endpoint{{URL: "https://example.com"}}, Locations: []string{"Beijing"}, Interval: "soon"},
+	}
+	for i, config := range tests {
+		if err := config.validate(); err == nil {
+			t.Errorf("config %d: expected a validation error", i)
+		}
+	}
+}